@@ -15,7 +15,9 @@ import (
 	"postgresus-backend/internal/config"
 	"postgresus-backend/internal/features/audit_logs"
 	"postgresus-backend/internal/features/backups/backups"
+	backups_blackouts "postgresus-backend/internal/features/backups/blackouts"
 	backups_config "postgresus-backend/internal/features/backups/config"
+	backups_wal "postgresus-backend/internal/features/backups/wal"
 	"postgresus-backend/internal/features/databases"
 	"postgresus-backend/internal/features/disk"
 	"postgresus-backend/internal/features/encryption/secrets"
@@ -23,6 +25,7 @@ import (
 	healthcheck_config "postgresus-backend/internal/features/healthcheck/config"
 	"postgresus-backend/internal/features/notifiers"
 	"postgresus-backend/internal/features/restores"
+	restores_verifications "postgresus-backend/internal/features/restores/verifications"
 	"postgresus-backend/internal/features/storages"
 	system_healthcheck "postgresus-backend/internal/features/system/healthcheck"
 	users_controllers "postgresus-backend/internal/features/users/controllers"
@@ -58,6 +61,8 @@ func main() {
 	err := files_utils.EnsureDirectories([]string{
 		config.GetEnv().TempFolder,
 		config.GetEnv().DataFolder,
+		config.GetEnv().WalFolder,
+		config.GetEnv().RestoresFolder,
 	})
 
 	if err != nil {
@@ -201,9 +206,12 @@ func setUpRoutes(r *gin.Engine) {
 	databases.GetDatabaseController().RegisterRoutes(protected)
 	backups.GetBackupController().RegisterRoutes(protected)
 	restores.GetRestoreController().RegisterRoutes(protected)
+	restores_verifications.GetVerificationController().RegisterRoutes(protected)
 	healthcheck_config.GetHealthcheckConfigController().RegisterRoutes(protected)
 	healthcheck_attempt.GetHealthcheckAttemptController().RegisterRoutes(protected)
 	backups_config.GetBackupConfigController().RegisterRoutes(protected)
+	backups_blackouts.GetBlackoutWindowController().RegisterRoutes(protected)
+	backups_wal.GetWalArchiveController().RegisterRoutes(protected)
 	audit_logs.GetAuditLogController().RegisterRoutes(protected)
 	users_controllers.GetManagementController().RegisterRoutes(protected)
 	users_controllers.GetSettingsController().RegisterRoutes(protected)
@@ -212,6 +220,8 @@ func setUpRoutes(r *gin.Engine) {
 func setUpDependencies() {
 	databases.SetupDependencies()
 	backups.SetupDependencies()
+	backups_blackouts.SetupDependencies()
+	backups_wal.SetupDependencies()
 	restores.SetupDependencies()
 	healthcheck_config.SetupDependencies()
 	audit_logs.SetupDependencies()
@@ -231,10 +241,22 @@ func runBackgroundTasks(log *slog.Logger) {
 		backups.GetBackupBackgroundService().Run()
 	})
 
+	go runWithPanicLogging(log, "backup copy background service", func() {
+		backups.GetBackupCopyBackgroundService().Run()
+	})
+
+	go runWithPanicLogging(log, "WAL archiving background service", func() {
+		backups_wal.GetWalArchivingBackgroundService().Run()
+	})
+
 	go runWithPanicLogging(log, "restore background service", func() {
 		restores.GetRestoreBackgroundService().Run()
 	})
 
+	go runWithPanicLogging(log, "restore verification background service", func() {
+		restores_verifications.GetVerificationBackgroundService().Run()
+	})
+
 	go runWithPanicLogging(log, "healthcheck attempt background service", func() {
 		healthcheck_attempt.GetHealthcheckAttemptBackgroundService().Run()
 	})
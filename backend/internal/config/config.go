@@ -20,15 +20,35 @@ const (
 	AppModeBackground = "background"
 )
 
+const (
+	BackupsQueueOrderFifo     = "FIFO"
+	BackupsQueueOrderPriority = "PRIORITY"
+)
+
 type EnvVariables struct {
 	IsTesting            bool
 	DatabaseDsn          string            `env:"DATABASE_DSN"         required:"true"`
 	EnvMode              env_utils.EnvMode `env:"ENV_MODE"             required:"true"`
 	PostgresesInstallDir string            `env:"POSTGRES_INSTALL_DIR"`
 
-	DataFolder    string
-	TempFolder    string
-	SecretKeyPath string
+	DataFolder     string
+	TempFolder     string
+	WalFolder      string
+	RestoresFolder string
+	SecretKeyPath  string
+
+	// backups over the limits wait in the queue, 0 means unlimited.
+	// Host limit is applied per host and port of the backed up server
+	MaxConcurrentBackups        int    `env:"MAX_CONCURRENT_BACKUPS"          env-default:"0"`
+	MaxConcurrentBackupsPerHost int    `env:"MAX_CONCURRENT_BACKUPS_PER_HOST" env-default:"0"`
+	BackupsQueueOrder           string `env:"BACKUPS_QUEUE_ORDER"             env-default:"FIFO"`
+
+	// cap of all uploads to storages together, 0 means unlimited
+	MaxUploadBytesPerSecond int64 `env:"MAX_UPLOAD_BYTES_PER_SECOND" env-default:"0"`
+
+	// shell hooks run arbitrary commands on the host, so
+	// they are allowed only when the admin enables them
+	IsShellHooksEnabled bool `env:"IS_SHELL_HOOKS_ENABLED" env-default:"false"`
 
 	TestGoogleDriveClientID     string `env:"TEST_GOOGLE_DRIVE_CLIENT_ID"`
 	TestGoogleDriveClientSecret string `env:"TEST_GOOGLE_DRIVE_CLIENT_SECRET"`
@@ -148,6 +168,20 @@ func loadEnvVariables() {
 	}
 	log.Info("ENV_MODE loaded", "mode", env.EnvMode)
 
+	if env.MaxConcurrentBackups < 0 || env.MaxConcurrentBackupsPerHost < 0 {
+		log.Error("MAX_CONCURRENT_BACKUPS and MAX_CONCURRENT_BACKUPS_PER_HOST cannot be negative")
+		os.Exit(1)
+	}
+	if env.MaxUploadBytesPerSecond < 0 {
+		log.Error("MAX_UPLOAD_BYTES_PER_SECOND cannot be negative")
+		os.Exit(1)
+	}
+	if env.BackupsQueueOrder != BackupsQueueOrderFifo &&
+		env.BackupsQueueOrder != BackupsQueueOrderPriority {
+		log.Error("BACKUPS_QUEUE_ORDER is invalid", "order", env.BackupsQueueOrder)
+		os.Exit(1)
+	}
+
 	env.PostgresesInstallDir = filepath.Join(backendRoot, "tools", "postgresql")
 	tools.VerifyPostgresesInstallation(log, env.EnvMode, env.PostgresesInstallDir)
 
@@ -155,6 +189,8 @@ func loadEnvVariables() {
 	// (projectRoot/postgresus-data -> /postgresus-data)
 	env.DataFolder = filepath.Join(filepath.Dir(backendRoot), "postgresus-data", "backups")
 	env.TempFolder = filepath.Join(filepath.Dir(backendRoot), "postgresus-data", "temp")
+	env.WalFolder = filepath.Join(filepath.Dir(backendRoot), "postgresus-data", "wal")
+	env.RestoresFolder = filepath.Join(filepath.Dir(backendRoot), "postgresus-data", "restores")
 	env.SecretKeyPath = filepath.Join(filepath.Dir(backendRoot), "postgresus-data", "secret.key")
 
 	if env.IsTesting {
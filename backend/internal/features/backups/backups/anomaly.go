@@ -0,0 +1,115 @@
+package backups
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+
+	backups_config "postgresus-backend/internal/features/backups/config"
+)
+
+const (
+	// count of recent completed backups the new one is compared with
+	anomalyBaselineSize = 7
+	// fewer backups do not tell what is usual for the database
+	minAnomalyBaselineSize = 3
+
+	// small backups change a lot in percent from run to run
+	minAnomalyBaselineSizeMb     = 1.0
+	minAnomalyBaselineDurationMs = 60 * 1000
+)
+
+// detectBackupAnomaly compares size and duration of the backup with medians
+// of recent backups made the same way. Returns description of the anomaly
+// or nil if the backup looks usual
+func detectBackupAnomaly(
+	backup *Backup,
+	recentBackups []*Backup,
+	backupConfig *backups_config.BackupConfig,
+) *string {
+	baseline := make([]*Backup, 0, len(recentBackups))
+	for _, recentBackup := range recentBackups {
+		if recentBackup.ID != backup.ID && isComparableBackup(backup, recentBackup) {
+			baseline = append(baseline, recentBackup)
+		}
+	}
+
+	if len(baseline) < minAnomalyBaselineSize {
+		return nil
+	}
+
+	anomalies := []string{}
+
+	if backupConfig.SizeAnomalyThresholdPercent > 0 {
+		sizes := make([]float64, 0, len(baseline))
+		for _, baselineBackup := range baseline {
+			sizes = append(sizes, baselineBackup.BackupSizeMb)
+		}
+
+		medianSize := getMedian(sizes)
+		deviation := getDeviationPercent(backup.BackupSizeMb, medianSize)
+
+		if medianSize >= minAnomalyBaselineSizeMb &&
+			deviation > float64(backupConfig.SizeAnomalyThresholdPercent) {
+			anomalies = append(anomalies, fmt.Sprintf(
+				"size %.2f MB differs from usual %.2f MB by %.0f%%",
+				backup.BackupSizeMb,
+				medianSize,
+				deviation,
+			))
+		}
+	}
+
+	if backupConfig.DurationAnomalyThresholdPercent > 0 {
+		durations := make([]float64, 0, len(baseline))
+		for _, baselineBackup := range baseline {
+			durations = append(durations, float64(baselineBackup.BackupDurationMs))
+		}
+
+		medianDuration := getMedian(durations)
+		deviation := getDeviationPercent(float64(backup.BackupDurationMs), medianDuration)
+
+		if medianDuration >= minAnomalyBaselineDurationMs &&
+			deviation > float64(backupConfig.DurationAnomalyThresholdPercent) {
+			anomalies = append(anomalies, fmt.Sprintf(
+				"duration %ds differs from usual %.0fs by %.0f%%",
+				backup.BackupDurationMs/1000,
+				medianDuration/1000,
+				deviation,
+			))
+		}
+	}
+
+	if len(anomalies) == 0 {
+		return nil
+	}
+
+	message := "Backup " + strings.Join(anomalies, ", ")
+	return &message
+}
+
+// backups made by another method or format differ in size
+// by design, so they are not used as the baseline
+func isComparableBackup(backup *Backup, other *Backup) bool {
+	return backup.Method == other.Method &&
+		backup.Format == other.Format &&
+		backup.Compression == other.Compression &&
+		backup.IsWholeServer == other.IsWholeServer
+}
+
+func getMedian(values []float64) float64 {
+	sorted := slices.Clone(values)
+	slices.Sort(sorted)
+
+	middle := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		return (sorted[middle-1] + sorted[middle]) / 2
+	}
+
+	return sorted[middle]
+}
+
+func getDeviationPercent(value float64, baseline float64) float64 {
+	return math.Abs(value-baseline) / baseline * 100
+}
@@ -0,0 +1,115 @@
+package backups
+
+import (
+	"testing"
+
+	backups_config "postgresus-backend/internal/features/backups/config"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_DetectBackupAnomaly_SizeDropped_AnomalyReported(t *testing.T) {
+	recentBackups := []*Backup{
+		createTestAnomalyBackup(40960, 600_000),
+		createTestAnomalyBackup(41000, 620_000),
+		createTestAnomalyBackup(40500, 590_000),
+	}
+
+	message := detectBackupAnomaly(
+		createTestAnomalyBackup(2, 610_000),
+		recentBackups,
+		createTestAnomalyConfig(),
+	)
+
+	assert.NotNil(t, message)
+	assert.Contains(t, *message, "size 2.00 MB differs from usual 40960.00 MB")
+	assert.NotContains(t, *message, "duration")
+}
+
+func Test_DetectBackupAnomaly_DurationGrown_AnomalyReported(t *testing.T) {
+	recentBackups := []*Backup{
+		createTestAnomalyBackup(100, 120_000),
+		createTestAnomalyBackup(100, 130_000),
+		createTestAnomalyBackup(100, 110_000),
+	}
+
+	message := detectBackupAnomaly(
+		createTestAnomalyBackup(100, 600_000),
+		recentBackups,
+		createTestAnomalyConfig(),
+	)
+
+	assert.NotNil(t, message)
+	assert.Contains(t, *message, "duration 600s differs from usual 120s")
+}
+
+func Test_DetectBackupAnomaly_UsualBackup_NothingReported(t *testing.T) {
+	recentBackups := []*Backup{
+		createTestAnomalyBackup(100, 120_000),
+		createTestAnomalyBackup(110, 130_000),
+		createTestAnomalyBackup(90, 110_000),
+	}
+
+	message := detectBackupAnomaly(
+		createTestAnomalyBackup(120, 150_000),
+		recentBackups,
+		createTestAnomalyConfig(),
+	)
+
+	assert.Nil(t, message)
+}
+
+func Test_DetectBackupAnomaly_NotEnoughComparableBackups_NothingReported(t *testing.T) {
+	plainBackup := createTestAnomalyBackup(40960, 600_000)
+	plainBackup.Format = backups_config.BackupFormatPlain
+
+	recentBackups := []*Backup{
+		createTestAnomalyBackup(40960, 600_000),
+		createTestAnomalyBackup(40960, 600_000),
+		plainBackup,
+	}
+
+	message := detectBackupAnomaly(
+		createTestAnomalyBackup(2, 600_000),
+		recentBackups,
+		createTestAnomalyConfig(),
+	)
+
+	assert.Nil(t, message)
+}
+
+func Test_DetectBackupAnomaly_ThresholdsDisabled_NothingReported(t *testing.T) {
+	recentBackups := []*Backup{
+		createTestAnomalyBackup(40960, 600_000),
+		createTestAnomalyBackup(40960, 600_000),
+		createTestAnomalyBackup(40960, 600_000),
+	}
+
+	message := detectBackupAnomaly(
+		createTestAnomalyBackup(2, 6_000_000),
+		recentBackups,
+		&backups_config.BackupConfig{},
+	)
+
+	assert.Nil(t, message)
+}
+
+func createTestAnomalyBackup(sizeMb float64, durationMs int64) *Backup {
+	return &Backup{
+		ID:               uuid.New(),
+		Status:           BackupStatusCompleted,
+		Method:           backups_config.BackupMethodPgDump,
+		Format:           backups_config.BackupFormatCustom,
+		Compression:      backups_config.BackupCompressionZstd,
+		BackupSizeMb:     sizeMb,
+		BackupDurationMs: durationMs,
+	}
+}
+
+func createTestAnomalyConfig() *backups_config.BackupConfig {
+	return &backups_config.BackupConfig{
+		SizeAnomalyThresholdPercent:     backups_config.DefaultSizeAnomalyThresholdPercent,
+		DurationAnomalyThresholdPercent: backups_config.DefaultDurationAnomalyThresholdPercent,
+	}
+}
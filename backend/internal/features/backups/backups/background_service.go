@@ -3,10 +3,9 @@ package backups
 import (
 	"log/slog"
 	"postgresus-backend/internal/config"
+	backups_blackouts "postgresus-backend/internal/features/backups/blackouts"
 	backups_config "postgresus-backend/internal/features/backups/config"
-	"postgresus-backend/internal/features/storages"
-	"postgresus-backend/internal/util/encryption"
-	"postgresus-backend/internal/util/period"
+	"slices"
 	"time"
 )
 
@@ -14,7 +13,8 @@ type BackupBackgroundService struct {
 	backupService       *BackupService
 	backupRepository    *BackupRepository
 	backupConfigService *backups_config.BackupConfigService
-	storageService      *storages.StorageService
+
+	blackoutWindowService *backups_blackouts.BlackoutWindowService
 
 	lastBackupTime time.Time
 	logger         *slog.Logger
@@ -95,19 +95,7 @@ func (s *BackupBackgroundService) cleanOldBackups() error {
 	}
 
 	for _, backupConfig := range enabledBackupConfigs {
-		backupStorePeriod := backupConfig.StorePeriod
-
-		if backupStorePeriod == period.PeriodForever {
-			continue
-		}
-
-		storeDuration := backupStorePeriod.ToDuration()
-		dateBeforeBackupsShouldBeDeleted := time.Now().UTC().Add(-storeDuration)
-
-		oldBackups, err := s.backupRepository.FindBackupsBeforeDate(
-			backupConfig.DatabaseID,
-			dateBeforeBackupsShouldBeDeleted,
-		)
+		oldBackups, err := s.findBackupsToDelete(backupConfig)
 		if err != nil {
 			s.logger.Error(
 				"Failed to find old backups for database",
@@ -120,25 +108,35 @@ func (s *BackupBackgroundService) cleanOldBackups() error {
 		}
 
 		for _, backup := range oldBackups {
-			storage, err := s.storageService.GetStorageByID(backup.StorageID)
-			if err != nil {
-				s.logger.Error(
-					"Failed to get storage by ID",
-					"storageId",
-					backup.StorageID,
-					"error",
-					err,
-				)
+			// files are still read by the copier, deleted on the next run
+			if isBackupCopying(backup) {
 				continue
 			}
 
-			encryptor := encryption.GetFieldEncryptor()
-			err = storage.DeleteFile(encryptor, backup.ID)
-			if err != nil {
-				s.logger.Error("Failed to delete backup file", "backupId", backup.ID, "error", err)
+			if hasCompletedCopy(backup) {
+				if err := s.backupService.removeBackupFromStorage(backup); err != nil {
+					s.logger.Error(
+						"Failed to remove old backup from storage",
+						"backupId",
+						backup.ID,
+						"error",
+						err,
+					)
+					continue
+				}
+
+				s.logger.Info(
+					"Removed old backup from storage, copies are kept",
+					"backupId",
+					backup.ID,
+					"databaseId",
+					backupConfig.DatabaseID,
+				)
+				continue
 			}
 
-			if err := s.backupRepository.DeleteByID(backup.ID); err != nil {
+			// removes every file of the backup and notifies remove listeners
+			if err := s.backupService.deleteBackup(backup); err != nil {
 				s.logger.Error("Failed to delete old backup", "backupId", backup.ID, "error", err)
 				continue
 			}
@@ -179,15 +177,49 @@ func (s *BackupBackgroundService) runPendingBackups() error {
 			continue
 		}
 
+		if lastBackup != nil &&
+			(lastBackup.Status == BackupStatusQueued || lastBackup.Status == BackupStatusInProgress) {
+			continue
+		}
+
 		var lastBackupTime *time.Time
 		if lastBackup != nil {
 			lastBackupTime = &lastBackup.CreatedAt
 		}
 
 		remainedBackupTryCount := s.GetRemainedBackupTryCount(lastBackup)
+		now := time.Now().UTC()
+
+		if backupConfig.BackupInterval.ShouldTriggerBackup(now, lastBackupTime) ||
+			(remainedBackupTryCount > 0 && isRetryDue(lastBackup, now)) {
+			// missed slot and remained tries are kept, so the backup
+			// is started on the first check after the window ends
+			blackoutEnd, err := s.blackoutWindowService.GetActiveBlackoutEnd(
+				backupConfig.DatabaseID,
+				now,
+			)
+			if err != nil {
+				s.logger.Error(
+					"Failed to check blackout windows",
+					"databaseId",
+					backupConfig.DatabaseID,
+					"error",
+					err,
+				)
+				continue
+			}
+
+			if blackoutEnd != nil {
+				s.logger.Debug(
+					"Backup deferred by blackout window",
+					"databaseId",
+					backupConfig.DatabaseID,
+					"deferredUntil",
+					blackoutEnd,
+				)
+				continue
+			}
 
-		if backupConfig.BackupInterval.ShouldTriggerBackup(time.Now().UTC(), lastBackupTime) ||
-			remainedBackupTryCount > 0 {
 			s.logger.Info(
 				"Triggering scheduled backup",
 				"databaseId",
@@ -196,7 +228,17 @@ func (s *BackupBackgroundService) runPendingBackups() error {
 				backupConfig.BackupInterval.Interval,
 			)
 
-			go s.backupService.MakeBackup(backupConfig.DatabaseID, remainedBackupTryCount == 1)
+			if err := s.backupService.EnqueueBackup(backupConfig.DatabaseID); err != nil {
+				s.logger.Error(
+					"Failed to enqueue scheduled backup",
+					"databaseId",
+					backupConfig.DatabaseID,
+					"error",
+					err,
+				)
+				continue
+			}
+
 			s.logger.Info(
 				"Successfully triggered scheduled backup",
 				"databaseId",
@@ -205,6 +247,9 @@ func (s *BackupBackgroundService) runPendingBackups() error {
 		}
 	}
 
+	// backups left in the queue after restart
+	s.backupService.StartQueuedBackups()
+
 	return nil
 }
 
@@ -252,3 +297,19 @@ func (s *BackupBackgroundService) GetRemainedBackupTryCount(lastBackup *Backup)
 
 	return maxFailedTriesCount - len(lastFailedBackups)
 }
+
+func (s *BackupBackgroundService) findBackupsToDelete(
+	backupConfig *backups_config.BackupConfig,
+) ([]*Backup, error) {
+	backups, err := s.backupRepository.FindByDatabaseID(backupConfig.DatabaseID)
+	if err != nil {
+		return nil, err
+	}
+
+	// backups kept only in copies follow retention of the copies
+	backups = slices.DeleteFunc(backups, func(backup *Backup) bool {
+		return backup.IsRemovedFromStorage
+	})
+
+	return getBackupsToDelete(backups, backupConfig, time.Now().UTC()), nil
+}
@@ -1,6 +1,7 @@
 package backups
 
 import (
+	"context"
 	backups_config "postgresus-backend/internal/features/backups/config"
 	"postgresus-backend/internal/features/databases"
 	"postgresus-backend/internal/features/intervals"
@@ -9,10 +10,14 @@ import (
 	users_enums "postgresus-backend/internal/features/users/enums"
 	users_testing "postgresus-backend/internal/features/users/testing"
 	workspaces_testing "postgresus-backend/internal/features/workspaces/testing"
+	"postgresus-backend/internal/util/encryption"
+	"postgresus-backend/internal/util/logger"
 	"postgresus-backend/internal/util/period"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -319,3 +324,200 @@ func Test_MakeBackupHavingFailedBackupWithRetries_RetriesCountNotExceeded(t *tes
 	storages.RemoveTestStorage(storage.ID)
 	workspaces_testing.RemoveTestWorkspace(workspace, router)
 }
+
+func Test_MakeBackupHavingFailedBackupWithPlannedRetry_RetryDeferred(t *testing.T) {
+	// setup data
+	user := users_testing.CreateTestUser(users_enums.UserRoleAdmin)
+	router := CreateTestRouter()
+	workspace := workspaces_testing.CreateTestWorkspace("Test Workspace", user, router)
+	storage := storages.CreateTestStorage(workspace.ID)
+	notifier := notifiers.CreateTestNotifier(workspace.ID)
+	database := databases.CreateTestDatabase(workspace.ID, storage, notifier)
+
+	// Enable backups for the database with retries enabled
+	backupConfig, err := backups_config.GetBackupConfigService().GetBackupConfigByDbId(database.ID)
+	assert.NoError(t, err)
+
+	timeOfDay := "04:00"
+	backupConfig.BackupInterval = &intervals.Interval{
+		Interval:  intervals.IntervalDaily,
+		TimeOfDay: &timeOfDay,
+	}
+	backupConfig.IsBackupsEnabled = true
+	backupConfig.StorePeriod = period.PeriodWeek
+	backupConfig.Storage = storage
+	backupConfig.StorageID = &storage.ID
+	backupConfig.IsRetryIfFailed = true
+	backupConfig.MaxFailedTriesCount = 3
+	backupConfig.RetryDelaySeconds = 600
+
+	_, err = backups_config.GetBackupConfigService().SaveBackupConfig(backupConfig)
+	assert.NoError(t, err)
+
+	// add failed backup with retry planned in the future
+	failMessage := "backup failed"
+	nextRetryAt := time.Now().UTC().Add(10 * time.Minute)
+	backupRepository.Save(&Backup{
+		DatabaseID: database.ID,
+		StorageID:  storage.ID,
+
+		Status:      BackupStatusFailed,
+		FailMessage: &failMessage,
+		NextRetryAt: &nextRetryAt,
+
+		CreatedAt: time.Now().UTC().Add(-1 * time.Minute),
+	})
+
+	GetBackupBackgroundService().runPendingBackups()
+
+	time.Sleep(100 * time.Millisecond)
+
+	// assertions
+	backups, err := backupRepository.FindByDatabaseID(database.ID)
+	assert.NoError(t, err)
+	assert.Len(t, backups, 1) // Retry is not started before the planned time
+
+	// cleanup
+	for _, backup := range backups {
+		err := backupRepository.DeleteByID(backup.ID)
+		assert.NoError(t, err)
+	}
+
+	databases.RemoveTestDatabase(database)
+	time.Sleep(50 * time.Millisecond) // Wait for cascading deletes
+	notifiers.RemoveTestNotifier(notifier)
+	storages.RemoveTestStorage(storage.ID)
+	workspaces_testing.RemoveTestWorkspace(workspace, router)
+}
+
+func Test_CleanOldBackupsHavingWholeServerBackup_ArtifactFilesDeleted(t *testing.T) {
+	// setup data
+	user := users_testing.CreateTestUser(users_enums.UserRoleAdmin)
+	router := CreateTestRouter()
+	workspace := workspaces_testing.CreateTestWorkspace("Test Workspace", user, router)
+	storage := storages.CreateTestStorage(workspace.ID)
+	notifier := notifiers.CreateTestNotifier(workspace.ID)
+	database := databases.CreateTestDatabase(workspace.ID, storage, notifier)
+
+	backupConfig, err := backups_config.GetBackupConfigService().GetBackupConfigByDbId(database.ID)
+	assert.NoError(t, err)
+
+	backupConfig.IsBackupsEnabled = true
+	backupConfig.StorePeriod = period.PeriodWeek
+	backupConfig.MinKeepCount = 0
+	backupConfig.Storage = storage
+	backupConfig.StorageID = &storage.ID
+
+	_, err = backups_config.GetBackupConfigService().SaveBackupConfig(backupConfig)
+	assert.NoError(t, err)
+
+	// add whole-server backup older than the store period
+	backup := &Backup{
+		DatabaseID:    database.ID,
+		StorageID:     storage.ID,
+		Status:        BackupStatusCompleted,
+		IsWholeServer: true,
+		CreatedAt:     time.Now().UTC().Add(-8 * 24 * time.Hour),
+	}
+	assert.NoError(t, backupRepository.Save(backup))
+
+	artifact := &BackupArtifact{
+		BackupID:     backup.ID,
+		DatabaseName: "app",
+		CreatedAt:    backup.CreatedAt,
+	}
+	assert.NoError(t, backupRepository.SaveArtifact(artifact))
+
+	fullStorage, err := storages.GetStorageService().GetStorageByID(storage.ID)
+	assert.NoError(t, err)
+
+	err = fullStorage.SaveFile(
+		context.Background(),
+		encryption.GetFieldEncryptor(),
+		logger.GetLogger(),
+		artifact.ID,
+		strings.NewReader("artifact"),
+	)
+	assert.NoError(t, err)
+
+	err = GetBackupBackgroundService().cleanOldBackups()
+	assert.NoError(t, err)
+
+	// assertions
+	backups, err := backupRepository.FindByDatabaseID(database.ID)
+	assert.NoError(t, err)
+	assert.Empty(t, backups)
+
+	_, err = fullStorage.GetFile(encryption.GetFieldEncryptor(), artifact.ID)
+	assert.Error(t, err)
+
+	// cleanup
+	databases.RemoveTestDatabase(database)
+	time.Sleep(50 * time.Millisecond) // Wait for cascading deletes
+	notifiers.RemoveTestNotifier(notifier)
+	storages.RemoveTestStorage(storage.ID)
+	workspaces_testing.RemoveTestWorkspace(workspace, router)
+}
+
+func Test_CleanOldBackupsHavingGlobalsFile_GlobalsFileDeleted(t *testing.T) {
+	// setup data
+	user := users_testing.CreateTestUser(users_enums.UserRoleAdmin)
+	router := CreateTestRouter()
+	workspace := workspaces_testing.CreateTestWorkspace("Test Workspace", user, router)
+	storage := storages.CreateTestStorage(workspace.ID)
+	notifier := notifiers.CreateTestNotifier(workspace.ID)
+	database := databases.CreateTestDatabase(workspace.ID, storage, notifier)
+
+	backupConfig, err := backups_config.GetBackupConfigService().GetBackupConfigByDbId(database.ID)
+	assert.NoError(t, err)
+
+	backupConfig.IsBackupsEnabled = true
+	backupConfig.StorePeriod = period.PeriodWeek
+	backupConfig.MinKeepCount = 0
+	backupConfig.Storage = storage
+	backupConfig.StorageID = &storage.ID
+
+	_, err = backups_config.GetBackupConfigService().SaveBackupConfig(backupConfig)
+	assert.NoError(t, err)
+
+	fullStorage, err := storages.GetStorageService().GetStorageByID(storage.ID)
+	assert.NoError(t, err)
+
+	// add backup with globals older than the store period
+	globalsFileID := uuid.New()
+	err = fullStorage.SaveFile(
+		context.Background(),
+		encryption.GetFieldEncryptor(),
+		logger.GetLogger(),
+		globalsFileID,
+		strings.NewReader("CREATE ROLE app;"),
+	)
+	assert.NoError(t, err)
+
+	backup := &Backup{
+		DatabaseID:    database.ID,
+		StorageID:     storage.ID,
+		Status:        BackupStatusCompleted,
+		GlobalsFileID: &globalsFileID,
+		CreatedAt:     time.Now().UTC().Add(-8 * 24 * time.Hour),
+	}
+	assert.NoError(t, backupRepository.Save(backup))
+
+	err = GetBackupBackgroundService().cleanOldBackups()
+	assert.NoError(t, err)
+
+	// assertions
+	backups, err := backupRepository.FindByDatabaseID(database.ID)
+	assert.NoError(t, err)
+	assert.Empty(t, backups)
+
+	_, err = fullStorage.GetFile(encryption.GetFieldEncryptor(), globalsFileID)
+	assert.Error(t, err)
+
+	// cleanup
+	databases.RemoveTestDatabase(database)
+	time.Sleep(50 * time.Millisecond) // Wait for cascading deletes
+	notifiers.RemoveTestNotifier(notifier)
+	storages.RemoveTestStorage(storage.ID)
+	workspaces_testing.RemoveTestWorkspace(workspace, router)
+}
@@ -0,0 +1,349 @@
+package backups
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"postgresus-backend/internal/config"
+	files_utils "postgresus-backend/internal/util/files"
+
+	"github.com/google/uuid"
+)
+
+const listBackupContentsTimeout = 30 * time.Minute
+
+// BackupContents is the table of contents of pg_dump archive,
+// as listed by pg_restore --list
+type BackupContents struct {
+	BackupID     uuid.UUID  `json:"backupId"`
+	ArtifactID   *uuid.UUID `json:"artifactId"`
+	DatabaseName string     `json:"databaseName"`
+
+	Schemas   []string               `json:"schemas"`
+	Tables    []*BackupContentsTable `json:"tables"`
+	Indexes   []*BackupContentsEntry `json:"indexes"`
+	Functions []*BackupContentsEntry `json:"functions"`
+	Entries   []*BackupContentsEntry `json:"entries"`
+}
+
+type BackupContentsEntry struct {
+	DumpID int    `json:"dumpId"`
+	Type   string `json:"type"`
+	Schema string `json:"schema"`
+	Name   string `json:"name"`
+	Owner  string `json:"owner"`
+
+	// known only for directory format, where each entry with data is a file
+	SizeBytes *int64 `json:"sizeBytes"`
+}
+
+type BackupContentsTable struct {
+	Schema        string `json:"schema"`
+	Name          string `json:"name"`
+	Owner         string `json:"owner"`
+	HasData       bool   `json:"hasData"`
+	DataSizeBytes *int64 `json:"dataSizeBytes"`
+}
+
+// pg_dump object descriptions consisting of several words. Longer
+// descriptions go before the ones they start with
+var multiWordEntryTypes = []string{
+	"PUBLICATION TABLES IN SCHEMA",
+	"TEXT SEARCH CONFIGURATION",
+	"TEXT SEARCH DICTIONARY",
+	"MATERIALIZED VIEW DATA",
+	"FOREIGN DATA WRAPPER",
+	"TEXT SEARCH TEMPLATE",
+	"DATABASE PROPERTIES",
+	"PROCEDURAL LANGUAGE",
+	"TEXT SEARCH PARSER",
+	"PUBLICATION TABLE",
+	"SEQUENCE OWNED BY",
+	"MATERIALIZED VIEW",
+	"CHECK CONSTRAINT",
+	"BLOB METADATA",
+	"EVENT TRIGGER",
+	"FOREIGN SERVER",
+	"FOREIGN TABLE",
+	"LARGE OBJECTS",
+	"ACCESS METHOD",
+	"OPERATOR CLASS",
+	"OPERATOR FAMILY",
+	"STATISTICS DATA",
+	"FK CONSTRAINT",
+	"INDEX ATTACH",
+	"LARGE OBJECT",
+	"ROW SECURITY",
+	"SEQUENCE SET",
+	"TABLE ATTACH",
+	"USER MAPPING",
+	"DEFAULT ACL",
+	"SHELL TYPE",
+	"TABLE DATA",
+}
+
+// ParseBackupContents parses output of pg_restore --list. Entry lines look
+// like "215; 1259 16386 TABLE public users postgres", header lines start with ";"
+func ParseBackupContents(list string, dataSizes map[int]int64) *BackupContents {
+	contents := &BackupContents{
+		Schemas:   []string{},
+		Tables:    []*BackupContentsTable{},
+		Indexes:   []*BackupContentsEntry{},
+		Functions: []*BackupContentsEntry{},
+		Entries:   []*BackupContentsEntry{},
+	}
+
+	scanner := bufio.NewScanner(strings.NewReader(list))
+	scanner.Buffer(make([]byte, 64*1024), 10*1024*1024)
+
+	for scanner.Scan() {
+		line := scanner.Text()
+
+		if strings.HasPrefix(line, ";") {
+			comment := strings.TrimSpace(strings.TrimPrefix(line, ";"))
+			if databaseName, found := strings.CutPrefix(comment, "dbname:"); found {
+				contents.DatabaseName = strings.TrimSpace(databaseName)
+			}
+
+			continue
+		}
+
+		entry := parseBackupContentsEntry(line)
+		if entry == nil {
+			continue
+		}
+
+		if size, ok := dataSizes[entry.DumpID]; ok {
+			entry.SizeBytes = &size
+		}
+
+		contents.Entries = append(contents.Entries, entry)
+	}
+
+	groupBackupContentsEntries(contents)
+
+	return contents
+}
+
+func parseBackupContentsEntry(line string) *BackupContentsEntry {
+	dumpIDString, rest, found := strings.Cut(line, "; ")
+	if !found {
+		return nil
+	}
+
+	dumpID, err := strconv.Atoi(strings.TrimSpace(dumpIDString))
+	if err != nil {
+		return nil
+	}
+
+	// skip catalog table OID and object OID
+	fields := strings.SplitN(rest, " ", 3)
+	if len(fields) < 3 {
+		return nil
+	}
+	rest = fields[2]
+
+	entryType := ""
+	for _, multiWordType := range multiWordEntryTypes {
+		if strings.HasPrefix(rest, multiWordType+" ") {
+			entryType = multiWordType
+			break
+		}
+	}
+
+	if entryType == "" {
+		entryType, _, _ = strings.Cut(rest, " ")
+	}
+	rest = strings.TrimPrefix(rest, entryType+" ")
+
+	schema, rest, _ := strings.Cut(rest, " ")
+	if schema == "-" {
+		schema = ""
+	}
+
+	// owner is the last word and is empty for objects without owner
+	name := rest
+	owner := ""
+	if idx := strings.LastIndex(rest, " "); idx >= 0 {
+		name = rest[:idx]
+		owner = rest[idx+1:]
+	}
+
+	return &BackupContentsEntry{
+		DumpID: dumpID,
+		Type:   entryType,
+		Schema: schema,
+		Name:   name,
+		Owner:  owner,
+	}
+}
+
+func groupBackupContentsEntries(contents *BackupContents) {
+	schemas := map[string]bool{}
+	tables := map[string]*BackupContentsTable{}
+
+	for _, entry := range contents.Entries {
+		if entry.Schema != "" {
+			schemas[entry.Schema] = true
+		}
+
+		switch entry.Type {
+		case "SCHEMA":
+			schemas[entry.Name] = true
+		case "TABLE":
+			table := &BackupContentsTable{
+				Schema: entry.Schema,
+				Name:   entry.Name,
+				Owner:  entry.Owner,
+			}
+			tables[entry.Schema+"."+entry.Name] = table
+			contents.Tables = append(contents.Tables, table)
+		case "INDEX":
+			contents.Indexes = append(contents.Indexes, entry)
+		case "FUNCTION", "PROCEDURE", "AGGREGATE":
+			contents.Functions = append(contents.Functions, entry)
+		}
+	}
+
+	for _, entry := range contents.Entries {
+		if entry.Type != "TABLE DATA" {
+			continue
+		}
+
+		if table, ok := tables[entry.Schema+"."+entry.Name]; ok {
+			table.HasData = true
+			table.DataSizeBytes = entry.SizeBytes
+		}
+	}
+
+	for schema := range schemas {
+		contents.Schemas = append(contents.Schemas, schema)
+	}
+	sort.Strings(contents.Schemas)
+}
+
+// listCustomBackupContents streams custom format archive to pg_restore. TOC
+// is at the beginning of the archive, so data is not read at all
+func listCustomBackupContents(
+	ctx context.Context,
+	pgRestoreBin string,
+	reader io.Reader,
+) (string, error) {
+	cmd := exec.CommandContext(ctx, pgRestoreBin, "--list")
+	cmd.Stdin = reader
+
+	return runPgRestoreList(cmd)
+}
+
+// listDirectoryBackupContents unpacks only TOC files of directory format
+// backup. Sizes of data files are taken from tar headers, file names
+// of data are dump IDs of their entries
+func listDirectoryBackupContents(
+	ctx context.Context,
+	pgRestoreBin string,
+	reader io.Reader,
+) (string, map[int]int64, error) {
+	if err := files_utils.EnsureDirectories([]string{config.GetEnv().TempFolder}); err != nil {
+		return "", nil, fmt.Errorf("failed to ensure directories: %w", err)
+	}
+
+	tempDir, err := os.MkdirTemp(config.GetEnv().TempFolder, "contents_"+uuid.New().String())
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to create temporary directory: %w", err)
+	}
+	defer func() { _ = os.RemoveAll(tempDir) }()
+
+	dataSizes := map[int]int64{}
+	tarReader := tar.NewReader(reader)
+
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return "", nil, fmt.Errorf("failed to read backup: %w", err)
+		}
+
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+
+		fileName := filepath.Base(header.Name)
+
+		if fileName == "toc.dat" || strings.HasSuffix(fileName, ".toc") {
+			if err := writeTocFile(tarReader, filepath.Join(tempDir, fileName)); err != nil {
+				return "", nil, fmt.Errorf("failed to extract %s: %w", fileName, err)
+			}
+
+			continue
+		}
+
+		dumpIDString, _, found := strings.Cut(fileName, ".dat")
+		if !found {
+			continue
+		}
+
+		if dumpID, err := strconv.Atoi(dumpIDString); err == nil {
+			dataSizes[dumpID] = header.Size
+		}
+	}
+
+	// read till the end, so checksum of the file is verified
+	if _, err := io.Copy(io.Discard, reader); err != nil {
+		return "", nil, fmt.Errorf("failed to read backup: %w", err)
+	}
+
+	if _, err := os.Stat(filepath.Join(tempDir, "toc.dat")); err != nil {
+		return "", nil, errors.New("backup does not contain table of contents")
+	}
+
+	list, err := runPgRestoreList(exec.CommandContext(ctx, pgRestoreBin, "--list", tempDir))
+	if err != nil {
+		return "", nil, err
+	}
+
+	return list, dataSizes, nil
+}
+
+func runPgRestoreList(cmd *exec.Cmd) (string, error) {
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf(
+			"pg_restore failed to list backup: %w, stderr: %s",
+			err,
+			strings.TrimSpace(stderr.String()),
+		)
+	}
+
+	return stdout.String(), nil
+}
+
+func writeTocFile(reader io.Reader, path string) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.Copy(file, reader); err != nil {
+		_ = file.Close()
+		return err
+	}
+
+	return file.Close()
+}
@@ -0,0 +1,78 @@
+package backups
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testTocList = `;
+; Archive created at 2026-10-17 09:00:00 UTC
+;     dbname: shop
+;     TOC Entries: 12
+;     Compression: gzip
+;     Dump Version: 1.15-0
+;     Format: DIRECTORY
+;
+; Selected TOC Entries:
+;
+6; 2615 16385 SCHEMA - sales postgres
+215; 1259 16386 TABLE public users postgres
+216; 1259 16390 TABLE sales orders postgres
+217; 1255 16400 FUNCTION public calc_total(integer, numeric) postgres
+218; 1259 16394 SEQUENCE public users_id_seq postgres
+3345; 0 16386 TABLE DATA public users postgres
+3346; 0 16390 TABLE DATA sales orders postgres
+3347; 0 0 SEQUENCE SET public users_id_seq postgres
+3190; 2606 16393 CONSTRAINT public users users_pkey postgres
+3191; 1259 16394 INDEX public idx_users_email postgres
+3192; 2606 16395 FK CONSTRAINT sales orders orders_user_id_fkey postgres
+4012; 0 0 COMMENT - EXTENSION plpgsql 
+`
+
+func Test_ParseBackupContents_EntriesAreGroupedByType(t *testing.T) {
+	contents := ParseBackupContents(testTocList, map[int]int64{3345: 2048})
+
+	assert.Equal(t, "shop", contents.DatabaseName)
+	assert.Equal(t, []string{"public", "sales"}, contents.Schemas)
+	assert.Len(t, contents.Entries, 12)
+
+	assert.Len(t, contents.Tables, 2)
+	assert.Equal(t, "public", contents.Tables[0].Schema)
+	assert.Equal(t, "users", contents.Tables[0].Name)
+	assert.Equal(t, "postgres", contents.Tables[0].Owner)
+	assert.True(t, contents.Tables[0].HasData)
+	assert.Equal(t, int64(2048), *contents.Tables[0].DataSizeBytes)
+	assert.True(t, contents.Tables[1].HasData)
+	assert.Nil(t, contents.Tables[1].DataSizeBytes)
+
+	assert.Len(t, contents.Indexes, 1)
+	assert.Equal(t, "idx_users_email", contents.Indexes[0].Name)
+
+	assert.Len(t, contents.Functions, 1)
+	assert.Equal(t, "calc_total(integer, numeric)", contents.Functions[0].Name)
+}
+
+func Test_ParseBackupContents_MultiWordTypesAndEmptyOwnerParsed(t *testing.T) {
+	contents := ParseBackupContents(testTocList, nil)
+
+	entriesByID := map[int]*BackupContentsEntry{}
+	for _, entry := range contents.Entries {
+		entriesByID[entry.DumpID] = entry
+	}
+
+	assert.Equal(t, "SEQUENCE SET", entriesByID[3347].Type)
+	assert.Equal(t, "users_id_seq", entriesByID[3347].Name)
+
+	assert.Equal(t, "FK CONSTRAINT", entriesByID[3192].Type)
+	assert.Equal(t, "sales", entriesByID[3192].Schema)
+	assert.Equal(t, "orders orders_user_id_fkey", entriesByID[3192].Name)
+
+	assert.Equal(t, "COMMENT", entriesByID[4012].Type)
+	assert.Equal(t, "", entriesByID[4012].Schema)
+	assert.Equal(t, "EXTENSION plpgsql", entriesByID[4012].Name)
+	assert.Equal(t, "", entriesByID[4012].Owner)
+
+	assert.Equal(t, "SCHEMA", entriesByID[6].Type)
+	assert.Equal(t, "sales", entriesByID[6].Name)
+}
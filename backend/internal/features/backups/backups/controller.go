@@ -1,10 +1,12 @@
 package backups
 
 import (
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	users_middleware "postgresus-backend/internal/features/users/middleware"
+	"postgresus-backend/internal/util/checksum"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
@@ -18,13 +20,17 @@ func (c *BackupController) RegisterRoutes(router *gin.RouterGroup) {
 	router.GET("/backups", c.GetBackups)
 	router.POST("/backups", c.MakeBackup)
 	router.GET("/backups/:id/file", c.GetFile)
+	router.GET("/backups/:id/contents", c.GetContents)
 	router.DELETE("/backups/:id", c.DeleteBackup)
 	router.POST("/backups/:id/cancel", c.CancelBackup)
+	router.POST("/backups/:id/pin", c.PinBackup)
+	router.POST("/backups/:id/unpin", c.UnpinBackup)
+	router.POST("/backups/:id/copies", c.CopyBackup)
 }
 
 // GetBackups
 // @Summary Get backups for a database
-// @Description Get paginated backups for the specified database
+// @Description Get paginated backups for the specified database. Queued backups contain their position in the queue
 // @Tags backups
 // @Produce json
 // @Param database_id query string true "Database ID"
@@ -65,12 +71,12 @@ func (c *BackupController) GetBackups(ctx *gin.Context) {
 
 // MakeBackup
 // @Summary Create a backup
-// @Description Create a new backup for the specified database
+// @Description Create a new backup for the specified database. Blackout windows do not block manual backups, warning is returned instead
 // @Tags backups
 // @Accept json
 // @Produce json
 // @Param request body MakeBackupRequest true "Backup creation data"
-// @Success 200 {object} map[string]string
+// @Success 200 {object} MakeBackupResponse
 // @Failure 400
 // @Failure 401
 // @Failure 500
@@ -88,12 +94,13 @@ func (c *BackupController) MakeBackup(ctx *gin.Context) {
 		return
 	}
 
-	if err := c.backupService.MakeBackupWithAuth(user, request.DatabaseID); err != nil {
+	response, err := c.backupService.MakeBackupWithAuth(user, request.DatabaseID)
+	if err != nil {
 		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	ctx.JSON(http.StatusOK, gin.H{"message": "backup started successfully"})
+	ctx.JSON(http.StatusOK, response)
 }
 
 // DeleteBackup
@@ -129,7 +136,7 @@ func (c *BackupController) DeleteBackup(ctx *gin.Context) {
 
 // CancelBackup
 // @Summary Cancel an in-progress backup
-// @Description Cancel a backup that is currently in progress
+// @Description Cancel a backup that is queued or currently in progress
 // @Tags backups
 // @Param id path string true "Backup ID"
 // @Success 204
@@ -158,11 +165,120 @@ func (c *BackupController) CancelBackup(ctx *gin.Context) {
 	ctx.Status(http.StatusNoContent)
 }
 
+// PinBackup
+// @Summary Pin a backup
+// @Description Pin a completed backup, so it is never deleted by retention policy or storage change
+// @Tags backups
+// @Accept json
+// @Param id path string true "Backup ID"
+// @Param request body PinBackupRequest true "Pin reason"
+// @Success 204
+// @Failure 400
+// @Failure 401
+// @Router /backups/{id}/pin [post]
+func (c *BackupController) PinBackup(ctx *gin.Context) {
+	user, ok := users_middleware.GetUserFromContext(ctx)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	id, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid backup ID"})
+		return
+	}
+
+	var request PinBackupRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := c.backupService.PinBackup(user, id, request.Reason); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+// UnpinBackup
+// @Summary Unpin a backup
+// @Description Unpin a backup, so retention policy applies to it again
+// @Tags backups
+// @Param id path string true "Backup ID"
+// @Success 204
+// @Failure 400
+// @Failure 401
+// @Router /backups/{id}/unpin [post]
+func (c *BackupController) UnpinBackup(ctx *gin.Context) {
+	user, ok := users_middleware.GetUserFromContext(ctx)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	id, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid backup ID"})
+		return
+	}
+
+	if err := c.backupService.UnpinBackup(user, id); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+// CopyBackup
+// @Summary Copy a backup to another storage
+// @Description Copy a completed backup to another storage of the workspace. Files are copied in the background, failed copy can be requested again
+// @Tags backups
+// @Accept json
+// @Produce json
+// @Param id path string true "Backup ID"
+// @Param request body CopyBackupRequest true "Storage to copy the backup to"
+// @Success 200 {object} BackupCopy
+// @Failure 400
+// @Failure 401
+// @Router /backups/{id}/copies [post]
+func (c *BackupController) CopyBackup(ctx *gin.Context) {
+	user, ok := users_middleware.GetUserFromContext(ctx)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	id, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid backup ID"})
+		return
+	}
+
+	var request CopyBackupRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	backupCopy, err := c.backupService.CopyBackupToStorageWithAuth(user, id, request.StorageID)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, backupCopy)
+}
+
 // GetFile
 // @Summary Download a backup file
 // @Description Download the backup file for the specified backup
 // @Tags backups
 // @Param id path string true "Backup ID"
+// @Param artifact_id query string false "Artifact ID, required for whole-server backups"
 // @Success 200 {file} file
 // @Failure 400
 // @Failure 401
@@ -181,7 +297,18 @@ func (c *BackupController) GetFile(ctx *gin.Context) {
 		return
 	}
 
-	fileReader, err := c.backupService.GetBackupFile(user, id)
+	var artifactID *uuid.UUID
+	if artifactIDParam := ctx.Query("artifact_id"); artifactIDParam != "" {
+		parsedArtifactID, err := uuid.Parse(artifactIDParam)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid artifact ID"})
+			return
+		}
+
+		artifactID = &parsedArtifactID
+	}
+
+	fileReader, backup, err := c.backupService.GetBackupFile(user, id, artifactID)
 	if err != nil {
 		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
@@ -195,16 +322,80 @@ func (c *BackupController) GetFile(ctx *gin.Context) {
 	ctx.Header("Content-Type", "application/octet-stream")
 	ctx.Header(
 		"Content-Disposition",
-		fmt.Sprintf("attachment; filename=\"backup_%s.dump\"", id.String()),
+		fmt.Sprintf("attachment; filename=\"backup_%s%s\"", id.String(), backup.GetFileExtension()),
 	)
 
 	_, err = io.Copy(ctx.Writer, fileReader)
 	if err != nil {
+		if errors.Is(err, checksum.ErrChecksumMismatch) {
+			// headers are already sent, so the connection is dropped
+			// to not let the client treat corrupted file as complete
+			fmt.Printf("Backup file is corrupted: %v\n", err)
+			c.dropConnection(ctx)
+			return
+		}
+
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to stream file"})
 		return
 	}
 }
 
+// GetContents
+// @Summary Get table of contents of a backup
+// @Description List schemas, tables, indexes, functions and other objects stored in the backup without restoring it. Sizes are known for directory format backups only
+// @Tags backups
+// @Produce json
+// @Param id path string true "Backup ID"
+// @Param artifact_id query string false "Artifact ID, required for whole-server backups"
+// @Success 200 {object} BackupContents
+// @Failure 400
+// @Failure 401
+// @Router /backups/{id}/contents [get]
+func (c *BackupController) GetContents(ctx *gin.Context) {
+	user, ok := users_middleware.GetUserFromContext(ctx)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	id, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid backup ID"})
+		return
+	}
+
+	var artifactID *uuid.UUID
+	if artifactIDParam := ctx.Query("artifact_id"); artifactIDParam != "" {
+		parsedArtifactID, err := uuid.Parse(artifactIDParam)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid artifact ID"})
+			return
+		}
+
+		artifactID = &parsedArtifactID
+	}
+
+	contents, err := c.backupService.GetBackupContents(user, id, artifactID)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, contents)
+}
+
 type MakeBackupRequest struct {
 	DatabaseID uuid.UUID `json:"database_id" binding:"required"`
 }
+
+func (c *BackupController) dropConnection(ctx *gin.Context) {
+	conn, _, err := ctx.Writer.Hijack()
+	if err != nil {
+		fmt.Printf("Error dropping connection: %v\n", err)
+		return
+	}
+
+	if err := conn.Close(); err != nil {
+		fmt.Printf("Error closing connection: %v\n", err)
+	}
+}
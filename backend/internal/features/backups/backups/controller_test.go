@@ -247,6 +247,36 @@ func Test_CreateBackup_AuditLogWritten(t *testing.T) {
 	assert.True(t, found, "Audit log for backup creation not found")
 }
 
+func Test_CreateBackup_BackupAlreadyQueued_ReturnsBadRequest(t *testing.T) {
+	router := createTestRouter()
+	owner := users_testing.CreateTestUser(users_enums.UserRoleMember)
+	workspace := workspaces_testing.CreateTestWorkspace("Test Workspace", owner, router)
+
+	database, completedBackup := createTestDatabaseWithBackups(workspace, owner, router)
+
+	queuedBackup := &Backup{
+		ID:         uuid.New(),
+		DatabaseID: database.ID,
+		StorageID:  completedBackup.StorageID,
+		Status:     BackupStatusQueued,
+		CreatedAt:  time.Now().UTC(),
+	}
+	err := backupRepository.Save(queuedBackup)
+	assert.NoError(t, err)
+
+	request := MakeBackupRequest{DatabaseID: database.ID}
+	testResp := test_utils.MakePostRequest(
+		t,
+		router,
+		"/api/v1/backups",
+		"Bearer "+owner.Token,
+		request,
+		http.StatusBadRequest,
+	)
+
+	assert.Contains(t, string(testResp.Body), "already queued or in progress")
+}
+
 func Test_DeleteBackup_PermissionsEnforced(t *testing.T) {
 	tests := []struct {
 		name               string
@@ -378,6 +408,110 @@ func Test_DeleteBackup_AuditLogWritten(t *testing.T) {
 	assert.True(t, found, "Audit log for backup deletion not found")
 }
 
+func Test_PinBackup_PinnedBackupCannotBeDeletedUntilUnpinned(t *testing.T) {
+	router := createTestRouter()
+	owner := users_testing.CreateTestUser(users_enums.UserRoleMember)
+	workspace := workspaces_testing.CreateTestWorkspace("Test Workspace", owner, router)
+
+	database, backup := createTestDatabaseWithBackups(workspace, owner, router)
+
+	test_utils.MakePostRequest(
+		t,
+		router,
+		fmt.Sprintf("/api/v1/backups/%s/pin", backup.ID.String()),
+		"Bearer "+owner.Token,
+		PinBackupRequest{Reason: "Before major migration"},
+		http.StatusNoContent,
+	)
+
+	pinnedBackup, err := backupRepository.FindByID(backup.ID)
+	assert.NoError(t, err)
+	assert.True(t, pinnedBackup.IsPinned)
+	assert.Equal(t, "Before major migration", *pinnedBackup.PinReason)
+	assert.NotNil(t, pinnedBackup.PinnedByUserID)
+	assert.NotNil(t, pinnedBackup.PinnedAt)
+
+	testResp := test_utils.MakeDeleteRequest(
+		t,
+		router,
+		fmt.Sprintf("/api/v1/backups/%s", backup.ID.String()),
+		"Bearer "+owner.Token,
+		http.StatusBadRequest,
+	)
+	assert.Contains(t, string(testResp.Body), "pinned")
+
+	test_utils.MakePostRequest(
+		t,
+		router,
+		fmt.Sprintf("/api/v1/backups/%s/unpin", backup.ID.String()),
+		"Bearer "+owner.Token,
+		nil,
+		http.StatusNoContent,
+	)
+
+	test_utils.MakeDeleteRequest(
+		t,
+		router,
+		fmt.Sprintf("/api/v1/backups/%s", backup.ID.String()),
+		"Bearer "+owner.Token,
+		http.StatusNoContent,
+	)
+
+	time.Sleep(100 * time.Millisecond)
+
+	auditLogs, err := audit_logs.GetAuditLogService().GetWorkspaceAuditLogs(
+		workspace.ID,
+		&audit_logs.GetAuditLogsRequest{
+			Limit:  100,
+			Offset: 0,
+		},
+	)
+	assert.NoError(t, err)
+
+	isPinLogFound := false
+	isUnpinLogFound := false
+	for _, log := range auditLogs.AuditLogs {
+		if strings.Contains(log.Message, "Backup pinned") &&
+			strings.Contains(log.Message, database.Name) {
+			isPinLogFound = true
+		}
+
+		if strings.Contains(log.Message, "Backup unpinned") &&
+			strings.Contains(log.Message, database.Name) {
+			isUnpinLogFound = true
+		}
+	}
+	assert.True(t, isPinLogFound, "Audit log for backup pin not found")
+	assert.True(t, isUnpinLogFound, "Audit log for backup unpin not found")
+}
+
+func Test_PinBackup_WhenUserIsViewer_ReturnsBadRequest(t *testing.T) {
+	router := createTestRouter()
+	owner := users_testing.CreateTestUser(users_enums.UserRoleMember)
+	workspace := workspaces_testing.CreateTestWorkspace("Test Workspace", owner, router)
+
+	_, backup := createTestDatabaseWithBackups(workspace, owner, router)
+
+	viewer := users_testing.CreateTestUser(users_enums.UserRoleMember)
+	workspaces_testing.AddMemberToWorkspace(
+		workspace,
+		viewer,
+		users_enums.WorkspaceRoleViewer,
+		owner.Token,
+		router,
+	)
+
+	testResp := test_utils.MakePostRequest(
+		t,
+		router,
+		fmt.Sprintf("/api/v1/backups/%s/pin", backup.ID.String()),
+		"Bearer "+viewer.Token,
+		PinBackupRequest{Reason: "Audit"},
+		http.StatusBadRequest,
+	)
+	assert.Contains(t, string(testResp.Body), "insufficient permissions")
+}
+
 func Test_DownloadBackup_PermissionsEnforced(t *testing.T) {
 	tests := []struct {
 		name               string
@@ -563,6 +697,55 @@ func Test_CancelBackup_InProgressBackup_SuccessfullyCancelled(t *testing.T) {
 	assert.True(t, foundCancelLog, "Cancel audit log should be created")
 }
 
+func Test_CancelBackup_QueuedBackup_CancelledWithoutStart(t *testing.T) {
+	router := createTestRouter()
+	owner := users_testing.CreateTestUser(users_enums.UserRoleMember)
+	workspace := workspaces_testing.CreateTestWorkspace("Test Workspace", owner, router)
+
+	database, completedBackup := createTestDatabaseWithBackups(workspace, owner, router)
+
+	queuedBackup := &Backup{
+		ID:         uuid.New(),
+		DatabaseID: database.ID,
+		StorageID:  completedBackup.StorageID,
+		Status:     BackupStatusQueued,
+		CreatedAt:  time.Now().UTC(),
+	}
+	err := backupRepository.Save(queuedBackup)
+	assert.NoError(t, err)
+
+	var response GetBackupsResponse
+	test_utils.MakeGetRequestAndUnmarshal(
+		t,
+		router,
+		fmt.Sprintf("/api/v1/backups?database_id=%s", database.ID.String()),
+		"Bearer "+owner.Token,
+		http.StatusOK,
+		&response,
+	)
+
+	for _, backup := range response.Backups {
+		if backup.ID == queuedBackup.ID {
+			assert.NotNil(t, backup.QueuePosition)
+		} else {
+			assert.Nil(t, backup.QueuePosition)
+		}
+	}
+
+	test_utils.MakePostRequest(
+		t,
+		router,
+		fmt.Sprintf("/api/v1/backups/%s/cancel", queuedBackup.ID.String()),
+		"Bearer "+owner.Token,
+		nil,
+		http.StatusNoContent,
+	)
+
+	cancelledBackup, err := backupRepository.FindByID(queuedBackup.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, BackupStatusCanceled, cancelledBackup.Status)
+}
+
 func createTestRouter() *gin.Engine {
 	return CreateTestRouter()
 }
@@ -0,0 +1,435 @@
+package backups
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	backups_config "postgresus-backend/internal/features/backups/config"
+	"postgresus-backend/internal/features/storages"
+	users_models "postgresus-backend/internal/features/users/models"
+	"postgresus-backend/internal/util/checksum"
+	"postgresus-backend/internal/util/period"
+
+	"github.com/google/uuid"
+)
+
+// CopyBackupToStorageWithAuth plans copy of the backup to the storage,
+// files are copied by the background copier. Failed copy is retried
+func (s *BackupService) CopyBackupToStorageWithAuth(
+	user *users_models.User,
+	backupID uuid.UUID,
+	storageID uuid.UUID,
+) (*BackupCopy, error) {
+	backup, database, err := s.getManageableBackup(user, backupID)
+	if err != nil {
+		return nil, err
+	}
+
+	if backup.Status != BackupStatusCompleted {
+		return nil, errors.New("only completed backups can be copied")
+	}
+
+	storage, err := s.storageService.GetStorage(user, storageID)
+	if err != nil {
+		return nil, err
+	}
+
+	if storage.WorkspaceID != *database.WorkspaceID {
+		return nil, errors.New("backups can be copied only to storages of the database workspace")
+	}
+
+	if storage.ID == backup.StorageID {
+		return nil, errors.New("backup is already saved to this storage")
+	}
+
+	backupCopy := getBackupCopy(backup, storageID)
+	if backupCopy != nil && backupCopy.Status != BackupCopyStatusFailed {
+		return nil, errors.New("backup is already copied to this storage")
+	}
+
+	if backupCopy == nil {
+		backupCopy = &BackupCopy{
+			BackupID:  backup.ID,
+			StorageID: storageID,
+		}
+	}
+
+	// copy requested by user is not deferred by the backoff
+	backupCopy.Status = BackupCopyStatusPending
+	backupCopy.FailMessage = nil
+	backupCopy.FailedTriesCount = 0
+	backupCopy.NextRetryAt = nil
+	backupCopy.CreatedAt = time.Now().UTC()
+
+	if err := s.backupRepository.SaveCopy(backupCopy); err != nil {
+		return nil, err
+	}
+
+	s.auditLogService.WriteAuditLog(
+		fmt.Sprintf(
+			"Backup copy to storage %s requested for database: %s (ID: %s)",
+			storage.Name,
+			database.Name,
+			backupID.String(),
+		),
+		&user.ID,
+		database.WorkspaceID,
+	)
+
+	return backupCopy, nil
+}
+
+// OnBeforeStorageRemove prevents removal of the storage keeping copies of
+// backups, the backups may be stored nowhere else. Copies without files
+// are removed together with the storage
+func (s *BackupService) OnBeforeStorageRemove(storage *storages.Storage) error {
+	backupCopies, err := s.backupRepository.FindCopiesByStorageID(storage.ID)
+	if err != nil {
+		return err
+	}
+
+	for _, backupCopy := range backupCopies {
+		if backupCopy.Status == BackupCopyStatusCompleted ||
+			backupCopy.Status == BackupCopyStatusInProgress {
+			return errors.New("storage keeps copies of backups and cannot be removed")
+		}
+	}
+
+	for _, backupCopy := range backupCopies {
+		if err := s.backupRepository.DeleteCopyByID(backupCopy.ID); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// GetBackupStorage returns the storage to read the backup from. Without
+// storage ID it is the backup storage or, once retention removed the
+// backup from there, any storage with completed copy
+func (s *BackupService) GetBackupStorage(
+	backup *Backup,
+	storageID *uuid.UUID,
+) (*storages.Storage, error) {
+	if storageID == nil || *storageID == backup.StorageID {
+		if !backup.IsRemovedFromStorage {
+			return s.storageService.GetStorageByID(backup.StorageID)
+		}
+
+		if storageID != nil {
+			return nil, errors.New("backup is removed from this storage by retention")
+		}
+
+		for _, backupCopy := range backup.Copies {
+			if backupCopy.Status == BackupCopyStatusCompleted {
+				return s.storageService.GetStorageByID(backupCopy.StorageID)
+			}
+		}
+
+		return nil, errors.New("backup has no stored copies")
+	}
+
+	backupCopy := getBackupCopy(backup, *storageID)
+	if backupCopy == nil || backupCopy.Status != BackupCopyStatusCompleted {
+		return nil, errors.New("backup has no completed copy in this storage")
+	}
+
+	return s.storageService.GetStorageByID(backupCopy.StorageID)
+}
+
+// copyBackup streams files of the backup to the storage of the copy.
+// Files are saved under the same IDs, so any location is read the same way
+func (s *BackupService) copyBackup(ctx context.Context, backup *Backup, backupCopy *BackupCopy) error {
+	sourceStorage, err := s.GetBackupStorage(backup, nil)
+	if err != nil {
+		return err
+	}
+
+	targetStorage, err := s.storageService.GetStorageByID(backupCopy.StorageID)
+	if err != nil {
+		return fmt.Errorf("failed to get storage: %w", err)
+	}
+
+	fileIDs := getBackupFileIDs(backup)
+	for i, fileID := range fileIDs {
+		fileChecksum := getBackupFileChecksum(backup, fileID)
+
+		if err := s.copyFile(ctx, sourceStorage, targetStorage, fileID, fileChecksum); err != nil {
+			s.deleteFiles(targetStorage, fileIDs[:i+1])
+			return err
+		}
+	}
+
+	return nil
+}
+
+// removeBackupFromStorage deletes files of the backup from the backup
+// storage, but keeps the backup while its copies are stored
+func (s *BackupService) removeBackupFromStorage(backup *Backup) error {
+	storage, err := s.storageService.GetStorageByID(backup.StorageID)
+	if err != nil {
+		return err
+	}
+
+	s.deleteFiles(storage, getBackupFileIDs(backup))
+
+	backup.IsRemovedFromStorage = true
+	return s.backupRepository.Save(backup)
+}
+
+// deleteBackupCopy deletes files and record of the copy. The backup
+// itself is deleted when it was the last place the backup is stored
+func (s *BackupService) deleteBackupCopy(backup *Backup, backupCopy *BackupCopy) error {
+	if backupCopy.Status == BackupCopyStatusCompleted {
+		storage, err := s.storageService.GetStorageByID(backupCopy.StorageID)
+		if err != nil {
+			return err
+		}
+
+		s.deleteFiles(storage, getBackupFileIDs(backup))
+	}
+
+	if err := s.backupRepository.DeleteCopyByID(backupCopy.ID); err != nil {
+		return err
+	}
+
+	backup.Copies = slices.DeleteFunc(backup.Copies, func(c *BackupCopy) bool {
+		return c.ID == backupCopy.ID
+	})
+
+	if backup.IsRemovedFromStorage && !hasCompletedCopy(backup) {
+		return s.deleteBackup(backup)
+	}
+
+	return nil
+}
+
+func (s *BackupService) copyFile(
+	ctx context.Context,
+	sourceStorage *storages.Storage,
+	targetStorage *storages.Storage,
+	fileID uuid.UUID,
+	fileChecksum *string,
+) error {
+	reader, err := sourceStorage.GetFile(s.fieldEncryptor, fileID)
+	if err != nil {
+		return fmt.Errorf("failed to read file %s: %w", fileID, err)
+	}
+
+	// corrupted file must not become a copy, backups made
+	// before checksums were introduced have no checksum
+	if fileChecksum != nil {
+		reader = checksum.NewVerifyingReader(reader, *fileChecksum)
+	}
+	defer func() {
+		if closeErr := reader.Close(); closeErr != nil {
+			s.logger.Error("Failed to close file reader", "error", closeErr)
+		}
+	}()
+
+	if err := targetStorage.SaveFile(ctx, s.fieldEncryptor, s.logger, fileID, reader); err != nil {
+		return fmt.Errorf("failed to save file %s: %w", fileID, err)
+	}
+
+	return nil
+}
+
+// deleteFiles does not stop on errors, because files may
+// be already removed or the storage may be unavailable
+func (s *BackupService) deleteFiles(storage *storages.Storage, fileIDs []uuid.UUID) {
+	for _, fileID := range fileIDs {
+		if err := storage.DeleteFile(s.fieldEncryptor, fileID); err != nil {
+			s.logger.Error(
+				"Failed to delete backup file",
+				"fileId",
+				fileID,
+				"storageId",
+				storage.ID,
+				"error",
+				err,
+			)
+		}
+	}
+}
+
+// getBackupsToCopy returns completed backups which should be copied to the
+// target and are not copied yet. With daily, weekly or monthly frequency the
+// first backup of the period is copied, unless another one is there already.
+// Returned backup may have failed copy to the target which is due to retry
+func getBackupsToCopy(
+	backups []*Backup,
+	target *backups_config.BackupCopyTarget,
+	now time.Time,
+) []*Backup {
+	candidates := []*Backup{}
+	for _, backup := range backups {
+		if backup.Status == BackupStatusCompleted && !backup.IsRemovedFromStorage &&
+			!isCopyExpired(backup, target.StorePeriod, now) {
+			candidates = append(candidates, backup)
+		}
+	}
+
+	slices.SortFunc(candidates, func(a, b *Backup) int {
+		return a.CreatedAt.Compare(b.CreatedAt)
+	})
+
+	backupsByPeriod := map[string][]*Backup{}
+	periodKeys := []string{}
+
+	for _, backup := range candidates {
+		periodKey := getCopyPeriodKey(backup, target.Frequency)
+		if _, ok := backupsByPeriod[periodKey]; !ok {
+			periodKeys = append(periodKeys, periodKey)
+		}
+
+		backupsByPeriod[periodKey] = append(backupsByPeriod[periodKey], backup)
+	}
+
+	backupsToCopy := []*Backup{}
+
+	for _, periodKey := range periodKeys {
+		periodBackups := backupsByPeriod[periodKey]
+
+		// failed copy does not count once its retry is due,
+		// so the backup is copied again
+		isCopied := slices.ContainsFunc(periodBackups, func(backup *Backup) bool {
+			backupCopy := getBackupCopy(backup, target.StorageID)
+			return backupCopy != nil &&
+				(backupCopy.Status != BackupCopyStatusFailed || !isCopyRetryDue(backupCopy, now))
+		})
+		if !isCopied {
+			backupsToCopy = append(backupsToCopy, periodBackups[0])
+		}
+	}
+
+	return backupsToCopy
+}
+
+// getCopiesToDelete applies retention to copies of the backups. Copies in
+// storages of copy targets are kept for the store period of the target,
+// other copies (e.g. of removed targets) for the store period of backups.
+// Copies of pinned backups are kept as the backups themselves
+func getCopiesToDelete(
+	backups []*Backup,
+	backupConfig *backups_config.BackupConfig,
+	now time.Time,
+) map[*BackupCopy]*Backup {
+	copiesToDelete := map[*BackupCopy]*Backup{}
+
+	for _, backup := range backups {
+		if backup.IsPinned {
+			continue
+		}
+
+		for _, backupCopy := range backup.Copies {
+			if backupCopy.Status != BackupCopyStatusCompleted &&
+				backupCopy.Status != BackupCopyStatusFailed {
+				continue
+			}
+
+			storePeriod := backupConfig.StorePeriod
+			for _, target := range backupConfig.CopyTargets {
+				if target.StorageID == backupCopy.StorageID {
+					storePeriod = target.StorePeriod
+				}
+			}
+
+			if isCopyExpired(backup, storePeriod, now) {
+				copiesToDelete[backupCopy] = backup
+			}
+		}
+	}
+
+	return copiesToDelete
+}
+
+func isCopyExpired(backup *Backup, storePeriod period.Period, now time.Time) bool {
+	if storePeriod == period.PeriodForever {
+		return false
+	}
+
+	return backup.CreatedAt.Before(now.Add(-storePeriod.ToDuration()))
+}
+
+func getCopyPeriodKey(backup *Backup, frequency backups_config.BackupCopyFrequency) string {
+	createdAt := backup.CreatedAt.UTC()
+
+	switch frequency {
+	case backups_config.BackupCopyFrequencyDaily:
+		return createdAt.Format("2006-01-02")
+	case backups_config.BackupCopyFrequencyWeekly:
+		year, week := createdAt.ISOWeek()
+		return fmt.Sprintf("%d-W%02d", year, week)
+	case backups_config.BackupCopyFrequencyMonthly:
+		return createdAt.Format("2006-01")
+	default:
+		return backup.ID.String()
+	}
+}
+
+func getBackupCopy(backup *Backup, storageID uuid.UUID) *BackupCopy {
+	for _, backupCopy := range backup.Copies {
+		if backupCopy.StorageID == storageID {
+			return backupCopy
+		}
+	}
+
+	return nil
+}
+
+func hasCompletedCopy(backup *Backup) bool {
+	return slices.ContainsFunc(backup.Copies, func(backupCopy *BackupCopy) bool {
+		return backupCopy.Status == BackupCopyStatusCompleted
+	})
+}
+
+// isBackupCopying tells files of the backup are read by the copier
+func isBackupCopying(backup *Backup) bool {
+	return slices.ContainsFunc(backup.Copies, func(backupCopy *BackupCopy) bool {
+		return backupCopy.Status == BackupCopyStatusPending ||
+			backupCopy.Status == BackupCopyStatusInProgress
+	})
+}
+
+// getBackupFileIDs returns IDs of all files the backup is stored as.
+// Whole-server backup has no own file, only artifacts
+func getBackupFileIDs(backup *Backup) []uuid.UUID {
+	fileIDs := []uuid.UUID{}
+
+	if !backup.IsWholeServer {
+		fileIDs = append(fileIDs, backup.ID)
+	}
+
+	if backup.GlobalsFileID != nil {
+		fileIDs = append(fileIDs, *backup.GlobalsFileID)
+	}
+
+	for _, artifact := range backup.Artifacts {
+		fileIDs = append(fileIDs, artifact.ID)
+	}
+
+	return fileIDs
+}
+
+// getBackupFileChecksum returns checksum of the file of the backup,
+// it is nil for files saved before checksums were introduced
+func getBackupFileChecksum(backup *Backup, fileID uuid.UUID) *string {
+	if fileID == backup.ID {
+		return backup.Checksum
+	}
+
+	if backup.GlobalsFileID != nil && *backup.GlobalsFileID == fileID {
+		return backup.GlobalsChecksum
+	}
+
+	for _, artifact := range backup.Artifacts {
+		if artifact.ID == fileID {
+			return artifact.Checksum
+		}
+	}
+
+	return nil
+}
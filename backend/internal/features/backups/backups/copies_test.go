@@ -0,0 +1,265 @@
+package backups
+
+import (
+	"testing"
+	"time"
+
+	backups_config "postgresus-backend/internal/features/backups/config"
+	"postgresus-backend/internal/util/period"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_GetBackupsToCopy_EveryBackup_NotCopiedCompletedBackupsReturned(t *testing.T) {
+	now := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+	target := createTestCopyTarget(backups_config.BackupCopyFrequencyEveryBackup, period.PeriodWeek)
+
+	copiedBackup := createTestRetentionBackup(now.Add(-3*time.Hour), BackupStatusCompleted)
+	copiedBackup.Copies = []*BackupCopy{
+		{StorageID: target.StorageID, Status: BackupCopyStatusCompleted},
+	}
+
+	copiedElsewhereBackup := createTestRetentionBackup(now.Add(-2*time.Hour), BackupStatusCompleted)
+	copiedElsewhereBackup.Copies = []*BackupCopy{
+		{StorageID: uuid.New(), Status: BackupCopyStatusCompleted},
+	}
+
+	removedBackup := createTestRetentionBackup(now.Add(-4*time.Hour), BackupStatusCompleted)
+	removedBackup.IsRemovedFromStorage = true
+
+	backups := []*Backup{
+		createTestRetentionBackup(now.Add(-1*time.Hour), BackupStatusCompleted),
+		createTestRetentionBackup(now.Add(-90*time.Minute), BackupStatusFailed),
+		copiedElsewhereBackup,
+		copiedBackup,
+		removedBackup,
+		createTestRetentionBackup(now.Add(-8*24*time.Hour), BackupStatusCompleted),
+	}
+
+	backupsToCopy := getBackupsToCopy(backups, target, now)
+
+	assert.Len(t, backupsToCopy, 2)
+	assert.Equal(t, copiedElsewhereBackup.ID, backupsToCopy[0].ID)
+	assert.Equal(t, backups[0].ID, backupsToCopy[1].ID)
+}
+
+func Test_GetBackupsToCopy_Daily_FirstBackupOfEachDayReturned(t *testing.T) {
+	now := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+	target := createTestCopyTarget(backups_config.BackupCopyFrequencyDaily, period.PeriodMonth)
+
+	// backups every 6 hours for 3 days, the first one at 00:00
+	backups := []*Backup{}
+	for i := 0; i < 3*4; i++ {
+		backups = append(backups, createTestRetentionBackup(
+			time.Date(2026, 10, 14, 0, 0, 0, 0, time.UTC).Add(time.Duration(i)*6*time.Hour),
+			BackupStatusCompleted,
+		))
+	}
+
+	// the day is copied already, even if not by its first backup
+	backups[6].Copies = []*BackupCopy{
+		{StorageID: target.StorageID, Status: BackupCopyStatusPending},
+	}
+
+	backupsToCopy := getBackupsToCopy(backups, target, now)
+
+	assert.Len(t, backupsToCopy, 2)
+	assert.Equal(t, time.Date(2026, 10, 14, 0, 0, 0, 0, time.UTC), backupsToCopy[0].CreatedAt)
+	assert.Equal(t, time.Date(2026, 10, 16, 0, 0, 0, 0, time.UTC), backupsToCopy[1].CreatedAt)
+}
+
+func Test_GetBackupsToCopy_Weekly_FirstBackupOfIsoWeekReturned(t *testing.T) {
+	now := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+	target := createTestCopyTarget(backups_config.BackupCopyFrequencyWeekly, period.PeriodMonth)
+
+	// 2026-10-11 is Sunday, 2026-10-12 is Monday of the next ISO week
+	backups := []*Backup{
+		createTestRetentionBackup(time.Date(2026, 10, 13, 1, 0, 0, 0, time.UTC), BackupStatusCompleted),
+		createTestRetentionBackup(time.Date(2026, 10, 12, 1, 0, 0, 0, time.UTC), BackupStatusCompleted),
+		createTestRetentionBackup(time.Date(2026, 10, 11, 1, 0, 0, 0, time.UTC), BackupStatusCompleted),
+		createTestRetentionBackup(time.Date(2026, 10, 10, 1, 0, 0, 0, time.UTC), BackupStatusCompleted),
+	}
+
+	backupsToCopy := getBackupsToCopy(backups, target, now)
+
+	assert.Len(t, backupsToCopy, 2)
+	assert.Equal(t, backups[3].ID, backupsToCopy[0].ID)
+	assert.Equal(t, backups[1].ID, backupsToCopy[1].ID)
+}
+
+func Test_GetBackupsToCopy_CopyFailed_BackupReturnedAgain(t *testing.T) {
+	now := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+	target := createTestCopyTarget(backups_config.BackupCopyFrequencyDaily, period.PeriodMonth)
+
+	failedBackup := createTestRetentionBackup(now.Add(-3*time.Hour), BackupStatusCompleted)
+	failedBackup.Copies = []*BackupCopy{
+		{StorageID: target.StorageID, Status: BackupCopyStatusFailed},
+	}
+
+	inProgressBackup := createTestRetentionBackup(now.Add(-26*time.Hour), BackupStatusCompleted)
+	inProgressBackup.Copies = []*BackupCopy{
+		{StorageID: target.StorageID, Status: BackupCopyStatusInProgress},
+	}
+
+	backupsToCopy := getBackupsToCopy(
+		[]*Backup{
+			failedBackup,
+			createTestRetentionBackup(now.Add(-2*time.Hour), BackupStatusCompleted),
+			inProgressBackup,
+		},
+		target,
+		now,
+	)
+
+	assert.Len(t, backupsToCopy, 1)
+	assert.Equal(t, failedBackup.ID, backupsToCopy[0].ID)
+}
+
+func Test_GetBackupsToCopy_CopyRetryNotDue_BackupNotReturned(t *testing.T) {
+	now := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+	target := createTestCopyTarget(backups_config.BackupCopyFrequencyEveryBackup, period.PeriodMonth)
+
+	notDueRetryAt := now.Add(10 * time.Minute)
+	deferredBackup := createTestRetentionBackup(now.Add(-3*time.Hour), BackupStatusCompleted)
+	deferredBackup.Copies = []*BackupCopy{
+		{StorageID: target.StorageID, Status: BackupCopyStatusFailed, NextRetryAt: &notDueRetryAt},
+	}
+
+	dueRetryAt := now.Add(-1 * time.Minute)
+	dueBackup := createTestRetentionBackup(now.Add(-2*time.Hour), BackupStatusCompleted)
+	dueBackup.Copies = []*BackupCopy{
+		{StorageID: target.StorageID, Status: BackupCopyStatusFailed, NextRetryAt: &dueRetryAt},
+	}
+
+	backupsToCopy := getBackupsToCopy([]*Backup{deferredBackup, dueBackup}, target, now)
+
+	assert.Len(t, backupsToCopy, 1)
+	assert.Equal(t, dueBackup.ID, backupsToCopy[0].ID)
+}
+
+func Test_GetCopiesToDelete_ExpiredCopiesOfTargetReturned(t *testing.T) {
+	now := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+	target := createTestCopyTarget(backups_config.BackupCopyFrequencyEveryBackup, period.PeriodWeek)
+	backupConfig := createTestCopyBackupConfig(period.PeriodMonth, target)
+
+	expiredBackup := createTestRetentionBackup(now.Add(-8*24*time.Hour), BackupStatusCompleted)
+	expiredBackup.Copies = []*BackupCopy{
+		{ID: uuid.New(), StorageID: uuid.New(), Status: BackupCopyStatusCompleted},
+		{ID: uuid.New(), StorageID: target.StorageID, Status: BackupCopyStatusCompleted},
+	}
+
+	pinnedBackup := createTestRetentionBackup(now.Add(-9*24*time.Hour), BackupStatusCompleted)
+	pinnedBackup.IsPinned = true
+	pinnedBackup.Copies = []*BackupCopy{
+		{ID: uuid.New(), StorageID: target.StorageID, Status: BackupCopyStatusCompleted},
+	}
+
+	copyingBackup := createTestRetentionBackup(now.Add(-10*24*time.Hour), BackupStatusCompleted)
+	copyingBackup.Copies = []*BackupCopy{
+		{ID: uuid.New(), StorageID: target.StorageID, Status: BackupCopyStatusInProgress},
+	}
+
+	recentBackup := createTestRetentionBackup(now.Add(-1*24*time.Hour), BackupStatusCompleted)
+	recentBackup.Copies = []*BackupCopy{
+		{ID: uuid.New(), StorageID: target.StorageID, Status: BackupCopyStatusCompleted},
+	}
+
+	copiesToDelete := getCopiesToDelete(
+		[]*Backup{recentBackup, expiredBackup, pinnedBackup, copyingBackup},
+		backupConfig,
+		now,
+	)
+
+	// the copy outside of targets is kept for the month of the backups
+	assert.Len(t, copiesToDelete, 1)
+	assert.Equal(t, expiredBackup, copiesToDelete[expiredBackup.Copies[1]])
+}
+
+func Test_GetCopiesToDelete_CopyOfRemovedTarget_BackupsStorePeriodApplied(t *testing.T) {
+	now := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+	backupConfig := createTestCopyBackupConfig(period.PeriodWeek)
+
+	expiredBackup := createTestRetentionBackup(now.Add(-8*24*time.Hour), BackupStatusCompleted)
+	expiredBackup.Copies = []*BackupCopy{
+		{ID: uuid.New(), StorageID: uuid.New(), Status: BackupCopyStatusFailed},
+	}
+
+	recentBackup := createTestRetentionBackup(now.Add(-1*24*time.Hour), BackupStatusCompleted)
+	recentBackup.Copies = []*BackupCopy{
+		{ID: uuid.New(), StorageID: uuid.New(), Status: BackupCopyStatusCompleted},
+	}
+
+	copiesToDelete := getCopiesToDelete([]*Backup{expiredBackup, recentBackup}, backupConfig, now)
+
+	assert.Len(t, copiesToDelete, 1)
+	assert.Equal(t, expiredBackup, copiesToDelete[expiredBackup.Copies[0]])
+}
+
+func Test_GetCopiesToDelete_ForeverStorePeriod_NothingReturned(t *testing.T) {
+	now := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+	target := createTestCopyTarget(backups_config.BackupCopyFrequencyMonthly, period.PeriodForever)
+	backupConfig := createTestCopyBackupConfig(period.PeriodWeek, target)
+
+	backup := createTestRetentionBackup(now.Add(-5*365*24*time.Hour), BackupStatusCompleted)
+	backup.Copies = []*BackupCopy{
+		{ID: uuid.New(), StorageID: target.StorageID, Status: BackupCopyStatusCompleted},
+	}
+
+	assert.Empty(t, getCopiesToDelete([]*Backup{backup}, backupConfig, now))
+}
+
+func Test_GetBackupFileIDs_WholeServerBackup_OnlyGlobalsAndArtifactsReturned(t *testing.T) {
+	globalsFileID := uuid.New()
+	artifactID := uuid.New()
+
+	backup := &Backup{
+		ID:            uuid.New(),
+		IsWholeServer: true,
+		GlobalsFileID: &globalsFileID,
+		Artifacts:     []*BackupArtifact{{ID: artifactID}},
+	}
+
+	assert.Equal(t, []uuid.UUID{globalsFileID, artifactID}, getBackupFileIDs(backup))
+}
+
+func Test_GetBackupFileChecksum_ChecksumOfEachFileReturned(t *testing.T) {
+	backupChecksum := "backup"
+	globalsChecksum := "globals"
+	artifactChecksum := "artifact"
+	globalsFileID := uuid.New()
+
+	backup := &Backup{
+		ID:              uuid.New(),
+		Checksum:        &backupChecksum,
+		GlobalsFileID:   &globalsFileID,
+		GlobalsChecksum: &globalsChecksum,
+		Artifacts:       []*BackupArtifact{{ID: uuid.New(), Checksum: &artifactChecksum}},
+	}
+
+	assert.Equal(t, &backupChecksum, getBackupFileChecksum(backup, backup.ID))
+	assert.Equal(t, &globalsChecksum, getBackupFileChecksum(backup, globalsFileID))
+	assert.Equal(t, &artifactChecksum, getBackupFileChecksum(backup, backup.Artifacts[0].ID))
+	assert.Nil(t, getBackupFileChecksum(backup, uuid.New()))
+}
+
+func createTestCopyTarget(
+	frequency backups_config.BackupCopyFrequency,
+	storePeriod period.Period,
+) *backups_config.BackupCopyTarget {
+	return &backups_config.BackupCopyTarget{
+		StorageID:   uuid.New(),
+		Frequency:   frequency,
+		StorePeriod: storePeriod,
+	}
+}
+
+func createTestCopyBackupConfig(
+	storePeriod period.Period,
+	targets ...*backups_config.BackupCopyTarget,
+) *backups_config.BackupConfig {
+	return &backups_config.BackupConfig{
+		StorePeriod: storePeriod,
+		CopyTargets: targets,
+	}
+}
@@ -0,0 +1,280 @@
+package backups
+
+import (
+	"context"
+	"log/slog"
+	"math/rand/v2"
+	"time"
+
+	"postgresus-backend/internal/config"
+	backups_config "postgresus-backend/internal/features/backups/config"
+)
+
+// BackupCopyBackgroundService copies backups to additional storages of
+// their configs and applies retention of the copies. Copies are made one
+// by one, so copying does not compete with backups for the bandwidth
+type BackupCopyBackgroundService struct {
+	backupService       *BackupService
+	backupRepository    *BackupRepository
+	backupConfigService *backups_config.BackupConfigService
+
+	logger *slog.Logger
+}
+
+func (s *BackupCopyBackgroundService) Run() {
+	if err := s.failCopiesInProgress(); err != nil {
+		s.logger.Error("Failed to fail backup copies in progress", "error", err)
+		panic(err)
+	}
+
+	for {
+		if config.IsShouldShutdown() {
+			return
+		}
+
+		if err := s.planBackupCopies(); err != nil {
+			s.logger.Error("Failed to plan backup copies", "error", err)
+		}
+
+		if err := s.runPendingCopies(); err != nil {
+			s.logger.Error("Failed to run pending backup copies", "error", err)
+		}
+
+		if err := s.cleanOldCopies(); err != nil {
+			s.logger.Error("Failed to clean old backup copies", "error", err)
+		}
+
+		time.Sleep(1 * time.Minute)
+	}
+}
+
+func (s *BackupCopyBackgroundService) failCopiesInProgress() error {
+	copiesInProgress, err := s.backupRepository.FindCopiesByStatus(BackupCopyStatusInProgress)
+	if err != nil {
+		return err
+	}
+
+	for _, backupCopy := range copiesInProgress {
+		failMessage := "Backup copy failed due to application restart"
+		backupCopy.Status = BackupCopyStatusFailed
+		backupCopy.FailMessage = &failMessage
+
+		if err := s.backupRepository.SaveCopy(backupCopy); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *BackupCopyBackgroundService) planBackupCopies() error {
+	enabledBackupConfigs, err := s.backupConfigService.GetBackupConfigsWithEnabledBackups()
+	if err != nil {
+		return err
+	}
+
+	now := time.Now().UTC()
+
+	for _, backupConfig := range enabledBackupConfigs {
+		if len(backupConfig.CopyTargets) == 0 {
+			continue
+		}
+
+		backups, err := s.backupRepository.FindByDatabaseID(backupConfig.DatabaseID)
+		if err != nil {
+			s.logger.Error(
+				"Failed to find backups for database",
+				"databaseId",
+				backupConfig.DatabaseID,
+				"error",
+				err,
+			)
+			continue
+		}
+
+		for _, target := range backupConfig.CopyTargets {
+			for _, backup := range getBackupsToCopy(backups, target, now) {
+				// failed copy is reset, there is one copy per storage
+				backupCopy := getBackupCopy(backup, target.StorageID)
+				isNewCopy := backupCopy == nil
+
+				if isNewCopy {
+					backupCopy = &BackupCopy{
+						BackupID:  backup.ID,
+						StorageID: target.StorageID,
+					}
+				}
+
+				backupCopy.Status = BackupCopyStatusPending
+				backupCopy.FailMessage = nil
+				backupCopy.NextRetryAt = nil
+				backupCopy.CreatedAt = now
+
+				if err := s.backupRepository.SaveCopy(backupCopy); err != nil {
+					s.logger.Error("Failed to plan backup copy", "backupId", backup.ID, "error", err)
+					continue
+				}
+
+				if isNewCopy {
+					backup.Copies = append(backup.Copies, backupCopy)
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+func (s *BackupCopyBackgroundService) runPendingCopies() error {
+	pendingCopies, err := s.backupRepository.FindCopiesByStatus(BackupCopyStatusPending)
+	if err != nil {
+		return err
+	}
+
+	for _, backupCopy := range pendingCopies {
+		if config.IsShouldShutdown() {
+			return nil
+		}
+
+		s.runCopy(backupCopy)
+	}
+
+	return nil
+}
+
+func (s *BackupCopyBackgroundService) runCopy(backupCopy *BackupCopy) {
+	backupCopy.Status = BackupCopyStatusInProgress
+	if err := s.backupRepository.SaveCopy(backupCopy); err != nil {
+		s.logger.Error("Failed to save backup copy", "copyId", backupCopy.ID, "error", err)
+		return
+	}
+
+	start := time.Now().UTC()
+
+	err := s.copyBackup(backupCopy)
+	if err != nil {
+		s.logger.Error(
+			"Failed to copy backup",
+			"backupId",
+			backupCopy.BackupID,
+			"storageId",
+			backupCopy.StorageID,
+			"error",
+			err,
+		)
+
+		failMessage := err.Error()
+		backupCopy.Status = BackupCopyStatusFailed
+		backupCopy.FailMessage = &failMessage
+		s.planCopyRetry(backupCopy)
+	} else {
+		s.logger.Info(
+			"Backup copied",
+			"backupId",
+			backupCopy.BackupID,
+			"storageId",
+			backupCopy.StorageID,
+			"durationMs",
+			time.Since(start).Milliseconds(),
+		)
+
+		completedAt := time.Now().UTC()
+		backupCopy.Status = BackupCopyStatusCompleted
+		backupCopy.CompletedAt = &completedAt
+		backupCopy.FailedTriesCount = 0
+		backupCopy.NextRetryAt = nil
+	}
+
+	if err := s.backupRepository.SaveCopy(backupCopy); err != nil {
+		s.logger.Error("Failed to save backup copy", "copyId", backupCopy.ID, "error", err)
+	}
+}
+
+// planCopyRetry defers retry of the failed copy with the backoff of
+// the backup config, so unavailable storage is not hammered every minute
+func (s *BackupCopyBackgroundService) planCopyRetry(backupCopy *BackupCopy) {
+	backupCopy.FailedTriesCount++
+
+	backup, err := s.backupRepository.FindByID(backupCopy.BackupID)
+	if err != nil {
+		s.logger.Error("Failed to find backup of the copy", "copyId", backupCopy.ID, "error", err)
+		return
+	}
+
+	backupConfig, err := s.backupConfigService.GetBackupConfigByDbId(backup.DatabaseID)
+	if err != nil {
+		s.logger.Error("Failed to get backup config", "databaseId", backup.DatabaseID, "error", err)
+		return
+	}
+
+	nextRetryAt := time.Now().UTC().Add(
+		getRetryDelay(backupConfig, backupCopy.FailedTriesCount, rand.Float64()),
+	)
+	backupCopy.NextRetryAt = &nextRetryAt
+}
+
+func (s *BackupCopyBackgroundService) copyBackup(backupCopy *BackupCopy) error {
+	backup, err := s.backupRepository.FindByID(backupCopy.BackupID)
+	if err != nil {
+		return err
+	}
+
+	return s.backupService.copyBackup(context.Background(), backup, backupCopy)
+}
+
+// cleanOldCopies goes over all databases having copies rather than
+// configs with copy targets, so copies of removed targets and of
+// databases with disabled backups expire as well
+func (s *BackupCopyBackgroundService) cleanOldCopies() error {
+	databaseIDs, err := s.backupRepository.FindDatabaseIDsWithCopies()
+	if err != nil {
+		return err
+	}
+
+	now := time.Now().UTC()
+
+	for _, databaseID := range databaseIDs {
+		backupConfig, err := s.backupConfigService.GetBackupConfigByDbId(databaseID)
+		if err != nil {
+			s.logger.Error("Failed to get backup config", "databaseId", databaseID, "error", err)
+			continue
+		}
+
+		backups, err := s.backupRepository.FindByDatabaseID(databaseID)
+		if err != nil {
+			s.logger.Error(
+				"Failed to find backups for database",
+				"databaseId",
+				databaseID,
+				"error",
+				err,
+			)
+			continue
+		}
+
+		for backupCopy, backup := range getCopiesToDelete(backups, backupConfig, now) {
+			if err := s.backupService.deleteBackupCopy(backup, backupCopy); err != nil {
+				s.logger.Error(
+					"Failed to delete old backup copy",
+					"backupId",
+					backup.ID,
+					"storageId",
+					backupCopy.StorageID,
+					"error",
+					err,
+				)
+				continue
+			}
+
+			s.logger.Info(
+				"Deleted old backup copy",
+				"backupId",
+				backup.ID,
+				"storageId",
+				backupCopy.StorageID,
+			)
+		}
+	}
+
+	return nil
+}
@@ -5,6 +5,7 @@ import (
 
 	audit_logs "postgresus-backend/internal/features/audit_logs"
 	"postgresus-backend/internal/features/backups/backups/usecases"
+	backups_blackouts "postgresus-backend/internal/features/backups/blackouts"
 	backups_config "postgresus-backend/internal/features/backups/config"
 	"postgresus-backend/internal/features/databases"
 	encryption_secrets "postgresus-backend/internal/features/encryption/secrets"
@@ -34,17 +35,25 @@ var backupService = &BackupService{
 	workspaces_services.GetWorkspaceService(),
 	audit_logs.GetAuditLogService(),
 	backupContextManager,
+	backups_blackouts.GetBlackoutWindowService(),
 }
 
 var backupBackgroundService = &BackupBackgroundService{
 	backupService,
 	backupRepository,
 	backups_config.GetBackupConfigService(),
-	storages.GetStorageService(),
+	backups_blackouts.GetBlackoutWindowService(),
 	time.Now().UTC(),
 	logger.GetLogger(),
 }
 
+var backupCopyBackgroundService = &BackupCopyBackgroundService{
+	backupService,
+	backupRepository,
+	backups_config.GetBackupConfigService(),
+	logger.GetLogger(),
+}
+
 var backupController = &BackupController{
 	backupService,
 }
@@ -55,6 +64,7 @@ func SetupDependencies() {
 		SetDatabaseStorageChangeListener(backupService)
 
 	databases.GetDatabaseService().AddDbRemoveListener(backupService)
+	storages.GetStorageService().AddStorageRemoveListener(backupService)
 	databases.GetDatabaseService().AddDbCopyListener(backups_config.GetBackupConfigService())
 }
 
@@ -69,3 +79,7 @@ func GetBackupController() *BackupController {
 func GetBackupBackgroundService() *BackupBackgroundService {
 	return backupBackgroundService
 }
+
+func GetBackupCopyBackgroundService() *BackupCopyBackgroundService {
+	return backupCopyBackgroundService
+}
@@ -3,6 +3,8 @@ package backups
 import (
 	"io"
 	"postgresus-backend/internal/features/backups/backups/encryption"
+
+	"github.com/google/uuid"
 )
 
 type GetBackupsRequest struct {
@@ -18,6 +20,19 @@ type GetBackupsResponse struct {
 	Offset  int       `json:"offset"`
 }
 
+type MakeBackupResponse struct {
+	Message string  `json:"message"`
+	Warning *string `json:"warning,omitempty"`
+}
+
+type PinBackupRequest struct {
+	Reason string `json:"reason" binding:"required"`
+}
+
+type CopyBackupRequest struct {
+	StorageID uuid.UUID `json:"storageId" binding:"required"`
+}
+
 type decryptionReaderCloser struct {
 	*encryption.DecryptionReader
 	baseReader io.ReadCloser
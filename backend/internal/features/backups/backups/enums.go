@@ -3,8 +3,20 @@ package backups
 type BackupStatus string
 
 const (
+	// waits for free slot of concurrency limits
+	BackupStatusQueued     BackupStatus = "QUEUED"
 	BackupStatusInProgress BackupStatus = "IN_PROGRESS"
 	BackupStatusCompleted  BackupStatus = "COMPLETED"
 	BackupStatusFailed     BackupStatus = "FAILED"
 	BackupStatusCanceled   BackupStatus = "CANCELED"
 )
+
+type BackupCopyStatus string
+
+const (
+	// waits for the background copier
+	BackupCopyStatusPending    BackupCopyStatus = "PENDING"
+	BackupCopyStatusInProgress BackupCopyStatus = "IN_PROGRESS"
+	BackupCopyStatusCompleted  BackupCopyStatus = "COMPLETED"
+	BackupCopyStatusFailed     BackupCopyStatus = "FAILED"
+)
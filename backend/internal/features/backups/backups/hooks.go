@@ -0,0 +1,175 @@
+package backups
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"postgresus-backend/internal/config"
+	usecases_postgresql "postgresus-backend/internal/features/backups/backups/usecases/postgresql"
+	backups_config "postgresus-backend/internal/features/backups/config"
+	"postgresus-backend/internal/features/databases"
+
+	"github.com/google/uuid"
+)
+
+// output of all hooks of the backup is cut to this length
+const maxHooksOutputLength = 64 * 1024
+
+// shell hook may start children which keep output pipes open
+// after it is killed, so waiting for them is limited
+const shellHookWaitDelay = 5 * time.Second
+
+// runBackupHooks runs hooks of the stage one by one and writes their output
+// to the backup. Pre hooks stop on the first failure which fails the backup,
+// post hooks are all run, so cleanup is not skipped
+func (s *BackupService) runBackupHooks(
+	ctx context.Context,
+	backupConfig *backups_config.BackupConfig,
+	database *databases.Database,
+	backup *Backup,
+	stage backups_config.BackupHookStage,
+	resultStatus BackupStatus,
+) error {
+	var firstErr error
+
+	for i, hook := range backupConfig.GetHooks(stage) {
+		output, err := s.runBackupHook(ctx, hook, database, backup, resultStatus)
+		appendHookOutput(backup, fmt.Sprintf("%s #%d (%s)", stage, i+1, hook.Type), output, err)
+
+		if err == nil || !hook.IsFailBackupOnError {
+			continue
+		}
+
+		hookErr := fmt.Errorf(
+			"%s-backup hook #%d failed: %w",
+			strings.ToLower(string(stage)),
+			i+1,
+			err,
+		)
+
+		if stage == backups_config.BackupHookStagePre {
+			return hookErr
+		}
+
+		if firstErr == nil {
+			firstErr = hookErr
+		}
+	}
+
+	return firstErr
+}
+
+func (s *BackupService) runBackupHook(
+	ctx context.Context,
+	hook *backups_config.BackupHook,
+	database *databases.Database,
+	backup *Backup,
+	resultStatus BackupStatus,
+) (string, error) {
+	hookCtx, cancel := context.WithTimeout(ctx, hook.GetTimeout())
+	defer cancel()
+
+	var output string
+	var err error
+
+	switch hook.Type {
+	case backups_config.BackupHookTypeSql:
+		output, err = database.ExecuteSql(hookCtx, s.logger, s.fieldEncryptor, hook.Command)
+	case backups_config.BackupHookTypeShell:
+		// the flag may be turned off after the hook is saved
+		if !config.GetEnv().IsShellHooksEnabled {
+			return "", errors.New("shell hooks are disabled")
+		}
+
+		output, err = runShellHook(hookCtx, hook.Command, []string{
+			"POSTGRESUS_BACKUP_ID=" + backup.ID.String(),
+			"POSTGRESUS_BACKUP_STATUS=" + string(resultStatus),
+			"POSTGRESUS_DATABASE_ID=" + database.ID.String(),
+			"POSTGRESUS_DATABASE_NAME=" + database.Name,
+		})
+	default:
+		return "", fmt.Errorf("unknown hook type: %s", hook.Type)
+	}
+
+	if err != nil && errors.Is(hookCtx.Err(), context.DeadlineExceeded) {
+		return output, fmt.Errorf("hook timed out after %s", hook.GetTimeout())
+	}
+
+	return output, err
+}
+
+// getUploadedFileIDs returns IDs of files saved by the backup
+// usecase, the backup itself does not reference them yet
+func getUploadedFileIDs(
+	backup *Backup,
+	backupMetadata *usecases_postgresql.BackupMetadata,
+) []uuid.UUID {
+	fileIDs := []uuid.UUID{}
+
+	if !backup.IsWholeServer {
+		fileIDs = append(fileIDs, backup.ID)
+	}
+
+	if backupMetadata == nil {
+		return fileIDs
+	}
+
+	if backupMetadata.GlobalsFileID != nil {
+		fileIDs = append(fileIDs, *backupMetadata.GlobalsFileID)
+	}
+
+	for _, artifact := range backupMetadata.Artifacts {
+		fileIDs = append(fileIDs, artifact.FileID)
+	}
+
+	return fileIDs
+}
+
+// runShellHook runs the command with PATH and the given POSTGRESUS_*
+// variables only: environment of Postgresus contains its secrets
+func runShellHook(ctx context.Context, command string, env []string) (string, error) {
+	cmd := exec.CommandContext(ctx, "sh", "-c", command)
+	cmd.Env = append([]string{"PATH=" + os.Getenv("PATH")}, env...)
+	cmd.WaitDelay = shellHookWaitDelay
+
+	output, err := cmd.CombinedOutput()
+	return string(output), err
+}
+
+func appendHookOutput(backup *Backup, title string, output string, err error) {
+	var builder strings.Builder
+	if backup.HooksOutput != nil {
+		builder.WriteString(*backup.HooksOutput)
+	}
+
+	builder.WriteString("[" + title + "] ")
+	if err != nil {
+		builder.WriteString("failed: " + err.Error())
+	} else {
+		builder.WriteString("succeeded")
+	}
+	builder.WriteString("\n")
+
+	if output = strings.TrimSpace(output); output != "" {
+		builder.WriteString(output + "\n")
+	}
+
+	hooksOutput := builder.String()
+	if len(hooksOutput) > maxHooksOutputLength {
+		// cut on rune boundary, so the output stays valid UTF-8
+		cutLength := maxHooksOutputLength
+		for cutLength > 0 && !utf8.RuneStart(hooksOutput[cutLength]) {
+			cutLength--
+		}
+
+		hooksOutput = hooksOutput[:cutLength]
+	}
+
+	backup.HooksOutput = &hooksOutput
+}
@@ -0,0 +1,109 @@
+package backups
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	usecases_postgresql "postgresus-backend/internal/features/backups/backups/usecases/postgresql"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_RunShellHook_OutputAndEnvCaptured(t *testing.T) {
+	output, err := runShellHook(
+		context.Background(),
+		`echo "backup $POSTGRESUS_BACKUP_ID"; echo "to stderr" >&2`,
+		[]string{"POSTGRESUS_BACKUP_ID=42"},
+	)
+
+	assert.NoError(t, err)
+	assert.Contains(t, output, "backup 42")
+	assert.Contains(t, output, "to stderr")
+}
+
+func Test_RunShellHook_ProcessEnv_NotPassedToHook(t *testing.T) {
+	t.Setenv("POSTGRESUS_TEST_SECRET", "secret")
+
+	output, err := runShellHook(
+		context.Background(),
+		`echo "secret=$POSTGRESUS_TEST_SECRET"; command -v sh`,
+		nil,
+	)
+
+	assert.NoError(t, err)
+	assert.Contains(t, output, "secret=\n")
+	assert.NotContains(t, output, "secret=secret")
+}
+
+func Test_RunShellHook_NonZeroExitCode_ErrorReturned(t *testing.T) {
+	output, err := runShellHook(context.Background(), "echo failing; exit 3", nil)
+
+	assert.Error(t, err)
+	assert.Contains(t, output, "failing")
+}
+
+func Test_RunShellHook_TimeoutExceeded_HookKilled(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err := runShellHook(ctx, "sleep 10", nil)
+
+	assert.Error(t, err)
+	assert.Less(t, time.Since(start), shellHookWaitDelay+2*time.Second)
+}
+
+func Test_AppendHookOutput_OutputOfHooksJoined(t *testing.T) {
+	backup := &Backup{}
+
+	appendHookOutput(backup, "PRE #1 (SQL)", "CHECKPOINT", nil)
+	appendHookOutput(backup, "POST #1 (SHELL)", "", errors.New("exit status 1"))
+
+	assert.Equal(
+		t,
+		"[PRE #1 (SQL)] succeeded\nCHECKPOINT\n[POST #1 (SHELL)] failed: exit status 1\n",
+		*backup.HooksOutput,
+	)
+}
+
+func Test_AppendHookOutput_LongOutput_OutputCut(t *testing.T) {
+	backup := &Backup{}
+
+	appendHookOutput(backup, "PRE #1 (SHELL)", strings.Repeat("a", maxHooksOutputLength*2), nil)
+
+	assert.Len(t, *backup.HooksOutput, maxHooksOutputLength)
+}
+
+func Test_GetUploadedFileIDs_WholeServerBackup_GlobalsAndArtifactsReturned(t *testing.T) {
+	globalsFileID := uuid.New()
+	artifactID := uuid.New()
+
+	backup := &Backup{ID: uuid.New(), IsWholeServer: true}
+	backupMetadata := &usecases_postgresql.BackupMetadata{
+		GlobalsFileID: &globalsFileID,
+		Artifacts:     []*usecases_postgresql.ArtifactMetadata{{FileID: artifactID}},
+	}
+
+	assert.Equal(
+		t,
+		[]uuid.UUID{globalsFileID, artifactID},
+		getUploadedFileIDs(backup, backupMetadata),
+	)
+
+	backup.IsWholeServer = false
+	assert.Equal(t, []uuid.UUID{backup.ID}, getUploadedFileIDs(backup, nil))
+}
+
+func Test_AppendHookOutput_LongMultibyteOutput_OutputCutOnRuneBoundary(t *testing.T) {
+	backup := &Backup{}
+
+	appendHookOutput(backup, "PRE #1 (SHELL)", strings.Repeat("я", maxHooksOutputLength), nil)
+
+	assert.LessOrEqual(t, len(*backup.HooksOutput), maxHooksOutputLength)
+	assert.True(t, utf8.ValidString(*backup.HooksOutput))
+}
@@ -16,13 +16,124 @@ type Backup struct {
 	Status      BackupStatus `json:"status"      gorm:"column:status;not null"`
 	FailMessage *string      `json:"failMessage" gorm:"column:fail_message"`
 
+	// set on failed backup when retry is planned with backoff
+	NextRetryAt *time.Time `json:"nextRetryAt" gorm:"column:next_retry_at"`
+
+	Method backups_config.BackupMethod `json:"method" gorm:"column:method;type:text;not null;default:'PG_DUMP'"`
+
+	Format      backups_config.BackupFormat      `json:"format"      gorm:"column:format;type:text;not null;default:'CUSTOM'"`
+	Compression backups_config.BackupCompression `json:"compression" gorm:"column:compression;type:text;not null;default:'ZSTD'"`
+
+	// whole-server backup has no own file, each database is saved as artifact
+	IsWholeServer bool              `json:"isWholeServer" gorm:"column:is_whole_server;type:boolean;not null;default:false"`
+	Artifacts     []*BackupArtifact `json:"artifacts"     gorm:"foreignKey:BackupID"`
+
 	BackupSizeMb float64 `json:"backupSizeMb" gorm:"column:backup_size_mb;default:0"`
 
 	BackupDurationMs int64 `json:"backupDurationMs" gorm:"column:backup_duration_ms;default:0"`
 
+	// raw size of the data at the start, gives compression ratio for the next backups
+	DatabaseSizeMb  float64 `json:"databaseSizeMb"  gorm:"column:database_size_mb;default:0"`
+	EstimatedSizeMb float64 `json:"estimatedSizeMb" gorm:"column:estimated_size_mb;default:0"`
+
+	// progress of in-progress backup, filled on read
+	ProgressPercent       *float64 `json:"progressPercent,omitempty"       gorm:"-"`
+	ThroughputMbPerSecond *float64 `json:"throughputMbPerSecond,omitempty" gorm:"-"`
+	EtaSeconds            *int64   `json:"etaSeconds,omitempty"            gorm:"-"`
+
 	EncryptionSalt *string                         `json:"-"          gorm:"column:encryption_salt"`
 	EncryptionIV   *string                         `json:"-"          gorm:"column:encryption_iv"`
 	Encryption     backups_config.BackupEncryption `json:"encryption" gorm:"column:encryption;type:text;not null;default:'NONE'"`
 
+	// SHA-256 of the stored (compressed and encrypted) bytes
+	Checksum *string `json:"checksum" gorm:"column:checksum;type:text"`
+
+	// pg_dumpall --globals-only output, encrypted with its own salt and IV
+	GlobalsFileID         *uuid.UUID `json:"globalsFileId" gorm:"column:globals_file_id;type:uuid"`
+	GlobalsEncryptionSalt *string    `json:"-"             gorm:"column:globals_encryption_salt"`
+	GlobalsEncryptionIV   *string    `json:"-"             gorm:"column:globals_encryption_iv"`
+	GlobalsChecksum       *string    `json:"-"             gorm:"column:globals_checksum;type:text"`
+
+	// pinned backups are never deleted by retention or storage change
+	IsPinned       bool       `json:"isPinned"       gorm:"column:is_pinned;type:boolean;not null;default:false"`
+	PinReason      *string    `json:"pinReason"      gorm:"column:pin_reason;type:text"`
+	PinnedByUserID *uuid.UUID `json:"pinnedByUserId" gorm:"column:pinned_by_user_id;type:uuid"`
+	PinnedAt       *time.Time `json:"pinnedAt"       gorm:"column:pinned_at"`
+
+	// size or duration differs much from the recent backups
+	IsAnomaly      bool    `json:"isAnomaly"      gorm:"column:is_anomaly;type:boolean;not null;default:false"`
+	AnomalyMessage *string `json:"anomalyMessage" gorm:"column:anomaly_message;type:text"`
+
+	// host:port of the primary or of the replica the backup is taken from
+	SourceHost *string `json:"sourceHost" gorm:"column:source_host;type:text"`
+
+	// output of pre- and post-backup hooks
+	HooksOutput *string `json:"hooksOutput" gorm:"column:hooks_output;type:text"`
+
+	// files are removed from the backup storage by retention,
+	// the backup is kept only in its copies
+	IsRemovedFromStorage bool `json:"isRemovedFromStorage" gorm:"column:is_removed_from_storage;type:boolean;not null;default:false"`
+	// the same files stored in additional storages
+	Copies []*BackupCopy `json:"copies" gorm:"foreignKey:BackupID"`
+
+	// priority of the config when backup is queued
+	QueuePriority int `json:"queuePriority" gorm:"column:queue_priority;type:int;not null;default:0"`
+	// 1-based position of queued backup, filled on read
+	QueuePosition *int `json:"queuePosition,omitempty" gorm:"-"`
+
 	CreatedAt time.Time `json:"createdAt" gorm:"column:created_at"`
 }
+
+// GetFileExtension returns extension of the downloaded backup file
+func (b *Backup) GetFileExtension() string {
+	switch b.Format {
+	case backups_config.BackupFormatDirectory:
+		return ".tar"
+	case backups_config.BackupFormatPlain:
+		return ".sql"
+	default:
+		return ".dump"
+	}
+}
+
+// BackupArtifact is a dump of a single database within whole-server backup.
+// It is saved to the backup storage under its own ID
+type BackupArtifact struct {
+	ID       uuid.UUID `json:"id"       gorm:"column:id;type:uuid;primaryKey"`
+	BackupID uuid.UUID `json:"backupId" gorm:"column:backup_id;type:uuid;not null"`
+
+	DatabaseName string  `json:"databaseName" gorm:"column:database_name;type:text;not null"`
+	SizeMb       float64 `json:"sizeMb"       gorm:"column:size_mb;default:0"`
+
+	EncryptionSalt *string `json:"-"        gorm:"column:encryption_salt"`
+	EncryptionIV   *string `json:"-"        gorm:"column:encryption_iv"`
+	Checksum       *string `json:"checksum" gorm:"column:checksum;type:text"`
+
+	CreatedAt time.Time `json:"createdAt" gorm:"column:created_at"`
+}
+
+func (a *BackupArtifact) TableName() string {
+	return "backup_artifacts"
+}
+
+// BackupCopy is the backup stored in one more storage. Files are copied
+// as they are, so encryption and checksums of the backup apply to them
+type BackupCopy struct {
+	ID        uuid.UUID `json:"id"        gorm:"column:id;type:uuid;primaryKey"`
+	BackupID  uuid.UUID `json:"backupId"  gorm:"column:backup_id;type:uuid;not null"`
+	StorageID uuid.UUID `json:"storageId" gorm:"column:storage_id;type:uuid;not null"`
+
+	Status      BackupCopyStatus `json:"status"      gorm:"column:status;type:text;not null"`
+	FailMessage *string          `json:"failMessage" gorm:"column:fail_message;type:text"`
+
+	// failed copy is retried with the backoff of the backup config
+	FailedTriesCount int        `json:"failedTriesCount" gorm:"column:failed_tries_count;type:int;not null;default:0"`
+	NextRetryAt      *time.Time `json:"nextRetryAt"      gorm:"column:next_retry_at"`
+
+	CreatedAt   time.Time  `json:"createdAt"   gorm:"column:created_at"`
+	CompletedAt *time.Time `json:"completedAt" gorm:"column:completed_at"`
+}
+
+func (c *BackupCopy) TableName() string {
+	return "backup_copies"
+}
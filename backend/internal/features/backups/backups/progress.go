@@ -0,0 +1,60 @@
+package backups
+
+import (
+	"math"
+)
+
+// progress is never shown as done before the backup is completed,
+// because the estimated size is not exact
+const maxInProgressPercent = 99.0
+
+// estimateBackupSizeMb estimates stored size of the backup. The previous
+// backup made the same way gives compression ratio of the data, without
+// it the database size is used as the upper bound
+func estimateBackupSizeMb(
+	backup *Backup,
+	databaseSizeMb float64,
+	previousBackup *Backup,
+) float64 {
+	if previousBackup == nil || !isComparableBackup(backup, previousBackup) ||
+		previousBackup.BackupSizeMb <= 0 {
+		return databaseSizeMb
+	}
+
+	if databaseSizeMb <= 0 || previousBackup.DatabaseSizeMb <= 0 {
+		return previousBackup.BackupSizeMb
+	}
+
+	compressionRatio := previousBackup.BackupSizeMb / previousBackup.DatabaseSizeMb
+	return databaseSizeMb * compressionRatio
+}
+
+// fillBackupProgress sets progress, throughput and ETA of in-progress
+// backup from the size and duration reported by the last progress update
+func fillBackupProgress(backup *Backup) {
+	if backup.Status != BackupStatusInProgress || backup.BackupDurationMs <= 0 {
+		return
+	}
+
+	throughput := backup.BackupSizeMb / (float64(backup.BackupDurationMs) / 1000)
+	backup.ThroughputMbPerSecond = &throughput
+
+	if backup.EstimatedSizeMb <= 0 {
+		return
+	}
+
+	progressPercent := math.Min(
+		backup.BackupSizeMb/backup.EstimatedSizeMb*100,
+		maxInProgressPercent,
+	)
+	backup.ProgressPercent = &progressPercent
+
+	if throughput <= 0 {
+		return
+	}
+
+	etaSeconds := int64(math.Ceil(
+		math.Max(backup.EstimatedSizeMb-backup.BackupSizeMb, 0) / throughput,
+	))
+	backup.EtaSeconds = &etaSeconds
+}
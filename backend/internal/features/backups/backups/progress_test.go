@@ -0,0 +1,95 @@
+package backups
+
+import (
+	"testing"
+
+	backups_config "postgresus-backend/internal/features/backups/config"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_EstimateBackupSizeMb_PreviousBackupExists_CompressionRatioApplied(t *testing.T) {
+	previousBackup := createTestProgressBackup(BackupStatusCompleted)
+	previousBackup.BackupSizeMb = 250
+	previousBackup.DatabaseSizeMb = 1000
+
+	estimatedSizeMb := estimateBackupSizeMb(
+		createTestProgressBackup(BackupStatusInProgress),
+		2000,
+		previousBackup,
+	)
+
+	assert.Equal(t, 500.0, estimatedSizeMb)
+}
+
+func Test_EstimateBackupSizeMb_NoPreviousBackup_DatabaseSizeUsed(t *testing.T) {
+	estimatedSizeMb := estimateBackupSizeMb(
+		createTestProgressBackup(BackupStatusInProgress),
+		2000,
+		nil,
+	)
+
+	assert.Equal(t, 2000.0, estimatedSizeMb)
+}
+
+func Test_EstimateBackupSizeMb_DatabaseSizeUnknown_PreviousSizeUsed(t *testing.T) {
+	previousBackup := createTestProgressBackup(BackupStatusCompleted)
+	previousBackup.BackupSizeMb = 250
+
+	estimatedSizeMb := estimateBackupSizeMb(
+		createTestProgressBackup(BackupStatusInProgress),
+		0,
+		previousBackup,
+	)
+
+	assert.Equal(t, 250.0, estimatedSizeMb)
+}
+
+func Test_FillBackupProgress_InProgressBackup_ProgressThroughputAndEtaFilled(t *testing.T) {
+	backup := createTestProgressBackup(BackupStatusInProgress)
+	backup.EstimatedSizeMb = 400
+	backup.BackupSizeMb = 100
+	backup.BackupDurationMs = 50_000
+
+	fillBackupProgress(backup)
+
+	assert.Equal(t, 25.0, *backup.ProgressPercent)
+	assert.Equal(t, 2.0, *backup.ThroughputMbPerSecond)
+	assert.Equal(t, int64(150), *backup.EtaSeconds)
+}
+
+func Test_FillBackupProgress_EstimationExceeded_ProgressNotCompleted(t *testing.T) {
+	backup := createTestProgressBackup(BackupStatusInProgress)
+	backup.EstimatedSizeMb = 100
+	backup.BackupSizeMb = 150
+	backup.BackupDurationMs = 10_000
+
+	fillBackupProgress(backup)
+
+	assert.Equal(t, maxInProgressPercent, *backup.ProgressPercent)
+	assert.Equal(t, int64(0), *backup.EtaSeconds)
+}
+
+func Test_FillBackupProgress_CompletedBackup_NothingFilled(t *testing.T) {
+	backup := createTestProgressBackup(BackupStatusCompleted)
+	backup.EstimatedSizeMb = 100
+	backup.BackupSizeMb = 100
+	backup.BackupDurationMs = 10_000
+
+	fillBackupProgress(backup)
+
+	assert.Nil(t, backup.ProgressPercent)
+	assert.Nil(t, backup.ThroughputMbPerSecond)
+	assert.Nil(t, backup.EtaSeconds)
+}
+
+func createTestProgressBackup(status BackupStatus) *Backup {
+	return &Backup{
+		ID:          uuid.New(),
+		Status:      status,
+		Method:      backups_config.BackupMethodPgDump,
+		Format:      backups_config.BackupFormatCustom,
+		Compression: backups_config.BackupCompressionZstd,
+	}
+}
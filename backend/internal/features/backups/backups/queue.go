@@ -0,0 +1,286 @@
+package backups
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"postgresus-backend/internal/config"
+	backups_config "postgresus-backend/internal/features/backups/config"
+	"postgresus-backend/internal/features/databases"
+	"postgresus-backend/internal/features/storages"
+
+	"github.com/google/uuid"
+)
+
+// guards the check of concurrency limits and the start of queued
+// backups, so two callers do not start backups for the same free slot.
+// Queueing is guarded too, so a database is not queued twice
+var backupQueueMutex sync.Mutex
+
+// EnqueueBackup adds backup of the database to the queue and starts
+// queued backups, which fit into concurrency limits
+func (s *BackupService) EnqueueBackup(databaseID uuid.UUID) error {
+	if err := s.queueBackup(databaseID); err != nil {
+		return err
+	}
+
+	s.StartQueuedBackups()
+
+	return nil
+}
+
+// StartQueuedBackups starts queued backups in queue order while global
+// and per host limits allow. Backup of a busy host does not block
+// backups of other hosts queued after it
+func (s *BackupService) StartQueuedBackups() {
+	backupQueueMutex.Lock()
+	defer backupQueueMutex.Unlock()
+
+	env := config.GetEnv()
+
+	queuedBackups, err := s.backupRepository.FindQueued(
+		env.BackupsQueueOrder == config.BackupsQueueOrderPriority,
+	)
+	if err != nil {
+		s.logger.Error("Failed to find queued backups", "error", err)
+		return
+	}
+
+	if len(queuedBackups) == 0 {
+		return
+	}
+
+	backupsInProgress, err := s.backupRepository.FindByStatus(BackupStatusInProgress)
+	if err != nil {
+		s.logger.Error("Failed to find backups in progress", "error", err)
+		return
+	}
+
+	runningCount := len(backupsInProgress)
+	runningCountByHost := map[string]int{}
+
+	for _, backup := range backupsInProgress {
+		if backup.SourceHost != nil {
+			runningCountByHost[*backup.SourceHost]++
+			continue
+		}
+
+		database, err := s.databaseService.GetDatabaseByID(backup.DatabaseID)
+		if err != nil {
+			s.logger.Error("Failed to get database by ID", "error", err)
+			continue
+		}
+
+		runningCountByHost[getDatabaseHostKey(database)]++
+	}
+
+	for _, backup := range queuedBackups {
+		if env.MaxConcurrentBackups > 0 && runningCount >= env.MaxConcurrentBackups {
+			return
+		}
+
+		database, err := s.databaseService.GetDatabaseByID(backup.DatabaseID)
+		if err != nil {
+			s.failQueuedBackup(backup, fmt.Sprintf("failed to get database: %v", err))
+			continue
+		}
+
+		hostKey := s.getBackupSourceHostKey(database)
+		if env.MaxConcurrentBackupsPerHost > 0 &&
+			runningCountByHost[hostKey] >= env.MaxConcurrentBackupsPerHost {
+			continue
+		}
+
+		// config might change while backup was waiting in the queue
+		backupConfig, storage, err := s.getBackupConfigAndStorage(database.ID)
+		if err != nil {
+			s.failQueuedBackup(backup, err.Error())
+			continue
+		}
+
+		applyBackupConfig(backup, backupConfig, storage)
+		backup.Status = BackupStatusInProgress
+		backup.SourceHost = &hostKey
+
+		if err := s.backupRepository.Save(backup); err != nil {
+			s.logger.Error("Failed to start queued backup", "backupId", backup.ID, "error", err)
+			continue
+		}
+
+		runningCount++
+		runningCountByHost[hostKey]++
+
+		go func() {
+			// freed slot is given to the next queued backup
+			defer s.StartQueuedBackups()
+
+			s.runBackup(backup, backupConfig, database, storage, true)
+		}()
+	}
+}
+
+// queueBackup checks and saves the backup under the queue lock, so
+// concurrent calls do not queue two backups of the same database
+func (s *BackupService) queueBackup(databaseID uuid.UUID) error {
+	backupQueueMutex.Lock()
+	defer backupQueueMutex.Unlock()
+
+	lastBackup, err := s.backupRepository.FindLastByDatabaseID(databaseID)
+	if err != nil {
+		return err
+	}
+
+	if lastBackup != nil &&
+		(lastBackup.Status == BackupStatusQueued || lastBackup.Status == BackupStatusInProgress) {
+		return errors.New("backup is already queued or in progress")
+	}
+
+	backupConfig, storage, err := s.getBackupConfigAndStorage(databaseID)
+	if err != nil {
+		return err
+	}
+
+	backup := &Backup{
+		DatabaseID: databaseID,
+		Status:     BackupStatusQueued,
+		CreatedAt:  time.Now().UTC(),
+	}
+	applyBackupConfig(backup, backupConfig, storage)
+
+	return s.backupRepository.Save(backup)
+}
+
+func (s *BackupService) fillQueuePositions(backups []*Backup) error {
+	isAnyQueued := false
+	for _, backup := range backups {
+		if backup.Status == BackupStatusQueued {
+			isAnyQueued = true
+			break
+		}
+	}
+
+	if !isAnyQueued {
+		return nil
+	}
+
+	queuedBackups, err := s.backupRepository.FindQueued(
+		config.GetEnv().BackupsQueueOrder == config.BackupsQueueOrderPriority,
+	)
+	if err != nil {
+		return err
+	}
+
+	positions := map[uuid.UUID]int{}
+	for i, backup := range queuedBackups {
+		positions[backup.ID] = i + 1
+	}
+
+	for _, backup := range backups {
+		if position, ok := positions[backup.ID]; ok {
+			backup.QueuePosition = &position
+		}
+	}
+
+	return nil
+}
+
+// cancelQueuedBackup cancels backup, which has not been started yet
+func (s *BackupService) cancelQueuedBackup(backupID uuid.UUID) error {
+	backupQueueMutex.Lock()
+	defer backupQueueMutex.Unlock()
+
+	backup, err := s.backupRepository.FindByID(backupID)
+	if err != nil {
+		return err
+	}
+
+	if backup.Status != BackupStatusQueued {
+		return errors.New("backup has been started already, cancel it again")
+	}
+
+	backup.Status = BackupStatusCanceled
+	return s.backupRepository.Save(backup)
+}
+
+func (s *BackupService) failQueuedBackup(backup *Backup, failMessage string) {
+	s.logger.Error("Failed to start queued backup", "backupId", backup.ID, "error", failMessage)
+
+	backup.Status = BackupStatusFailed
+	backup.FailMessage = &failMessage
+
+	if err := s.backupRepository.Save(backup); err != nil {
+		s.logger.Error("Failed to save backup", "backupId", backup.ID, "error", err)
+	}
+}
+
+func (s *BackupService) getBackupConfigAndStorage(
+	databaseID uuid.UUID,
+) (*backups_config.BackupConfig, *storages.Storage, error) {
+	backupConfig, err := s.backupConfigService.GetBackupConfigByDbId(databaseID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if !backupConfig.IsBackupsEnabled {
+		return nil, nil, errors.New("backups are not enabled for this database")
+	}
+
+	if backupConfig.StorageID == nil {
+		return nil, nil, errors.New("backup config storage ID is not defined")
+	}
+
+	storage, err := s.storageService.GetStorageByID(*backupConfig.StorageID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return backupConfig, storage, nil
+}
+
+func applyBackupConfig(
+	backup *Backup,
+	backupConfig *backups_config.BackupConfig,
+	storage *storages.Storage,
+) {
+	backup.StorageID = storage.ID
+	backup.Method = backupConfig.BackupMethod
+	backup.Format = backupConfig.Format
+	backup.Compression = backupConfig.GetAppliedCompression()
+	backup.IsWholeServer = backupConfig.IsWholeServerBackup
+	backup.QueuePriority = backupConfig.QueuePriority
+}
+
+// getBackupSourceHostKey identifies the server the backup is made from.
+// Backup of database with replicas loads the chosen replica, not the
+// primary. The source is chosen again when the backup is made, so it is
+// saved as the host the backup is finished from
+func (s *BackupService) getBackupSourceHostKey(database *databases.Database) string {
+	if database.Postgresql == nil || len(database.Postgresql.Replicas) == 0 {
+		return getDatabaseHostKey(database)
+	}
+
+	source, err := database.Postgresql.SelectBackupSource(
+		context.Background(),
+		s.logger,
+		s.fieldEncryptor,
+		database.ID,
+	)
+	if err != nil {
+		// the backup fails on the same check when it is started
+		return getDatabaseHostKey(database)
+	}
+
+	return source.GetAddress()
+}
+
+// getDatabaseHostKey identifies the primary server of the database
+func getDatabaseHostKey(database *databases.Database) string {
+	if database.Postgresql == nil {
+		return database.ID.String()
+	}
+
+	return fmt.Sprintf("%s:%d", database.Postgresql.Host, database.Postgresql.Port)
+}
@@ -4,8 +4,6 @@ import (
 	"errors"
 	"postgresus-backend/internal/storage"
 
-	"time"
-
 	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
@@ -22,19 +20,31 @@ func (r *BackupRepository) Save(backup *Backup) error {
 	isNew := backup.ID == uuid.Nil
 	if isNew {
 		backup.ID = uuid.New()
-		return db.Create(backup).
+		return db.Omit("Artifacts", "Copies").
+			Create(backup).
 			Error
 	}
 
-	return db.Save(backup).
+	return db.Omit("Artifacts", "Copies").
+		Save(backup).
 		Error
 }
 
+func (r *BackupRepository) SaveArtifact(artifact *BackupArtifact) error {
+	if artifact.ID == uuid.Nil {
+		artifact.ID = uuid.New()
+	}
+
+	return storage.GetDb().Save(artifact).Error
+}
+
 func (r *BackupRepository) FindByDatabaseID(databaseID uuid.UUID) ([]*Backup, error) {
 	var backups []*Backup
 
 	if err := storage.
 		GetDb().
+		Preload("Artifacts").
+		Preload("Copies").
 		Where("database_id = ?", databaseID).
 		Order("created_at DESC").
 		Find(&backups).Error; err != nil {
@@ -103,6 +113,8 @@ func (r *BackupRepository) FindByID(id uuid.UUID) (*Backup, error) {
 
 	if err := storage.
 		GetDb().
+		Preload("Artifacts").
+		Preload("Copies").
 		Where("id = ?", id).
 		First(&backup).Error; err != nil {
 		return nil, err
@@ -125,6 +137,24 @@ func (r *BackupRepository) FindByStatus(status BackupStatus) ([]*Backup, error)
 	return backups, nil
 }
 
+// FindQueued returns queued backups in the order they should be started
+func (r *BackupRepository) FindQueued(isPriorityOrder bool) ([]*Backup, error) {
+	var backups []*Backup
+
+	query := storage.GetDb().Where("status = ?", BackupStatusQueued)
+	if isPriorityOrder {
+		query = query.Order("queue_priority DESC")
+	}
+
+	if err := query.
+		Order("created_at ASC").
+		Find(&backups).Error; err != nil {
+		return nil, err
+	}
+
+	return backups, nil
+}
+
 func (r *BackupRepository) FindByStorageIdAndStatus(
 	storageID uuid.UUID,
 	status BackupStatus,
@@ -142,6 +172,29 @@ func (r *BackupRepository) FindByStorageIdAndStatus(
 	return backups, nil
 }
 
+func (r *BackupRepository) FindLastByDatabaseIdAndStatus(
+	databaseID uuid.UUID,
+	status BackupStatus,
+) (*Backup, error) {
+	var backup Backup
+
+	if err := storage.
+		GetDb().
+		Preload("Artifacts").
+		Preload("Copies").
+		Where("database_id = ? AND status = ?", databaseID, status).
+		Order("created_at DESC").
+		First(&backup).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	return &backup, nil
+}
+
 func (r *BackupRepository) FindByDatabaseIdAndStatus(
 	databaseID uuid.UUID,
 	status BackupStatus,
@@ -159,20 +212,18 @@ func (r *BackupRepository) FindByDatabaseIdAndStatus(
 	return backups, nil
 }
 
-func (r *BackupRepository) DeleteByID(id uuid.UUID) error {
-	return storage.GetDb().Delete(&Backup{}, "id = ?", id).Error
-}
-
-func (r *BackupRepository) FindBackupsBeforeDate(
+func (r *BackupRepository) FindByDatabaseIdAndStatusWithLimit(
 	databaseID uuid.UUID,
-	date time.Time,
+	status BackupStatus,
+	limit int,
 ) ([]*Backup, error) {
 	var backups []*Backup
 
 	if err := storage.
 		GetDb().
-		Where("database_id = ? AND created_at < ?", databaseID, date).
+		Where("database_id = ? AND status = ?", databaseID, status).
 		Order("created_at DESC").
+		Limit(limit).
 		Find(&backups).Error; err != nil {
 		return nil, err
 	}
@@ -180,6 +231,24 @@ func (r *BackupRepository) FindBackupsBeforeDate(
 	return backups, nil
 }
 
+func (r *BackupRepository) FindArtifactsByBackupID(backupID uuid.UUID) ([]*BackupArtifact, error) {
+	var artifacts []*BackupArtifact
+
+	if err := storage.
+		GetDb().
+		Where("backup_id = ?", backupID).
+		Order("database_name ASC").
+		Find(&artifacts).Error; err != nil {
+		return nil, err
+	}
+
+	return artifacts, nil
+}
+
+func (r *BackupRepository) DeleteByID(id uuid.UUID) error {
+	return storage.GetDb().Delete(&Backup{}, "id = ?", id).Error
+}
+
 func (r *BackupRepository) FindByDatabaseIDWithPagination(
 	databaseID uuid.UUID,
 	limit, offset int,
@@ -188,6 +257,8 @@ func (r *BackupRepository) FindByDatabaseIDWithPagination(
 
 	if err := storage.
 		GetDb().
+		Preload("Artifacts").
+		Preload("Copies").
 		Where("database_id = ?", databaseID).
 		Order("created_at DESC").
 		Limit(limit).
@@ -212,3 +283,77 @@ func (r *BackupRepository) CountByDatabaseID(databaseID uuid.UUID) (int64, error
 
 	return count, nil
 }
+
+func (r *BackupRepository) SaveCopy(backupCopy *BackupCopy) error {
+	if backupCopy.BackupID == uuid.Nil || backupCopy.StorageID == uuid.Nil {
+		return errors.New("backup ID and storage ID are required")
+	}
+
+	if backupCopy.ID == uuid.Nil {
+		backupCopy.ID = uuid.New()
+	}
+
+	return storage.GetDb().Save(backupCopy).Error
+}
+
+func (r *BackupRepository) FindCopiesByStatus(status BackupCopyStatus) ([]*BackupCopy, error) {
+	var backupCopies []*BackupCopy
+
+	if err := storage.
+		GetDb().
+		Where("status = ?", status).
+		Order("created_at ASC").
+		Find(&backupCopies).Error; err != nil {
+		return nil, err
+	}
+
+	return backupCopies, nil
+}
+
+func (r *BackupRepository) FindCopiesByBackupID(backupID uuid.UUID) ([]*BackupCopy, error) {
+	var backupCopies []*BackupCopy
+
+	if err := storage.
+		GetDb().
+		Where("backup_id = ?", backupID).
+		Order("created_at ASC").
+		Find(&backupCopies).Error; err != nil {
+		return nil, err
+	}
+
+	return backupCopies, nil
+}
+
+func (r *BackupRepository) FindCopiesByStorageID(storageID uuid.UUID) ([]*BackupCopy, error) {
+	var backupCopies []*BackupCopy
+
+	if err := storage.
+		GetDb().
+		Where("storage_id = ?", storageID).
+		Find(&backupCopies).Error; err != nil {
+		return nil, err
+	}
+
+	return backupCopies, nil
+}
+
+// FindDatabaseIDsWithCopies returns databases having copies of backups,
+// including databases whose configs do not copy backups anymore
+func (r *BackupRepository) FindDatabaseIDsWithCopies() ([]uuid.UUID, error) {
+	var databaseIDs []uuid.UUID
+
+	if err := storage.
+		GetDb().
+		Table("backup_copies").
+		Joins("JOIN backups ON backups.id = backup_copies.backup_id").
+		Distinct("backups.database_id").
+		Pluck("backups.database_id", &databaseIDs).Error; err != nil {
+		return nil, err
+	}
+
+	return databaseIDs, nil
+}
+
+func (r *BackupRepository) DeleteCopyByID(id uuid.UUID) error {
+	return storage.GetDb().Delete(&BackupCopy{}, "id = ?", id).Error
+}
@@ -0,0 +1,192 @@
+package backups
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	backups_config "postgresus-backend/internal/features/backups/config"
+	"postgresus-backend/internal/util/period"
+
+	"github.com/google/uuid"
+)
+
+// getBackupsToDelete applies retention policy of the config to the backups
+// of the database. Pinned backups and the last MinKeepCount completed
+// backups are never returned, whatever the policy is
+func getBackupsToDelete(
+	backups []*Backup,
+	backupConfig *backups_config.BackupConfig,
+	now time.Time,
+) []*Backup {
+	var backupsToDelete []*Backup
+
+	switch backupConfig.RetentionPolicyType {
+	case backups_config.RetentionPolicyGfs:
+		backupsToDelete = getBackupsToDeleteByGfs(backups, backupConfig)
+	case backups_config.RetentionPolicyCount:
+		backupsToDelete = getBackupsToDeleteByCount(backups, backupConfig.KeepLastCount)
+	default:
+		backupsToDelete = getBackupsToDeleteByStorePeriod(backups, backupConfig.StorePeriod, now)
+	}
+
+	protectedBackupIDs := map[uuid.UUID]bool{}
+	for i, backup := range getCompletedBackupsNewestFirst(backups) {
+		if i >= backupConfig.MinKeepCount {
+			break
+		}
+
+		protectedBackupIDs[backup.ID] = true
+	}
+
+	return slices.DeleteFunc(backupsToDelete, func(backup *Backup) bool {
+		return backup.IsPinned || protectedBackupIDs[backup.ID]
+	})
+}
+
+// getBackupsToDeleteByStorePeriod returns finished backups older than the
+// store period. Queued and running backups are never deleted by retention
+func getBackupsToDeleteByStorePeriod(
+	backups []*Backup,
+	storePeriod period.Period,
+	now time.Time,
+) []*Backup {
+	backupsToDelete := []*Backup{}
+
+	if storePeriod == period.PeriodForever {
+		return backupsToDelete
+	}
+
+	dateBeforeBackupsShouldBeDeleted := now.Add(-storePeriod.ToDuration())
+
+	for _, backup := range backups {
+		if backup.Status != BackupStatusCompleted &&
+			backup.Status != BackupStatusFailed &&
+			backup.Status != BackupStatusCanceled {
+			continue
+		}
+
+		if backup.CreatedAt.Before(dateBeforeBackupsShouldBeDeleted) {
+			backupsToDelete = append(backupsToDelete, backup)
+		}
+	}
+
+	return backupsToDelete
+}
+
+func getBackupsToDeleteByCount(backups []*Backup, keepLastCount int) []*Backup {
+	keptBackupIDs := map[uuid.UUID]bool{}
+
+	for i, backup := range getCompletedBackupsNewestFirst(backups) {
+		if i >= keepLastCount {
+			break
+		}
+
+		keptBackupIDs[backup.ID] = true
+	}
+
+	return getNotKeptBackups(backups, keptBackupIDs)
+}
+
+// getBackupsToDeleteByGfs returns backups which do not survive
+// grandfather-father-son retention. For each of the last N hours, days,
+// weeks, months and years the latest completed backup is kept
+func getBackupsToDeleteByGfs(
+	backups []*Backup,
+	backupConfig *backups_config.BackupConfig,
+) []*Backup {
+	completedBackups := getCompletedBackupsNewestFirst(backups)
+	keptBackupIDs := map[uuid.UUID]bool{}
+
+	keepLatestPerPeriod(completedBackups, backupConfig.GfsHourlyCount, keptBackupIDs, func(t time.Time) string {
+		return t.Format("2006-01-02T15")
+	})
+	keepLatestPerPeriod(completedBackups, backupConfig.GfsDailyCount, keptBackupIDs, func(t time.Time) string {
+		return t.Format("2006-01-02")
+	})
+	keepLatestPerPeriod(completedBackups, backupConfig.GfsWeeklyCount, keptBackupIDs, func(t time.Time) string {
+		year, week := t.ISOWeek()
+		return fmt.Sprintf("%d-W%02d", year, week)
+	})
+	keepLatestPerPeriod(completedBackups, backupConfig.GfsMonthlyCount, keptBackupIDs, func(t time.Time) string {
+		return t.Format("2006-01")
+	})
+	keepLatestPerPeriod(completedBackups, backupConfig.GfsYearlyCount, keptBackupIDs, func(t time.Time) string {
+		return t.Format("2006")
+	})
+
+	return getNotKeptBackups(backups, keptBackupIDs)
+}
+
+// getNotKeptBackups returns completed backups which are not kept. Failed
+// and canceled backups are returned once they are older than every kept one
+func getNotKeptBackups(backups []*Backup, keptBackupIDs map[uuid.UUID]bool) []*Backup {
+	var oldestKeptTime *time.Time
+	for _, backup := range backups {
+		if keptBackupIDs[backup.ID] &&
+			(oldestKeptTime == nil || backup.CreatedAt.Before(*oldestKeptTime)) {
+			oldestKeptTime = &backup.CreatedAt
+		}
+	}
+
+	backupsToDelete := []*Backup{}
+
+	for _, backup := range backups {
+		switch backup.Status {
+		case BackupStatusCompleted:
+			if !keptBackupIDs[backup.ID] {
+				backupsToDelete = append(backupsToDelete, backup)
+			}
+		case BackupStatusFailed, BackupStatusCanceled:
+			if oldestKeptTime != nil && backup.CreatedAt.Before(*oldestKeptTime) {
+				backupsToDelete = append(backupsToDelete, backup)
+			}
+		}
+	}
+
+	return backupsToDelete
+}
+
+func getCompletedBackupsNewestFirst(backups []*Backup) []*Backup {
+	completedBackups := make([]*Backup, 0, len(backups))
+	for _, backup := range backups {
+		if backup.Status == BackupStatusCompleted {
+			completedBackups = append(completedBackups, backup)
+		}
+	}
+
+	slices.SortFunc(completedBackups, func(a, b *Backup) int {
+		return b.CreatedAt.Compare(a.CreatedAt)
+	})
+
+	return completedBackups
+}
+
+// keepLatestPerPeriod marks the latest backup of each of the last count
+// periods as kept. Backups are sorted from newest to oldest
+func keepLatestPerPeriod(
+	backups []*Backup,
+	count int,
+	keptBackupIDs map[uuid.UUID]bool,
+	getPeriodKey func(t time.Time) string,
+) {
+	if count <= 0 {
+		return
+	}
+
+	seenPeriods := map[string]bool{}
+
+	for _, backup := range backups {
+		periodKey := getPeriodKey(backup.CreatedAt.UTC())
+		if seenPeriods[periodKey] {
+			continue
+		}
+
+		seenPeriods[periodKey] = true
+		keptBackupIDs[backup.ID] = true
+
+		if len(seenPeriods) >= count {
+			return
+		}
+	}
+}
@@ -0,0 +1,179 @@
+package backups
+
+import (
+	"testing"
+	"time"
+
+	backups_config "postgresus-backend/internal/features/backups/config"
+	"postgresus-backend/internal/util/period"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_GetBackupsToDeleteByGfs_LatestBackupOfEachPeriodKept(t *testing.T) {
+	now := time.Date(2026, 10, 17, 12, 30, 0, 0, time.UTC)
+
+	// backups every 6 hours for 60 days
+	backups := []*Backup{}
+	for i := 0; i < 60*4; i++ {
+		backups = append(backups, createTestRetentionBackup(
+			now.Add(-time.Duration(i)*6*time.Hour),
+			BackupStatusCompleted,
+		))
+	}
+
+	backupsToDelete := getBackupsToDeleteByGfs(backups, &backups_config.BackupConfig{
+		RetentionPolicyType: backups_config.RetentionPolicyGfs,
+		GfsHourlyCount:      2,
+		GfsDailyCount:       3,
+		GfsMonthlyCount:     2,
+	})
+
+	keptBackups := getKeptBackups(backups, backupsToDelete)
+
+	// 2 hourly (today 12:30 and 06:30), daily of yesterday and the day
+	// before (today is already kept), previous month's last backup
+	assert.Len(t, keptBackups, 5)
+	assert.Equal(t, now, keptBackups[0].CreatedAt)
+	assert.Equal(t, now.Add(-6*time.Hour), keptBackups[1].CreatedAt)
+	assert.Equal(t, time.Date(2026, 10, 16, 18, 30, 0, 0, time.UTC), keptBackups[2].CreatedAt)
+	assert.Equal(t, time.Date(2026, 10, 15, 18, 30, 0, 0, time.UTC), keptBackups[3].CreatedAt)
+	assert.Equal(t, time.Date(2026, 9, 30, 18, 30, 0, 0, time.UTC), keptBackups[4].CreatedAt)
+}
+
+func Test_GetBackupsToDeleteByGfs_FailedBackupsDeletedWhenOlderThanKept(t *testing.T) {
+	now := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+
+	recentFailedBackup := createTestRetentionBackup(now.Add(-1*time.Hour), BackupStatusFailed)
+	oldFailedBackup := createTestRetentionBackup(now.Add(-72*time.Hour), BackupStatusFailed)
+	inProgressBackup := createTestRetentionBackup(now.Add(-96*time.Hour), BackupStatusInProgress)
+
+	backups := []*Backup{
+		createTestRetentionBackup(now, BackupStatusCompleted),
+		recentFailedBackup,
+		createTestRetentionBackup(now.Add(-24*time.Hour), BackupStatusCompleted),
+		createTestRetentionBackup(now.Add(-48*time.Hour), BackupStatusCompleted),
+		oldFailedBackup,
+		inProgressBackup,
+	}
+
+	backupsToDelete := getBackupsToDeleteByGfs(backups, &backups_config.BackupConfig{
+		RetentionPolicyType: backups_config.RetentionPolicyGfs,
+		GfsDailyCount:       2,
+	})
+
+	assert.Len(t, backupsToDelete, 2)
+	assert.Equal(t, backups[3].ID, backupsToDelete[0].ID)
+	assert.Equal(t, oldFailedBackup.ID, backupsToDelete[1].ID)
+}
+
+func Test_GetBackupsToDelete_WhenCountPolicy_LastCompletedBackupsKept(t *testing.T) {
+	now := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+
+	backups := []*Backup{
+		createTestRetentionBackup(now, BackupStatusFailed),
+		createTestRetentionBackup(now.Add(-24*time.Hour), BackupStatusCompleted),
+		createTestRetentionBackup(now.Add(-48*time.Hour), BackupStatusCompleted),
+		createTestRetentionBackup(now.Add(-72*time.Hour), BackupStatusCompleted),
+		createTestRetentionBackup(now.Add(-96*time.Hour), BackupStatusCompleted),
+	}
+
+	backupsToDelete := getBackupsToDelete(backups, &backups_config.BackupConfig{
+		RetentionPolicyType: backups_config.RetentionPolicyCount,
+		KeepLastCount:       2,
+	}, now)
+
+	assert.Len(t, backupsToDelete, 2)
+	assert.Equal(t, backups[3].ID, backupsToDelete[0].ID)
+	assert.Equal(t, backups[4].ID, backupsToDelete[1].ID)
+}
+
+func Test_GetBackupsToDelete_WhenBackupsFailForLong_LastCompletedBackupsNotDeleted(t *testing.T) {
+	now := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+
+	// the only good backups are older than store period
+	backups := []*Backup{
+		createTestRetentionBackup(now.Add(-24*time.Hour), BackupStatusFailed),
+		createTestRetentionBackup(now.Add(-30*24*time.Hour), BackupStatusCompleted),
+		createTestRetentionBackup(now.Add(-31*24*time.Hour), BackupStatusCompleted),
+		createTestRetentionBackup(now.Add(-32*24*time.Hour), BackupStatusCompleted),
+	}
+
+	backupsToDelete := getBackupsToDelete(backups, &backups_config.BackupConfig{
+		RetentionPolicyType: backups_config.RetentionPolicyStorePeriod,
+		StorePeriod:         period.PeriodWeek,
+		MinKeepCount:        2,
+	}, now)
+
+	assert.Len(t, backupsToDelete, 1)
+	assert.Equal(t, backups[3].ID, backupsToDelete[0].ID)
+}
+
+func Test_GetBackupsToDelete_WhenStorePeriodPolicy_UnfinishedBackupsNotDeleted(t *testing.T) {
+	now := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+
+	// queued backup may wait for long behind a busy host
+	backups := []*Backup{
+		createTestRetentionBackup(now, BackupStatusCompleted),
+		createTestRetentionBackup(now.Add(-8*24*time.Hour), BackupStatusQueued),
+		createTestRetentionBackup(now.Add(-9*24*time.Hour), BackupStatusInProgress),
+		createTestRetentionBackup(now.Add(-10*24*time.Hour), BackupStatusFailed),
+		createTestRetentionBackup(now.Add(-11*24*time.Hour), BackupStatusCanceled),
+		createTestRetentionBackup(now.Add(-12*24*time.Hour), BackupStatusCompleted),
+	}
+
+	backupsToDelete := getBackupsToDelete(backups, &backups_config.BackupConfig{
+		RetentionPolicyType: backups_config.RetentionPolicyStorePeriod,
+		StorePeriod:         period.PeriodWeek,
+		MinKeepCount:        1,
+	}, now)
+
+	assert.Equal(t, backups[3:], backupsToDelete)
+}
+
+func Test_GetBackupsToDelete_WhenBackupIsPinned_BackupNotDeleted(t *testing.T) {
+	now := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+
+	pinnedBackup := createTestRetentionBackup(now.Add(-90*24*time.Hour), BackupStatusCompleted)
+	pinnedBackup.IsPinned = true
+
+	backups := []*Backup{
+		createTestRetentionBackup(now, BackupStatusCompleted),
+		createTestRetentionBackup(now.Add(-30*24*time.Hour), BackupStatusCompleted),
+		pinnedBackup,
+	}
+
+	backupsToDelete := getBackupsToDelete(backups, &backups_config.BackupConfig{
+		RetentionPolicyType: backups_config.RetentionPolicyStorePeriod,
+		StorePeriod:         period.PeriodWeek,
+		MinKeepCount:        1,
+	}, now)
+
+	assert.Len(t, backupsToDelete, 1)
+	assert.Equal(t, backups[1].ID, backupsToDelete[0].ID)
+}
+
+func createTestRetentionBackup(createdAt time.Time, status BackupStatus) *Backup {
+	return &Backup{
+		ID:        uuid.New(),
+		Status:    status,
+		CreatedAt: createdAt,
+	}
+}
+
+func getKeptBackups(backups []*Backup, backupsToDelete []*Backup) []*Backup {
+	deletedIDs := map[uuid.UUID]bool{}
+	for _, backup := range backupsToDelete {
+		deletedIDs[backup.ID] = true
+	}
+
+	keptBackups := []*Backup{}
+	for _, backup := range backups {
+		if !deletedIDs[backup.ID] {
+			keptBackups = append(keptBackups, backup)
+		}
+	}
+
+	return keptBackups
+}
@@ -0,0 +1,64 @@
+package backups
+
+import (
+	"math"
+	"time"
+
+	backups_config "postgresus-backend/internal/features/backups/config"
+)
+
+// getRetryDelay returns delay before the retry after failedTriesCount failed
+// tries in a row. Random is in [0, 1) and shifts the delay by up to the
+// jitter percent in both directions
+func getRetryDelay(
+	backupConfig *backups_config.BackupConfig,
+	failedTriesCount int,
+	random float64,
+) time.Duration {
+	if backupConfig.RetryDelaySeconds <= 0 || failedTriesCount <= 0 {
+		return 0
+	}
+
+	delaySeconds := float64(backupConfig.RetryDelaySeconds) *
+		math.Pow(2, float64(failedTriesCount-1))
+
+	if backupConfig.RetryMaxDelaySeconds > 0 {
+		delaySeconds = math.Min(delaySeconds, float64(backupConfig.RetryMaxDelaySeconds))
+	}
+
+	jitter := float64(backupConfig.RetryJitterPercent) / 100 * (2*random - 1)
+	delaySeconds *= 1 + jitter
+
+	return time.Duration(delaySeconds * float64(time.Second))
+}
+
+// getFailedTriesCount returns count of failed tries in a row ending with
+// the failed backup, which is not saved as failed yet. Last backups are
+// sorted from newest to oldest, successful backup ends the row
+func getFailedTriesCount(lastBackups []*Backup, failedBackup *Backup) int {
+	failedTriesCount := 1
+
+	for _, lastBackup := range lastBackups {
+		if lastBackup.ID == failedBackup.ID {
+			continue
+		}
+
+		if lastBackup.Status != BackupStatusFailed {
+			break
+		}
+
+		failedTriesCount++
+	}
+
+	return failedTriesCount
+}
+
+// isRetryDue checks the failed backup may be retried already
+func isRetryDue(lastBackup *Backup, now time.Time) bool {
+	return lastBackup.NextRetryAt == nil || !now.Before(*lastBackup.NextRetryAt)
+}
+
+// isCopyRetryDue checks the failed copy may be retried already
+func isCopyRetryDue(backupCopy *BackupCopy, now time.Time) bool {
+	return backupCopy.NextRetryAt == nil || !now.Before(*backupCopy.NextRetryAt)
+}
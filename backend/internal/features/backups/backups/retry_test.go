@@ -0,0 +1,65 @@
+package backups
+
+import (
+	"testing"
+	"time"
+
+	backups_config "postgresus-backend/internal/features/backups/config"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_GetRetryDelay_FailedTriesInRow_DelayDoubledUpToMaximum(t *testing.T) {
+	backupConfig := &backups_config.BackupConfig{
+		RetryDelaySeconds:    60,
+		RetryMaxDelaySeconds: 300,
+	}
+
+	assert.Equal(t, 60*time.Second, getRetryDelay(backupConfig, 1, 0.5))
+	assert.Equal(t, 120*time.Second, getRetryDelay(backupConfig, 2, 0.5))
+	assert.Equal(t, 240*time.Second, getRetryDelay(backupConfig, 3, 0.5))
+	assert.Equal(t, 300*time.Second, getRetryDelay(backupConfig, 4, 0.5))
+	assert.Equal(t, 300*time.Second, getRetryDelay(backupConfig, 20, 0.5))
+}
+
+func Test_GetRetryDelay_JitterConfigured_DelayShiftedWithinJitter(t *testing.T) {
+	backupConfig := &backups_config.BackupConfig{
+		RetryDelaySeconds:  100,
+		RetryJitterPercent: 20,
+	}
+
+	assert.Equal(t, 80*time.Second, getRetryDelay(backupConfig, 1, 0))
+	assert.Equal(t, 100*time.Second, getRetryDelay(backupConfig, 1, 0.5))
+	assert.InDelta(t, float64(120*time.Second), float64(getRetryDelay(backupConfig, 1, 0.999999)), float64(time.Second))
+}
+
+func Test_GetRetryDelay_DelayNotConfigured_RetriedImmediately(t *testing.T) {
+	assert.Equal(t, time.Duration(0), getRetryDelay(&backups_config.BackupConfig{}, 3, 0.5))
+}
+
+func Test_GetFailedTriesCount_SuccessfulBackupBetweenFailures_OnlyFailuresInRowCounted(t *testing.T) {
+	now := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+	failedBackup := createTestRetentionBackup(now, BackupStatusInProgress)
+
+	lastBackups := []*Backup{
+		failedBackup,
+		createTestRetentionBackup(now.Add(-1*time.Hour), BackupStatusFailed),
+		createTestRetentionBackup(now.Add(-2*time.Hour), BackupStatusCompleted),
+		createTestRetentionBackup(now.Add(-3*time.Hour), BackupStatusFailed),
+		createTestRetentionBackup(now.Add(-4*time.Hour), BackupStatusFailed),
+	}
+
+	assert.Equal(t, 2, getFailedTriesCount(lastBackups, failedBackup))
+	assert.Equal(t, 1, getFailedTriesCount(lastBackups[:1], failedBackup))
+}
+
+func Test_IsRetryDue_NextRetryTimeCompared(t *testing.T) {
+	now := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+	future := now.Add(time.Minute)
+	past := now.Add(-time.Minute)
+
+	assert.True(t, isRetryDue(&Backup{}, now))
+	assert.True(t, isRetryDue(&Backup{NextRetryAt: &past}, now))
+	assert.True(t, isRetryDue(&Backup{NextRetryAt: &now}, now))
+	assert.False(t, isRetryDue(&Backup{NextRetryAt: &future}, now))
+}
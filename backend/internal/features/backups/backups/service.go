@@ -7,12 +7,16 @@ import (
 	"fmt"
 	"io"
 	"log/slog"
+	"math/rand/v2"
 	"slices"
 	"strings"
 	"time"
 
+	"postgresus-backend/internal/config"
 	audit_logs "postgresus-backend/internal/features/audit_logs"
 	"postgresus-backend/internal/features/backups/backups/encryption"
+	usecases_postgresql "postgresus-backend/internal/features/backups/backups/usecases/postgresql"
+	backups_blackouts "postgresus-backend/internal/features/backups/blackouts"
 	backups_config "postgresus-backend/internal/features/backups/config"
 	"postgresus-backend/internal/features/databases"
 	encryption_secrets "postgresus-backend/internal/features/encryption/secrets"
@@ -20,7 +24,9 @@ import (
 	"postgresus-backend/internal/features/storages"
 	users_models "postgresus-backend/internal/features/users/models"
 	workspaces_services "postgresus-backend/internal/features/workspaces/services"
+	"postgresus-backend/internal/util/checksum"
 	util_encryption "postgresus-backend/internal/util/encryption"
+	"postgresus-backend/internal/util/tools"
 
 	"github.com/google/uuid"
 )
@@ -44,14 +50,18 @@ type BackupService struct {
 	workspaceService     *workspaces_services.WorkspaceService
 	auditLogService      *audit_logs.AuditLogService
 	backupContextManager *BackupContextManager
+
+	blackoutWindowService *backups_blackouts.BlackoutWindowService
 }
 
 func (s *BackupService) AddBackupRemoveListener(listener BackupRemoveListener) {
 	s.backupRemoveListeners = append(s.backupRemoveListeners, listener)
 }
 
+// OnBeforeBackupsStorageChange removes backups of the old storage. Pinned
+// backups are kept there, they still can be downloaded and restored
 func (s *BackupService) OnBeforeBackupsStorageChange(databaseID uuid.UUID) error {
-	err := s.deleteDbBackups(databaseID)
+	err := s.deleteDbBackups(databaseID, true)
 	if err != nil {
 		return err
 	}
@@ -60,7 +70,7 @@ func (s *BackupService) OnBeforeBackupsStorageChange(databaseID uuid.UUID) error
 }
 
 func (s *BackupService) OnBeforeDatabaseRemove(databaseID uuid.UUID) error {
-	err := s.deleteDbBackups(databaseID)
+	err := s.deleteDbBackups(databaseID, false)
 	if err != nil {
 		return err
 	}
@@ -68,28 +78,47 @@ func (s *BackupService) OnBeforeDatabaseRemove(databaseID uuid.UUID) error {
 	return nil
 }
 
+// MakeBackupWithAuth starts manual backup. Blackout windows do not block
+// manual backups, the response only warns about the active one
 func (s *BackupService) MakeBackupWithAuth(
 	user *users_models.User,
 	databaseID uuid.UUID,
-) error {
+) (*MakeBackupResponse, error) {
 	database, err := s.databaseService.GetDatabaseByID(databaseID)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	if database.WorkspaceID == nil {
-		return errors.New("cannot create backup for database without workspace")
+		return nil, errors.New("cannot create backup for database without workspace")
 	}
 
 	canAccess, _, err := s.workspaceService.CanUserAccessWorkspace(*database.WorkspaceID, user)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	if !canAccess {
-		return errors.New("insufficient permissions to create backup for this database")
+		return nil, errors.New("insufficient permissions to create backup for this database")
+	}
+
+	response := &MakeBackupResponse{Message: "backup started successfully"}
+
+	blackoutEnd, err := s.blackoutWindowService.GetActiveBlackoutEnd(databaseID, time.Now().UTC())
+	if err != nil {
+		s.logger.Error("Failed to check blackout windows", "databaseId", databaseID, "error", err)
+	} else if blackoutEnd != nil {
+		warning := fmt.Sprintf(
+			"backup is started inside of blackout window, which ends at %s UTC",
+			blackoutEnd.Format(time.DateTime),
+		)
+		response.Warning = &warning
 	}
 
-	go s.MakeBackup(databaseID, true)
+	if err := s.queueBackup(databaseID); err != nil {
+		return nil, err
+	}
+
+	go s.StartQueuedBackups()
 
 	s.auditLogService.WriteAuditLog(
 		fmt.Sprintf("Backup manually initiated for database: %s", database.Name),
@@ -97,7 +126,7 @@ func (s *BackupService) MakeBackupWithAuth(
 		database.WorkspaceID,
 	)
 
-	return nil
+	return response, nil
 }
 
 func (s *BackupService) GetBackups(
@@ -134,6 +163,14 @@ func (s *BackupService) GetBackups(
 		return nil, err
 	}
 
+	if err := s.fillQueuePositions(backups); err != nil {
+		return nil, err
+	}
+
+	for _, backup := range backups {
+		fillBackupProgress(backup)
+	}
+
 	total, err := s.backupRepository.CountByDatabaseID(databaseID)
 	if err != nil {
 		return nil, err
@@ -177,6 +214,18 @@ func (s *BackupService) DeleteBackup(
 		return errors.New("backup is in progress")
 	}
 
+	if backup.Status == BackupStatusQueued {
+		return errors.New("backup is queued, cancel it before deletion")
+	}
+
+	if backup.IsPinned {
+		return errors.New("backup is pinned, unpin it before deletion")
+	}
+
+	if isBackupCopying(backup) {
+		return errors.New("backup is being copied to another storage")
+	}
+
 	s.auditLogService.WriteAuditLog(
 		fmt.Sprintf(
 			"Backup deleted for database: %s (ID: %s)",
@@ -190,61 +239,105 @@ func (s *BackupService) DeleteBackup(
 	return s.deleteBackup(backup)
 }
 
-func (s *BackupService) MakeBackup(databaseID uuid.UUID, isLastTry bool) {
-	database, err := s.databaseService.GetDatabaseByID(databaseID)
-	if err != nil {
-		s.logger.Error("Failed to get database by ID", "error", err)
-		return
+func (s *BackupService) PinBackup(
+	user *users_models.User,
+	backupID uuid.UUID,
+	reason string,
+) error {
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		return errors.New("pin reason is required")
 	}
 
-	lastBackup, err := s.backupRepository.FindLastByDatabaseID(databaseID)
+	backup, database, err := s.getManageableBackup(user, backupID)
 	if err != nil {
-		s.logger.Error("Failed to find last backup by database ID", "error", err)
-		return
+		return err
 	}
 
-	if lastBackup != nil && lastBackup.Status == BackupStatusInProgress {
-		s.logger.Error("Backup is in progress")
-		return
+	if backup.Status != BackupStatusCompleted {
+		return errors.New("only completed backups can be pinned")
 	}
 
-	backupConfig, err := s.backupConfigService.GetBackupConfigByDbId(databaseID)
-	if err != nil {
-		s.logger.Error("Failed to get backup config by database ID", "error", err)
-		return
+	pinnedAt := time.Now().UTC()
+	backup.IsPinned = true
+	backup.PinReason = &reason
+	backup.PinnedByUserID = &user.ID
+	backup.PinnedAt = &pinnedAt
+
+	if err := s.backupRepository.Save(backup); err != nil {
+		return err
 	}
 
-	if !backupConfig.IsBackupsEnabled {
-		s.logger.Info("Backups are not enabled for this database")
-		return
+	s.auditLogService.WriteAuditLog(
+		fmt.Sprintf(
+			"Backup pinned for database: %s (ID: %s), reason: %s",
+			database.Name,
+			backupID.String(),
+			reason,
+		),
+		&user.ID,
+		database.WorkspaceID,
+	)
+
+	return nil
+}
+
+func (s *BackupService) UnpinBackup(
+	user *users_models.User,
+	backupID uuid.UUID,
+) error {
+	backup, database, err := s.getManageableBackup(user, backupID)
+	if err != nil {
+		return err
 	}
 
-	if backupConfig.StorageID == nil {
-		s.logger.Error("Backup config storage ID is not defined")
-		return
+	if !backup.IsPinned {
+		return errors.New("backup is not pinned")
 	}
 
-	storage, err := s.storageService.GetStorageByID(*backupConfig.StorageID)
-	if err != nil {
-		s.logger.Error("Failed to get storage by ID", "error", err)
-		return
+	backup.IsPinned = false
+	backup.PinReason = nil
+	backup.PinnedByUserID = nil
+	backup.PinnedAt = nil
+
+	if err := s.backupRepository.Save(backup); err != nil {
+		return err
 	}
 
-	backup := &Backup{
-		DatabaseID: databaseID,
-		StorageID:  storage.ID,
+	s.auditLogService.WriteAuditLog(
+		fmt.Sprintf(
+			"Backup unpinned for database: %s (ID: %s)",
+			database.Name,
+			backupID.String(),
+		),
+		&user.ID,
+		database.WorkspaceID,
+	)
 
-		Status: BackupStatusInProgress,
+	return nil
+}
 
-		BackupSizeMb: 0,
+// runBackup executes backup, which is already saved with IN_PROGRESS status
+func (s *BackupService) runBackup(
+	backup *Backup,
+	backupConfig *backups_config.BackupConfig,
+	database *databases.Database,
+	storage *storages.Storage,
+	isLastTry bool,
+) {
+	databaseID := database.ID
 
-		CreatedAt: time.Now().UTC(),
+	// to find databases which appeared since the previous backup
+	previousBackup, err := s.backupRepository.FindLastByDatabaseIdAndStatus(
+		databaseID,
+		BackupStatusCompleted,
+	)
+	if err != nil {
+		// only notification about new databases depends on it
+		s.logger.Error("Failed to find previous completed backup", "error", err)
 	}
 
-	if err := s.backupRepository.Save(backup); err != nil {
-		s.logger.Error("Failed to save backup", "error", err)
-		return
-	}
+	s.estimateBackupSize(backup, database, previousBackup)
 
 	start := time.Now().UTC()
 
@@ -263,14 +356,52 @@ func (s *BackupService) MakeBackup(databaseID uuid.UUID, isLastTry bool) {
 	s.backupContextManager.RegisterBackup(backup.ID, cancel)
 	defer s.backupContextManager.UnregisterBackup(backup.ID)
 
-	backupMetadata, err := s.createBackupUseCase.Execute(
+	var backupMetadata *usecases_postgresql.BackupMetadata
+
+	err = s.runBackupHooks(
 		ctx,
-		backup.ID,
 		backupConfig,
 		database,
-		storage,
-		backupProgressListener,
+		backup,
+		backups_config.BackupHookStagePre,
+		BackupStatusInProgress,
+	)
+	if err == nil {
+		backupMetadata, err = s.createBackupUseCase.Execute(
+			ctx,
+			backup.ID,
+			backupConfig,
+			database,
+			storage,
+			backupProgressListener,
+		)
+	}
+
+	// post hooks usually undo pre hooks, so they are run even
+	// when the backup is failed or cancelled
+	resultStatus := BackupStatusCompleted
+	if err != nil {
+		resultStatus = BackupStatusFailed
+		if ctx.Err() != nil {
+			resultStatus = BackupStatusCanceled
+		}
+	}
+
+	postHooksErr := s.runBackupHooks(
+		context.Background(),
+		backupConfig,
+		database,
+		backup,
+		backups_config.BackupHookStagePost,
+		resultStatus,
 	)
+	if err == nil && postHooksErr != nil {
+		// the backup is uploaded already, but it is failed by the
+		// hook, so its files would not be deleted by anything
+		s.deleteFiles(storage, getUploadedFileIDs(backup, backupMetadata))
+		err = postHooksErr
+	}
+
 	if err != nil {
 		errMsg := err.Error()
 
@@ -311,6 +442,8 @@ func (s *BackupService) MakeBackup(databaseID uuid.UUID, isLastTry bool) {
 		backup.BackupDurationMs = time.Since(start).Milliseconds()
 		backup.BackupSizeMb = 0
 
+		s.planBackupRetry(backupConfig, backup)
+
 		if updateErr := s.databaseService.SetBackupError(databaseID, errMsg); updateErr != nil {
 			s.logger.Error(
 				"Failed to update database last backup time",
@@ -338,11 +471,23 @@ func (s *BackupService) MakeBackup(databaseID uuid.UUID, isLastTry bool) {
 	backup.Status = BackupStatusCompleted
 	backup.BackupDurationMs = time.Since(start).Milliseconds()
 
+	s.checkBackupAnomaly(backupConfig, backup)
+
 	// Update backup with encryption metadata if provided
 	if backupMetadata != nil {
 		backup.EncryptionSalt = backupMetadata.EncryptionSalt
 		backup.EncryptionIV = backupMetadata.EncryptionIV
 		backup.Encryption = backupMetadata.Encryption
+		backup.Checksum = backupMetadata.Checksum
+
+		if backupMetadata.SourceHost != "" {
+			backup.SourceHost = &backupMetadata.SourceHost
+		}
+
+		backup.GlobalsFileID = backupMetadata.GlobalsFileID
+		backup.GlobalsEncryptionSalt = backupMetadata.GlobalsEncryptionSalt
+		backup.GlobalsEncryptionIV = backupMetadata.GlobalsEncryptionIV
+		backup.GlobalsChecksum = backupMetadata.GlobalsChecksum
 	}
 
 	if err := s.backupRepository.Save(backup); err != nil {
@@ -350,6 +495,15 @@ func (s *BackupService) MakeBackup(databaseID uuid.UUID, isLastTry bool) {
 		return
 	}
 
+	if backupMetadata != nil && len(backupMetadata.Artifacts) > 0 {
+		if err := s.saveArtifacts(backup, backupMetadata.Artifacts); err != nil {
+			s.logger.Error("Failed to save backup artifacts", "error", err)
+			return
+		}
+
+		s.notifyAboutNewDatabases(backupConfig, backup, previousBackup)
+	}
+
 	// Update database last backup time
 	now := time.Now().UTC()
 	if updateErr := s.databaseService.SetLastBackupTime(databaseID, now); updateErr != nil {
@@ -372,6 +526,15 @@ func (s *BackupService) MakeBackup(databaseID uuid.UUID, isLastTry bool) {
 		backups_config.NotificationBackupSuccess,
 		nil,
 	)
+
+	if backup.IsAnomaly {
+		s.SendBackupNotification(
+			backupConfig,
+			backup,
+			backups_config.NotificationBackupAnomaly,
+			backup.AnomalyMessage,
+		)
+	}
 }
 
 func (s *BackupService) SendBackupNotification(
@@ -412,6 +575,18 @@ func (s *BackupService) SendBackupNotification(
 				database.Name,
 				workspace.Name,
 			)
+		case backups_config.NotificationBackupAnomaly:
+			title = fmt.Sprintf(
+				"⚠️ Backup anomaly for database \"%s\" (workspace \"%s\")",
+				database.Name,
+				workspace.Name,
+			)
+		case backups_config.NotificationNewDatabaseFound:
+			title = fmt.Sprintf(
+				"🆕 New database found on the server of \"%s\" (workspace \"%s\")",
+				database.Name,
+				workspace.Name,
+			)
 		}
 
 		message := ""
@@ -452,6 +627,27 @@ func (s *BackupService) GetBackup(backupID uuid.UUID) (*Backup, error) {
 	return s.backupRepository.FindByID(backupID)
 }
 
+func (s *BackupService) GetLastCompletedBackup(databaseID uuid.UUID) (*Backup, error) {
+	return s.backupRepository.FindLastByDatabaseIdAndStatus(databaseID, BackupStatusCompleted)
+}
+
+func (s *BackupService) GetOldestCompletedBackup(databaseID uuid.UUID) (*Backup, error) {
+	backups, err := s.backupRepository.FindByDatabaseIdAndStatus(
+		databaseID,
+		BackupStatusCompleted,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(backups) == 0 {
+		return nil, nil
+	}
+
+	// backups are sorted by created_at DESC
+	return backups[len(backups)-1], nil
+}
+
 func (s *BackupService) CancelBackup(
 	user *users_models.User,
 	backupID uuid.UUID,
@@ -478,12 +674,17 @@ func (s *BackupService) CancelBackup(
 		return errors.New("insufficient permissions to cancel backup for this database")
 	}
 
-	if backup.Status != BackupStatusInProgress {
-		return errors.New("backup is not in progress")
-	}
-
-	if err := s.backupContextManager.CancelBackup(backupID); err != nil {
-		return err
+	switch backup.Status {
+	case BackupStatusQueued:
+		if err := s.cancelQueuedBackup(backupID); err != nil {
+			return err
+		}
+	case BackupStatusInProgress:
+		if err := s.backupContextManager.CancelBackup(backupID); err != nil {
+			return err
+		}
+	default:
+		return errors.New("backup is not queued or in progress")
 	}
 
 	s.auditLogService.WriteAuditLog(
@@ -502,19 +703,20 @@ func (s *BackupService) CancelBackup(
 func (s *BackupService) GetBackupFile(
 	user *users_models.User,
 	backupID uuid.UUID,
-) (io.ReadCloser, error) {
+	artifactID *uuid.UUID,
+) (io.ReadCloser, *Backup, error) {
 	backup, err := s.backupRepository.FindByID(backupID)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	database, err := s.databaseService.GetDatabaseByID(backup.DatabaseID)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	if database.WorkspaceID == nil {
-		return nil, errors.New("cannot download backup for database without workspace")
+		return nil, nil, errors.New("cannot download backup for database without workspace")
 	}
 
 	canAccess, _, err := s.workspaceService.CanUserAccessWorkspace(
@@ -522,10 +724,10 @@ func (s *BackupService) GetBackupFile(
 		user,
 	)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	if !canAccess {
-		return nil, errors.New("insufficient permissions to download backup for this database")
+		return nil, nil, errors.New("insufficient permissions to download backup for this database")
 	}
 
 	s.auditLogService.WriteAuditLog(
@@ -538,7 +740,268 @@ func (s *BackupService) GetBackupFile(
 		database.WorkspaceID,
 	)
 
-	return s.getBackupReader(backupID)
+	fileReader, err := s.getBackupReader(backupID, artifactID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return fileReader, backup, nil
+}
+
+// GetBackupContents lists objects of pg_dump archive without restoring it
+func (s *BackupService) GetBackupContents(
+	user *users_models.User,
+	backupID uuid.UUID,
+	artifactID *uuid.UUID,
+) (*BackupContents, error) {
+	backup, err := s.backupRepository.FindByID(backupID)
+	if err != nil {
+		return nil, err
+	}
+
+	database, err := s.databaseService.GetDatabaseByID(backup.DatabaseID)
+	if err != nil {
+		return nil, err
+	}
+
+	if database.WorkspaceID == nil {
+		return nil, errors.New("cannot browse backup for database without workspace")
+	}
+
+	canAccess, _, err := s.workspaceService.CanUserAccessWorkspace(
+		*database.WorkspaceID,
+		user,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if !canAccess {
+		return nil, errors.New("insufficient permissions to browse backup for this database")
+	}
+
+	if backup.Status != BackupStatusCompleted {
+		return nil, errors.New("backup is not completed")
+	}
+
+	if backup.Method == backups_config.BackupMethodPgBasebackup {
+		return nil, errors.New("physical base backup has no table of contents")
+	}
+
+	if backup.Format == backups_config.BackupFormatPlain {
+		return nil, errors.New("plain SQL backup has no table of contents")
+	}
+
+	if database.Postgresql == nil {
+		return nil, errors.New("database type not supported")
+	}
+
+	fileReader, err := s.getBackupReader(backupID, artifactID)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := fileReader.Close(); err != nil {
+			s.logger.Error("Failed to close backup reader", "error", err)
+		}
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), listBackupContentsTimeout)
+	defer cancel()
+
+	pgRestoreBin := tools.GetPostgresqlExecutable(
+		database.Postgresql.Version,
+		"pg_restore",
+		config.GetEnv().EnvMode,
+		config.GetEnv().PostgresesInstallDir,
+	)
+
+	var list string
+	dataSizes := map[int]int64{}
+
+	if backup.Format == backups_config.BackupFormatDirectory {
+		list, dataSizes, err = listDirectoryBackupContents(ctx, pgRestoreBin, fileReader)
+	} else {
+		list, err = listCustomBackupContents(ctx, pgRestoreBin, fileReader)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	contents := ParseBackupContents(list, dataSizes)
+	contents.BackupID = backup.ID
+	contents.ArtifactID = artifactID
+
+	return contents, nil
+}
+
+func (s *BackupService) saveArtifacts(
+	backup *Backup,
+	artifactsMetadata []*usecases_postgresql.ArtifactMetadata,
+) error {
+	backup.Artifacts = make([]*BackupArtifact, 0, len(artifactsMetadata))
+
+	for _, artifactMetadata := range artifactsMetadata {
+		artifact := &BackupArtifact{
+			ID:             artifactMetadata.FileID,
+			BackupID:       backup.ID,
+			DatabaseName:   artifactMetadata.DatabaseName,
+			SizeMb:         artifactMetadata.SizeMb,
+			EncryptionSalt: artifactMetadata.EncryptionSalt,
+			EncryptionIV:   artifactMetadata.EncryptionIV,
+			Checksum:       artifactMetadata.Checksum,
+			CreatedAt:      time.Now().UTC(),
+		}
+
+		if err := s.backupRepository.SaveArtifact(artifact); err != nil {
+			return err
+		}
+
+		backup.Artifacts = append(backup.Artifacts, artifact)
+	}
+
+	return nil
+}
+
+// planBackupRetry sets time of the retry of failed backup. Each failed try
+// in a row doubles the delay, so retries do not hammer unavailable database
+func (s *BackupService) planBackupRetry(
+	backupConfig *backups_config.BackupConfig,
+	backup *Backup,
+) {
+	if !backupConfig.IsRetryIfFailed || backupConfig.RetryDelaySeconds <= 0 {
+		return
+	}
+
+	lastBackups, err := s.backupRepository.FindByDatabaseIDWithLimit(
+		backup.DatabaseID,
+		backupConfig.MaxFailedTriesCount,
+	)
+	if err != nil {
+		s.logger.Error("Failed to find last backups", "error", err)
+		return
+	}
+
+	failedTriesCount := getFailedTriesCount(lastBackups, backup)
+
+	if failedTriesCount >= backupConfig.MaxFailedTriesCount {
+		return
+	}
+
+	nextRetryAt := time.Now().UTC().Add(
+		getRetryDelay(backupConfig, failedTriesCount, rand.Float64()),
+	)
+	backup.NextRetryAt = &nextRetryAt
+}
+
+// estimateBackupSize saves expected size of the backup, so progress
+// can be shown. Without estimation only completed MBs are shown
+func (s *BackupService) estimateBackupSize(
+	backup *Backup,
+	database *databases.Database,
+	previousBackup *Backup,
+) {
+	if database.Postgresql == nil {
+		return
+	}
+
+	// physical backup copies the whole cluster
+	isWholeServer := backup.IsWholeServer ||
+		backup.Method == backups_config.BackupMethodPgBasebackup
+
+	databaseSizeBytes, err := database.Postgresql.GetDatabaseSizeBytes(
+		s.logger,
+		s.fieldEncryptor,
+		database.ID,
+		isWholeServer,
+	)
+	if err != nil {
+		s.logger.Warn("Failed to get database size", "databaseId", database.ID, "error", err)
+	}
+
+	backup.DatabaseSizeMb = float64(databaseSizeBytes) / (1024 * 1024)
+	backup.EstimatedSizeMb = estimateBackupSizeMb(backup, backup.DatabaseSizeMb, previousBackup)
+
+	if err := s.backupRepository.Save(backup); err != nil {
+		s.logger.Error("Failed to save backup size estimation", "error", err)
+	}
+}
+
+// checkBackupAnomaly flags completed backup if its size or duration
+// differs much from the recent completed backups of the database
+func (s *BackupService) checkBackupAnomaly(
+	backupConfig *backups_config.BackupConfig,
+	backup *Backup,
+) {
+	if backupConfig.SizeAnomalyThresholdPercent == 0 &&
+		backupConfig.DurationAnomalyThresholdPercent == 0 {
+		return
+	}
+
+	recentBackups, err := s.backupRepository.FindByDatabaseIdAndStatusWithLimit(
+		backup.DatabaseID,
+		BackupStatusCompleted,
+		anomalyBaselineSize,
+	)
+	if err != nil {
+		s.logger.Error("Failed to find recent backups", "error", err)
+		return
+	}
+
+	anomalyMessage := detectBackupAnomaly(backup, recentBackups, backupConfig)
+	if anomalyMessage == nil {
+		return
+	}
+
+	s.logger.Warn(
+		"Backup anomaly detected",
+		"backupId",
+		backup.ID,
+		"message",
+		*anomalyMessage,
+	)
+
+	backup.IsAnomaly = true
+	backup.AnomalyMessage = anomalyMessage
+}
+
+// notifyAboutNewDatabases compares databases of whole-server backup with the
+// previous one. The first whole-server backup has nothing to compare with
+func (s *BackupService) notifyAboutNewDatabases(
+	backupConfig *backups_config.BackupConfig,
+	backup *Backup,
+	previousBackup *Backup,
+) {
+	if previousBackup == nil || !previousBackup.IsWholeServer {
+		return
+	}
+
+	previousDatabaseNames := make([]string, 0, len(previousBackup.Artifacts))
+	for _, artifact := range previousBackup.Artifacts {
+		previousDatabaseNames = append(previousDatabaseNames, artifact.DatabaseName)
+	}
+
+	newDatabaseNames := make([]string, 0)
+	for _, artifact := range backup.Artifacts {
+		if !slices.Contains(previousDatabaseNames, artifact.DatabaseName) {
+			newDatabaseNames = append(newDatabaseNames, artifact.DatabaseName)
+		}
+	}
+
+	if len(newDatabaseNames) == 0 {
+		return
+	}
+
+	message := fmt.Sprintf(
+		"New databases found on the server and included into the backup: %s",
+		strings.Join(newDatabaseNames, ", "),
+	)
+
+	s.SendBackupNotification(
+		backupConfig,
+		backup,
+		backups_config.NotificationNewDatabaseFound,
+		&message,
+	)
 }
 
 func (s *BackupService) deleteBackup(backup *Backup) error {
@@ -548,23 +1011,76 @@ func (s *BackupService) deleteBackup(backup *Backup) error {
 		}
 	}
 
-	storage, err := s.storageService.GetStorageByID(backup.StorageID)
+	artifacts, err := s.backupRepository.FindArtifactsByBackupID(backup.ID)
 	if err != nil {
 		return err
 	}
+	backup.Artifacts = artifacts
+
+	// we do not stop on file errors, because sometimes clean up performed
+	// before unavailable storage removal or change - therefore we should
+	// proceed even in case of error
+	if !backup.IsRemovedFromStorage {
+		storage, err := s.storageService.GetStorageByID(backup.StorageID)
+		if err != nil {
+			return err
+		}
+
+		s.deleteFiles(storage, getBackupFileIDs(backup))
+	}
 
-	err = storage.DeleteFile(s.fieldEncryptor, backup.ID)
+	backupCopies, err := s.backupRepository.FindCopiesByBackupID(backup.ID)
 	if err != nil {
-		// we do not return error here, because sometimes clean up performed
-		// before unavailable storage removal or change - therefore we should
-		// proceed even in case of error
-		s.logger.Error("Failed to delete backup file", "error", err)
+		return err
+	}
+
+	for _, backupCopy := range backupCopies {
+		if backupCopy.Status != BackupCopyStatusCompleted {
+			continue
+		}
+
+		storage, err := s.storageService.GetStorageByID(backupCopy.StorageID)
+		if err != nil {
+			s.logger.Error("Failed to get storage of backup copy", "error", err)
+			continue
+		}
+
+		s.deleteFiles(storage, getBackupFileIDs(backup))
 	}
 
 	return s.backupRepository.DeleteByID(backup.ID)
 }
 
-func (s *BackupService) deleteDbBackups(databaseID uuid.UUID) error {
+func (s *BackupService) getManageableBackup(
+	user *users_models.User,
+	backupID uuid.UUID,
+) (*Backup, *databases.Database, error) {
+	backup, err := s.backupRepository.FindByID(backupID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	database, err := s.databaseService.GetDatabaseByID(backup.DatabaseID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if database.WorkspaceID == nil {
+		return nil, nil, errors.New("cannot manage backup for database without workspace")
+	}
+
+	canManage, err := s.workspaceService.CanUserManageDBs(*database.WorkspaceID, user)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !canManage {
+		return nil, nil, errors.New("insufficient permissions to manage backup for this database")
+	}
+
+	return backup, database, nil
+}
+
+func (s *BackupService) deleteDbBackups(databaseID uuid.UUID, isKeepPinned bool) error {
 	dbBackupsInProgress, err := s.backupRepository.FindByDatabaseIdAndStatus(
 		databaseID,
 		BackupStatusInProgress,
@@ -585,6 +1101,10 @@ func (s *BackupService) deleteDbBackups(databaseID uuid.UUID) error {
 	}
 
 	for _, dbBackup := range dbBackups {
+		if isKeepPinned && dbBackup.IsPinned {
+			continue
+		}
+
 		err := s.deleteBackup(dbBackup)
 		if err != nil {
 			return err
@@ -594,39 +1114,88 @@ func (s *BackupService) deleteDbBackups(databaseID uuid.UUID) error {
 	return nil
 }
 
-// GetBackupReader returns a reader for the backup file
-// If encrypted, wraps with DecryptionReader
-func (s *BackupService) getBackupReader(backupID uuid.UUID) (io.ReadCloser, error) {
+// getBackupReader returns a reader for the backup file or for the
+// artifact file of whole-server backup
+func (s *BackupService) getBackupReader(
+	backupID uuid.UUID,
+	artifactID *uuid.UUID,
+) (io.ReadCloser, error) {
 	backup, err := s.backupRepository.FindByID(backupID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to find backup: %w", err)
 	}
 
-	storage, err := s.storageService.GetStorageByID(backup.StorageID)
+	storage, err := s.GetBackupStorage(backup, nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get storage: %w", err)
 	}
 
-	fileReader, err := storage.GetFile(s.fieldEncryptor, backup.ID)
+	if !backup.IsWholeServer {
+		return s.getFileReader(
+			storage,
+			backup.ID,
+			backup.Encryption,
+			backup.EncryptionSalt,
+			backup.EncryptionIV,
+			backup.Checksum,
+		)
+	}
+
+	if artifactID == nil {
+		return nil, errors.New("whole-server backup contains several databases, artifact ID is required")
+	}
+
+	for _, artifact := range backup.Artifacts {
+		if artifact.ID == *artifactID {
+			return s.getFileReader(
+				storage,
+				artifact.ID,
+				backup.Encryption,
+				artifact.EncryptionSalt,
+				artifact.EncryptionIV,
+				artifact.Checksum,
+			)
+		}
+	}
+
+	return nil, errors.New("artifact not found in the backup")
+}
+
+// getFileReader returns a reader for the file in the storage
+// If encrypted, wraps with DecryptionReader
+func (s *BackupService) getFileReader(
+	storage *storages.Storage,
+	fileID uuid.UUID,
+	fileEncryption backups_config.BackupEncryption,
+	encryptionSalt *string,
+	encryptionIV *string,
+	fileChecksum *string,
+) (io.ReadCloser, error) {
+	fileReader, err := storage.GetFile(s.fieldEncryptor, fileID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get backup file: %w", err)
 	}
 
+	// backups made before checksums were introduced have no checksum
+	if fileChecksum != nil {
+		fileReader = checksum.NewVerifyingReader(fileReader, *fileChecksum)
+	}
+
 	// If not encrypted, return raw reader
-	if backup.Encryption == backups_config.BackupEncryptionNone {
-		s.logger.Info("Returning non-encrypted backup", "backupId", backupID)
+	if fileEncryption == backups_config.BackupEncryptionNone {
+		s.logger.Info("Returning non-encrypted backup", "fileId", fileID)
 		return fileReader, nil
 	}
 
 	// Decrypt on-the-fly for encrypted backups
-	if backup.Encryption != backups_config.BackupEncryptionEncrypted {
+	if fileEncryption != backups_config.BackupEncryptionEncrypted {
 		if err := fileReader.Close(); err != nil {
 			s.logger.Error("Failed to close file reader", "error", err)
 		}
-		return nil, fmt.Errorf("unsupported encryption type: %s", backup.Encryption)
+		return nil, fmt.Errorf("unsupported encryption type: %s", fileEncryption)
 	}
 
-	if backup.EncryptionSalt == nil || backup.EncryptionIV == nil {
+	if encryptionSalt == nil || encryptionIV == nil {
 		if err := fileReader.Close(); err != nil {
 			s.logger.Error("Failed to close file reader", "error", err)
 		}
@@ -643,7 +1212,7 @@ func (s *BackupService) getBackupReader(backupID uuid.UUID) (io.ReadCloser, erro
 	}
 
 	// Decode salt and IV
-	salt, err := base64.StdEncoding.DecodeString(*backup.EncryptionSalt)
+	salt, err := base64.StdEncoding.DecodeString(*encryptionSalt)
 	if err != nil {
 		if closeErr := fileReader.Close(); closeErr != nil {
 			s.logger.Error("Failed to close file reader", "error", closeErr)
@@ -651,7 +1220,7 @@ func (s *BackupService) getBackupReader(backupID uuid.UUID) (io.ReadCloser, erro
 		return nil, fmt.Errorf("failed to decode salt: %w", err)
 	}
 
-	iv, err := base64.StdEncoding.DecodeString(*backup.EncryptionIV)
+	iv, err := base64.StdEncoding.DecodeString(*encryptionIV)
 	if err != nil {
 		if closeErr := fileReader.Close(); closeErr != nil {
 			s.logger.Error("Failed to close file reader", "error", closeErr)
@@ -663,7 +1232,7 @@ func (s *BackupService) getBackupReader(backupID uuid.UUID) (io.ReadCloser, erro
 	decryptionReader, err := encryption.NewDecryptionReader(
 		fileReader,
 		masterKey,
-		backup.ID,
+		fileID,
 		salt,
 		iv,
 	)
@@ -674,7 +1243,7 @@ func (s *BackupService) getBackupReader(backupID uuid.UUID) (io.ReadCloser, erro
 		return nil, fmt.Errorf("failed to create decrypting reader: %w", err)
 	}
 
-	s.logger.Info("Returning encrypted backup with decryption", "backupId", backupID)
+	s.logger.Info("Returning encrypted backup with decryption", "fileId", fileID)
 
 	return &decryptionReaderCloser{
 		decryptionReader,
@@ -65,6 +65,7 @@ func Test_BackupExecuted_NotificationSent(t *testing.T) {
 			workspaces_services.GetWorkspaceService(),
 			nil,
 			NewBackupContextManager(),
+			nil,
 		}
 
 		// Set up expectations
@@ -78,7 +79,7 @@ func Test_BackupExecuted_NotificationSent(t *testing.T) {
 			}),
 		).Once()
 
-		backupService.MakeBackup(database.ID, true)
+		runTestBackup(t, backupService, database.ID)
 
 		// Verify all expectations were met
 		mockNotificationSender.AssertExpectations(t)
@@ -113,9 +114,10 @@ func Test_BackupExecuted_NotificationSent(t *testing.T) {
 			workspaces_services.GetWorkspaceService(),
 			nil,
 			NewBackupContextManager(),
+			nil,
 		}
 
-		backupService.MakeBackup(database.ID, true)
+		runTestBackup(t, backupService, database.ID)
 
 		// Verify all expectations were met
 		mockNotificationSender.AssertExpectations(t)
@@ -138,6 +140,7 @@ func Test_BackupExecuted_NotificationSent(t *testing.T) {
 			workspaces_services.GetWorkspaceService(),
 			nil,
 			NewBackupContextManager(),
+			nil,
 		}
 
 		// capture arguments
@@ -155,7 +158,7 @@ func Test_BackupExecuted_NotificationSent(t *testing.T) {
 			capturedMessage = args.Get(2).(string)
 		}).Once()
 
-		backupService.MakeBackup(database.ID, true)
+		runTestBackup(t, backupService, database.ID)
 
 		// Verify expectations were met
 		mockNotificationSender.AssertExpectations(t)
@@ -169,6 +172,26 @@ func Test_BackupExecuted_NotificationSent(t *testing.T) {
 	})
 }
 
+// runTestBackup runs backup of the database right away, so the
+// result is checked without waiting for the queue
+func runTestBackup(t *testing.T, backupService *BackupService, databaseID uuid.UUID) {
+	database, err := backupService.databaseService.GetDatabaseByID(databaseID)
+	assert.NoError(t, err)
+
+	backupConfig, storage, err := backupService.getBackupConfigAndStorage(databaseID)
+	assert.NoError(t, err)
+
+	backup := &Backup{
+		DatabaseID: databaseID,
+		Status:     BackupStatusInProgress,
+		CreatedAt:  time.Now().UTC(),
+	}
+	applyBackupConfig(backup, backupConfig, storage)
+	assert.NoError(t, backupService.backupRepository.Save(backup))
+
+	backupService.runBackup(backup, backupConfig, database, storage, true)
+}
+
 type CreateFailedBackupUsecase struct {
 }
 
@@ -1,6 +1,7 @@
 package usecases_postgresql
 
 import (
+	"bytes"
 	"context"
 	"encoding/base64"
 	"errors"
@@ -21,7 +22,9 @@ import (
 	pgtypes "postgresus-backend/internal/features/databases/databases/postgresql"
 	encryption_secrets "postgresus-backend/internal/features/encryption/secrets"
 	"postgresus-backend/internal/features/storages"
+	"postgresus-backend/internal/util/checksum"
 	"postgresus-backend/internal/util/encryption"
+	files_utils "postgresus-backend/internal/util/files"
 	"postgresus-backend/internal/util/tools"
 
 	"github.com/google/uuid"
@@ -33,7 +36,6 @@ const (
 	copyBufferSize           = 8 * 1024 * 1024
 	progressReportIntervalMB = 1.0
 	pgConnectTimeout         = 30
-	compressionLevel         = 5
 	exitCodeAccessViolation  = -1073741819
 	exitCodeGenericError     = 1
 	exitCodeConnectionError  = 2
@@ -61,53 +63,458 @@ func (uc *CreatePostgresqlBackupUsecase) Execute(
 		completedMBs float64,
 	),
 ) (*BackupMetadata, error) {
+	if !backupConfig.IsBackupsEnabled {
+		return nil, fmt.Errorf("backups are not enabled for this database: \"%s\"", db.Name)
+	}
+
+	if db.Postgresql == nil {
+		return nil, fmt.Errorf("postgresql database configuration is required for pg_dump backups")
+	}
+
+	// dumping from a standby does not load the primary
+	pg, err := db.Postgresql.SelectBackupSource(ctx, uc.logger, uc.fieldEncryptor, db.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	sourceDb := *db
+	sourceDb.Postgresql = pg
+	db = &sourceDb
+
+	decryptedPassword, err := uc.fieldEncryptor.Decrypt(db.ID, pg.Password)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt database password: %w", err)
+	}
+
+	if backupConfig.BackupMethod == backups_config.BackupMethodPgBasebackup {
+		uc.logger.Info(
+			"Creating PostgreSQL backup via pg_basebackup tar format",
+			"databaseId",
+			db.ID,
+			"storageId",
+			storage.ID,
+		)
+
+		backupMetadata, err := uc.streamToStorage(
+			ctx,
+			backupID,
+			backupConfig,
+			tools.GetPostgresqlExecutable(
+				pg.Version,
+				"pg_basebackup",
+				config.GetEnv().EnvMode,
+				config.GetEnv().PostgresesInstallDir,
+			),
+			uc.buildPgBasebackupArgs(pg, backupConfig),
+			decryptedPassword,
+			storage,
+			db,
+			backupProgressListener,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		backupMetadata.SourceHost = pg.GetAddress()
+		return backupMetadata, nil
+	}
+
 	uc.logger.Info(
-		"Creating PostgreSQL backup via pg_dump custom format",
+		"Creating PostgreSQL backup via pg_dump",
 		"databaseId",
 		db.ID,
 		"storageId",
 		storage.ID,
+		"format",
+		backupConfig.Format,
 	)
 
-	if !backupConfig.IsBackupsEnabled {
-		return nil, fmt.Errorf("backups are not enabled for this database: \"%s\"", db.Name)
+	if pg.Database == nil || *pg.Database == "" {
+		return nil, fmt.Errorf("database name is required for pg_dump backups")
 	}
 
-	pg := db.Postgresql
+	// version may be changed after the config is saved
+	if err := backupConfig.ValidateCompressionForVersion(pg.Version); err != nil {
+		return nil, err
+	}
 
-	if pg == nil {
-		return nil, fmt.Errorf("postgresql database configuration is required for pg_dump backups")
+	// exclusions may be added to the database after the config is saved
+	if backupConfig.IsWholeServerBackup && pg.HasExclusions() {
+		return nil, backups_config.ErrWholeServerBackupExclusions
 	}
 
-	if pg.Database == nil || *pg.Database == "" {
-		return nil, fmt.Errorf("database name is required for pg_dump backups")
+	var globalsFileID *uuid.UUID
+	var globalsMetadata *BackupMetadata
+
+	// globals are small, so they are saved first to fail fast
+	// on missing permissions before the long dump
+	if backupConfig.IsBackupGlobals {
+		fileID := uuid.New()
+
+		globalsMetadata, err = uc.streamToStorage(
+			ctx,
+			fileID,
+			backupConfig,
+			tools.GetPostgresqlExecutable(
+				pg.Version,
+				"pg_dumpall",
+				config.GetEnv().EnvMode,
+				config.GetEnv().PostgresesInstallDir,
+			),
+			uc.buildPgDumpallGlobalsArgs(pg),
+			decryptedPassword,
+			storage,
+			db,
+			nil,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to back up globals: %w", err)
+		}
+
+		globalsFileID = &fileID
 	}
 
-	args := uc.buildPgDumpArgs(pg)
+	var backupMetadata *BackupMetadata
+	if backupConfig.IsWholeServerBackup {
+		backupMetadata, err = uc.backupWholeServer(
+			ctx,
+			backupConfig,
+			db,
+			decryptedPassword,
+			storage,
+			backupProgressListener,
+		)
+	} else {
+		backupMetadata, err = uc.dumpDatabaseToStorage(
+			ctx,
+			backupID,
+			backupConfig,
+			decryptedPassword,
+			storage,
+			db,
+			backupProgressListener,
+		)
+	}
+	if err != nil {
+		if globalsFileID != nil {
+			if deleteErr := storage.DeleteFile(uc.fieldEncryptor, *globalsFileID); deleteErr != nil {
+				uc.logger.Error("Failed to delete globals file", "error", deleteErr)
+			}
+		}
 
-	decryptedPassword, err := uc.fieldEncryptor.Decrypt(db.ID, pg.Password)
+		return nil, err
+	}
+
+	if globalsFileID != nil {
+		backupMetadata.GlobalsFileID = globalsFileID
+		backupMetadata.GlobalsEncryptionSalt = globalsMetadata.EncryptionSalt
+		backupMetadata.GlobalsEncryptionIV = globalsMetadata.EncryptionIV
+		backupMetadata.GlobalsChecksum = globalsMetadata.Checksum
+	}
+
+	backupMetadata.SourceHost = pg.GetAddress()
+	return backupMetadata, nil
+}
+
+// backupWholeServer dumps every included database of the server to
+// its own file. If any dump fails, already saved files are removed
+func (uc *CreatePostgresqlBackupUsecase) backupWholeServer(
+	ctx context.Context,
+	backupConfig *backups_config.BackupConfig,
+	db *databases.Database,
+	password string,
+	storage *storages.Storage,
+	backupProgressListener func(completedMBs float64),
+) (*BackupMetadata, error) {
+	serverDatabaseNames, err := db.Postgresql.GetServerDatabaseNames(
+		uc.logger,
+		uc.fieldEncryptor,
+		db.ID,
+	)
 	if err != nil {
-		return nil, fmt.Errorf("failed to decrypt database password: %w", err)
+		return nil, err
+	}
+
+	databaseNames := make([]string, 0, len(serverDatabaseNames))
+	for _, databaseName := range serverDatabaseNames {
+		if backupConfig.IsDatabaseIncluded(databaseName) {
+			databaseNames = append(databaseNames, databaseName)
+		}
+	}
+
+	if len(databaseNames) == 0 {
+		return nil, errors.New("no databases on the server match include and exclude patterns")
+	}
+
+	uc.logger.Info(
+		"Creating whole-server backup",
+		"databaseId",
+		db.ID,
+		"databases",
+		databaseNames,
+	)
+
+	backupMetadata := &BackupMetadata{
+		Encryption: backups_config.BackupEncryptionNone,
+		Artifacts:  make([]*ArtifactMetadata, 0, len(databaseNames)),
+	}
+
+	var completedMBs float64
+	for _, databaseName := range databaseNames {
+		artifact := &ArtifactMetadata{
+			FileID:       uuid.New(),
+			DatabaseName: databaseName,
+		}
+
+		serverDatabase := *db.Postgresql
+		serverDatabase.Database = &databaseName
+		// schemas are configured for the single database only
+		serverDatabase.IncludeSchemas = nil
+
+		artifactDb := *db
+		artifactDb.Postgresql = &serverDatabase
+
+		artifactMetadata, err := uc.dumpDatabaseToStorage(
+			ctx,
+			artifact.FileID,
+			backupConfig,
+			password,
+			storage,
+			&artifactDb,
+			func(artifactMBs float64) {
+				artifact.SizeMb = artifactMBs
+
+				if backupProgressListener != nil {
+					backupProgressListener(completedMBs + artifactMBs)
+				}
+			},
+		)
+		if err != nil {
+			uc.deleteArtifacts(storage, backupMetadata.Artifacts)
+			return nil, fmt.Errorf("failed to back up database \"%s\": %w", databaseName, err)
+		}
+
+		artifact.EncryptionSalt = artifactMetadata.EncryptionSalt
+		artifact.EncryptionIV = artifactMetadata.EncryptionIV
+		artifact.Checksum = artifactMetadata.Checksum
+		backupMetadata.Encryption = artifactMetadata.Encryption
+		backupMetadata.Artifacts = append(backupMetadata.Artifacts, artifact)
+
+		completedMBs += artifact.SizeMb
+	}
+
+	return backupMetadata, nil
+}
+
+func (uc *CreatePostgresqlBackupUsecase) deleteArtifacts(
+	storage *storages.Storage,
+	artifacts []*ArtifactMetadata,
+) {
+	for _, artifact := range artifacts {
+		if err := storage.DeleteFile(uc.fieldEncryptor, artifact.FileID); err != nil {
+			uc.logger.Error(
+				"Failed to delete artifact file",
+				"fileId",
+				artifact.FileID,
+				"error",
+				err,
+			)
+		}
+	}
+}
+
+// dumpDatabaseToStorage dumps the database via pg_dump in the configured format
+func (uc *CreatePostgresqlBackupUsecase) dumpDatabaseToStorage(
+	ctx context.Context,
+	fileID uuid.UUID,
+	backupConfig *backups_config.BackupConfig,
+	password string,
+	storage *storages.Storage,
+	db *databases.Database,
+	backupProgressListener func(completedMBs float64),
+) (*BackupMetadata, error) {
+	pgBin := tools.GetPostgresqlExecutable(
+		db.Postgresql.Version,
+		"pg_dump",
+		config.GetEnv().EnvMode,
+		config.GetEnv().PostgresesInstallDir,
+	)
+	args := uc.buildPgDumpArgs(db.Postgresql, backupConfig)
+
+	if backupConfig.Format == backups_config.BackupFormatDirectory {
+		return uc.dumpDirectoryToStorage(
+			ctx,
+			fileID,
+			backupConfig,
+			pgBin,
+			args,
+			password,
+			storage,
+			db,
+			backupProgressListener,
+		)
 	}
 
 	return uc.streamToStorage(
 		ctx,
-		backupID,
+		fileID,
 		backupConfig,
-		tools.GetPostgresqlExecutable(
-			pg.Version,
-			"pg_dump",
-			config.GetEnv().EnvMode,
-			config.GetEnv().PostgresesInstallDir,
-		),
+		pgBin,
 		args,
-		decryptedPassword,
+		password,
 		storage,
 		db,
 		backupProgressListener,
 	)
 }
 
+// dumpDirectoryToStorage runs pg_dump into the temp folder, because directory
+// format cannot be written to stdout, and streams the directory as tar to storage
+func (uc *CreatePostgresqlBackupUsecase) dumpDirectoryToStorage(
+	parentCtx context.Context,
+	fileID uuid.UUID,
+	backupConfig *backups_config.BackupConfig,
+	pgBin string,
+	args []string,
+	password string,
+	storage *storages.Storage,
+	db *databases.Database,
+	backupProgressListener func(completedMBs float64),
+) (*BackupMetadata, error) {
+	ctx, cancel := uc.createBackupContext(parentCtx)
+	defer cancel()
+
+	if err := files_utils.EnsureDirectories([]string{config.GetEnv().TempFolder}); err != nil {
+		return nil, fmt.Errorf("failed to ensure directories: %w", err)
+	}
+
+	tempDir, err := os.MkdirTemp(config.GetEnv().TempFolder, "backup_"+fileID.String())
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temporary directory: %w", err)
+	}
+	defer func() {
+		_ = os.RemoveAll(tempDir)
+	}()
+
+	// pg_dump creates the directory itself
+	dumpDir := filepath.Join(tempDir, "dump")
+	args = append(args, "-f", dumpDir)
+
+	pgpassFile, err := uc.setupPgpassFile(db.Postgresql, password)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if pgpassFile != "" {
+			_ = os.Remove(pgpassFile)
+		}
+	}()
+
+	cmd := exec.CommandContext(ctx, pgBin, args...)
+	uc.logger.Info("Executing PostgreSQL directory backup command", "command", cmd.String())
+
+	if err := uc.setupPgEnvironment(cmd, pgpassFile, db.Postgresql.IsHttps, password, backupConfig.CpuCount, pgBin); err != nil {
+		return nil, err
+	}
+
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		if err := uc.checkCancellation(ctx); err != nil {
+			return nil, err
+		}
+		return nil, uc.buildPgDumpErrorMessage(err, stderr.Bytes(), pgBin, args, password)
+	}
+
+	tarReader, tarWriter := io.Pipe()
+	go func() {
+		_ = tarWriter.CloseWithError(files_utils.WriteDirectoryToTar(tarWriter, dumpDir))
+	}()
+	defer func() {
+		_ = tarReader.Close()
+	}()
+
+	return uc.streamReaderToStorage(
+		ctx,
+		fileID,
+		backupConfig,
+		tarReader,
+		storage,
+		backupProgressListener,
+	)
+}
+
+// streamReaderToStorage streams already produced backup data
+// through the encryption writer to storage
+func (uc *CreatePostgresqlBackupUsecase) streamReaderToStorage(
+	ctx context.Context,
+	fileID uuid.UUID,
+	backupConfig *backups_config.BackupConfig,
+	reader io.Reader,
+	storage *storages.Storage,
+	backupProgressListener func(completedMBs float64),
+) (*BackupMetadata, error) {
+	storageReader, pipeWriter := io.Pipe()
+	// checksum of the stored bytes, so corruption in the storage is detected
+	storageWriter := checksum.NewWriter(pipeWriter)
+
+	finalWriter, encryptionWriter, backupMetadata, err := uc.setupBackupEncryption(
+		fileID,
+		backupConfig,
+		storageWriter,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	countingWriter := &CountingWriter{writer: finalWriter}
+
+	saveErrCh := make(chan error, 1)
+	go func() {
+		saveErr := storage.SaveFile(ctx, uc.fieldEncryptor, uc.logger, fileID, storageReader)
+		saveErrCh <- saveErr
+	}()
+
+	bytesWritten, copyErr := uc.copyWithShutdownCheck(
+		ctx,
+		countingWriter,
+		reader,
+		backupProgressListener,
+	)
+
+	select {
+	case <-ctx.Done():
+		uc.cleanupOnCancellation(encryptionWriter, storageWriter, saveErrCh)
+		return nil, uc.checkCancellationReason()
+	default:
+	}
+
+	if err := uc.closeWriters(encryptionWriter, storageWriter); err != nil {
+		<-saveErrCh
+		return nil, err
+	}
+
+	saveErr := <-saveErrCh
+
+	if copyErr == nil && saveErr == nil && backupProgressListener != nil {
+		sizeMB := float64(bytesWritten) / (1024 * 1024)
+		backupProgressListener(sizeMB)
+	}
+
+	switch {
+	case copyErr != nil:
+		return nil, fmt.Errorf("copy to storage: %w", copyErr)
+	case saveErr != nil:
+		return nil, fmt.Errorf("save to storage: %w", saveErr)
+	}
+
+	fileChecksum := storageWriter.GetChecksum()
+	backupMetadata.Checksum = &fileChecksum
+
+	return &backupMetadata, nil
+}
+
 // streamToStorage streams pg_dump output directly to storage
 func (uc *CreatePostgresqlBackupUsecase) streamToStorage(
 	parentCtx context.Context,
@@ -159,7 +566,9 @@ func (uc *CreatePostgresqlBackupUsecase) streamToStorage(
 		stderrCh <- stderrOutput
 	}()
 
-	storageReader, storageWriter := io.Pipe()
+	storageReader, pipeWriter := io.Pipe()
+	// checksum of the stored bytes, so corruption in the storage is detected
+	storageWriter := checksum.NewWriter(pipeWriter)
 
 	finalWriter, encryptionWriter, backupMetadata, err := uc.setupBackupEncryption(
 		backupID,
@@ -243,6 +652,9 @@ func (uc *CreatePostgresqlBackupUsecase) streamToStorage(
 		return nil, fmt.Errorf("save to storage: %w", saveErr)
 	}
 
+	fileChecksum := storageWriter.GetChecksum()
+	backupMetadata.Checksum = &fileChecksum
+
 	return &backupMetadata, nil
 }
 
@@ -323,44 +735,125 @@ func (uc *CreatePostgresqlBackupUsecase) copyWithShutdownCheck(
 	return totalBytesWritten, nil
 }
 
-func (uc *CreatePostgresqlBackupUsecase) buildPgDumpArgs(pg *pgtypes.PostgresqlDatabase) []string {
-	args := []string{
-		"-Fc",
+func (uc *CreatePostgresqlBackupUsecase) buildPgDumpArgs(
+	pg *pgtypes.PostgresqlDatabase,
+	backupConfig *backups_config.BackupConfig,
+) []string {
+	args := uc.getFormatArgs(backupConfig)
+	args = append(args,
 		"--no-password",
 		"-h", pg.Host,
 		"-p", strconv.Itoa(pg.Port),
 		"-U", pg.Username,
 		"-d", *pg.Database,
 		"--verbose",
-	}
+	)
 
 	for _, schema := range pg.IncludeSchemas {
 		args = append(args, "-n", schema)
 	}
 
-	compressionArgs := uc.getCompressionArgs(pg.Version)
+	for _, schema := range pg.ExcludeSchemas {
+		args = append(args, "-N", schema)
+	}
+
+	for _, table := range pg.ExcludeTables {
+		args = append(args, "-T", table)
+	}
+
+	for _, table := range pg.ExcludeTableData {
+		args = append(args, "--exclude-table-data", table)
+	}
+
+	compressionArgs := uc.getCompressionArgs(backupConfig)
 	return append(args, compressionArgs...)
 }
 
-func (uc *CreatePostgresqlBackupUsecase) getCompressionArgs(
-	version tools.PostgresqlVersion,
+func (uc *CreatePostgresqlBackupUsecase) buildPgDumpallGlobalsArgs(
+	pg *pgtypes.PostgresqlDatabase,
 ) []string {
-	if uc.isOlderPostgresVersion(version) {
-		uc.logger.Info("Using gzip compression level 5 (zstd not available)", "version", version)
-		return []string{"-Z", strconv.Itoa(compressionLevel)}
+	return []string{
+		"--globals-only",
+		"--no-password",
+		"-h", pg.Host,
+		"-p", strconv.Itoa(pg.Port),
+		"-U", pg.Username,
+		// connect to the backed up database, "postgres" may be not accessible
+		"-l", *pg.Database,
+		"--verbose",
 	}
+}
 
-	uc.logger.Info("Using zstd compression level 5", "version", version)
-	return []string{fmt.Sprintf("--compress=zstd:%d", compressionLevel)}
+// buildPgBasebackupArgs builds args to write the whole cluster as a single
+// tar to stdout. Tablespaces are not supported, because each of them
+// is written to a separate tar file
+func (uc *CreatePostgresqlBackupUsecase) buildPgBasebackupArgs(
+	pg *pgtypes.PostgresqlDatabase,
+	backupConfig *backups_config.BackupConfig,
+) []string {
+	args := []string{
+		"-D", "-",
+		"-Ft",
+		"--checkpoint=fast",
+		"--no-password",
+		"-h", pg.Host,
+		"-p", strconv.Itoa(pg.Port),
+		"-U", pg.Username,
+		"--verbose",
+	}
+
+	// with WAL archiving WAL is already in the storage, otherwise
+	// it should be included to make the backup consistent by itself.
+	// Streaming WAL is not possible when tar is written to stdout
+	if backupConfig.IsWalArchivingEnabled {
+		args = append(args, "-X", "none")
+	} else {
+		args = append(args, "-X", "fetch")
+	}
+
+	return args
+}
+
+func (uc *CreatePostgresqlBackupUsecase) getFormatArgs(
+	backupConfig *backups_config.BackupConfig,
+) []string {
+	switch backupConfig.Format {
+	case backups_config.BackupFormatDirectory:
+		return []string{"-Fd", "-j", strconv.Itoa(max(1, backupConfig.CpuCount))}
+	case backups_config.BackupFormatPlain:
+		// psql cannot skip owners and privileges like pg_restore
+		// does, so they are left out of the dump itself
+		return []string{"-Fp", "--clean", "--if-exists", "--no-owner", "--no-acl"}
+	default:
+		return []string{"-Fc"}
+	}
 }
 
-func (uc *CreatePostgresqlBackupUsecase) isOlderPostgresVersion(
-	version tools.PostgresqlVersion,
-) bool {
-	return version == tools.PostgresqlVersion12 ||
-		version == tools.PostgresqlVersion13 ||
-		version == tools.PostgresqlVersion14 ||
-		version == tools.PostgresqlVersion15
+// getCompressionArgs builds pg_dump compression args. Plain -Z is used
+// for gzip and none, because older pg_dump does not support method names
+func (uc *CreatePostgresqlBackupUsecase) getCompressionArgs(
+	backupConfig *backups_config.BackupConfig,
+) []string {
+	compression := backupConfig.GetAppliedCompression()
+
+	uc.logger.Info(
+		"Using compression",
+		"compression",
+		compression,
+		"level",
+		backupConfig.CompressionLevel,
+	)
+
+	switch compression {
+	case backups_config.BackupCompressionNone:
+		return []string{"-Z", "0"}
+	case backups_config.BackupCompressionGzip:
+		return []string{"-Z", strconv.Itoa(backupConfig.CompressionLevel)}
+	case backups_config.BackupCompressionLz4:
+		return []string{fmt.Sprintf("--compress=lz4:%d", backupConfig.CompressionLevel)}
+	default:
+		return []string{fmt.Sprintf("--compress=zstd:%d", backupConfig.CompressionLevel)}
+	}
 }
 
 func (uc *CreatePostgresqlBackupUsecase) createBackupContext(
@@ -395,7 +888,7 @@ func (uc *CreatePostgresqlBackupUsecase) setupPgpassFile(
 	pgConfig *pgtypes.PostgresqlDatabase,
 	password string,
 ) (string, error) {
-	pgpassFile, err := uc.createTempPgpassFile(pgConfig, password)
+	pgpassFile, err := tools.CreateTempPgpassFile(pgConfig.Host, pgConfig.Port, pgConfig.Username, password)
 	if err != nil {
 		return "", fmt.Errorf("failed to create temporary .pgpass file: %w", err)
 	}
@@ -734,39 +1227,6 @@ func (uc *CreatePostgresqlBackupUsecase) handleConnectionErrors(
 	return fmt.Errorf("PostgreSQL connection or authentication error. stderr: %s", stderrStr)
 }
 
-func (uc *CreatePostgresqlBackupUsecase) createTempPgpassFile(
-	pgConfig *pgtypes.PostgresqlDatabase,
-	password string,
-) (string, error) {
-	if pgConfig == nil || password == "" {
-		return "", nil
-	}
-
-	escapedHost := tools.EscapePgpassField(pgConfig.Host)
-	escapedUsername := tools.EscapePgpassField(pgConfig.Username)
-	escapedPassword := tools.EscapePgpassField(password)
-
-	pgpassContent := fmt.Sprintf("%s:%d:*:%s:%s",
-		escapedHost,
-		pgConfig.Port,
-		escapedUsername,
-		escapedPassword,
-	)
-
-	tempDir, err := os.MkdirTemp("", "pgpass")
-	if err != nil {
-		return "", fmt.Errorf("failed to create temporary directory: %w", err)
-	}
-
-	pgpassFile := filepath.Join(tempDir, ".pgpass")
-	err = os.WriteFile(pgpassFile, []byte(pgpassContent), 0600)
-	if err != nil {
-		return "", fmt.Errorf("failed to write temporary .pgpass file: %w", err)
-	}
-
-	return pgpassFile, nil
-}
-
 func containsIgnoreCase(str, substr string) bool {
 	return strings.Contains(strings.ToLower(str), strings.ToLower(substr))
 }
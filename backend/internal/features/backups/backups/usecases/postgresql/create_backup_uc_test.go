@@ -0,0 +1,31 @@
+package usecases_postgresql
+
+import (
+	"testing"
+
+	pgtypes "postgresus-backend/internal/features/databases/databases/postgresql"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_BuildPgDumpallGlobalsArgs_GlobalsDumpedViaBackedUpDatabase(t *testing.T) {
+	database := "app"
+	uc := &CreatePostgresqlBackupUsecase{}
+
+	args := uc.buildPgDumpallGlobalsArgs(&pgtypes.PostgresqlDatabase{
+		Host:     "db.local",
+		Port:     5433,
+		Username: "backup",
+		Database: &database,
+	})
+
+	assert.Equal(t, []string{
+		"--globals-only",
+		"--no-password",
+		"-h", "db.local",
+		"-p", "5433",
+		"-U", "backup",
+		"-l", "app",
+		"--verbose",
+	}, args)
+}
@@ -1,6 +1,10 @@
 package usecases_postgresql
 
-import backups_config "postgresus-backend/internal/features/backups/config"
+import (
+	backups_config "postgresus-backend/internal/features/backups/config"
+
+	"github.com/google/uuid"
+)
 
 type EncryptionMetadata struct {
 	Salt       string
@@ -12,4 +16,24 @@ type BackupMetadata struct {
 	EncryptionSalt *string
 	EncryptionIV   *string
 	Encryption     backups_config.BackupEncryption
+	Checksum       *string
+
+	GlobalsFileID         *uuid.UUID
+	GlobalsEncryptionSalt *string
+	GlobalsEncryptionIV   *string
+	GlobalsChecksum       *string
+
+	Artifacts []*ArtifactMetadata
+
+	// host:port of the primary or of the replica the backup is taken from
+	SourceHost string
+}
+
+type ArtifactMetadata struct {
+	FileID         uuid.UUID
+	DatabaseName   string
+	SizeMb         float64
+	EncryptionSalt *string
+	EncryptionIV   *string
+	Checksum       *string
 }
@@ -0,0 +1,177 @@
+package backups_blackouts
+
+import (
+	"net/http"
+
+	users_middleware "postgresus-backend/internal/features/users/middleware"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type BlackoutWindowController struct {
+	blackoutWindowService *BlackoutWindowService
+}
+
+func (c *BlackoutWindowController) RegisterRoutes(router *gin.RouterGroup) {
+	router.POST("/backup-blackouts/database/:id", c.SaveDatabaseWindows)
+	router.GET("/backup-blackouts/database/:id", c.GetDatabaseWindows)
+	router.POST("/backup-blackouts/workspace/:id", c.SaveWorkspaceWindows)
+	router.GET("/backup-blackouts/workspace/:id", c.GetWorkspaceWindows)
+}
+
+// SaveDatabaseWindows
+// @Summary Save blackout windows of the database
+// @Description Replace blackout windows of the database. Scheduled and retry backups are not started during them. Empty list means windows of the workspace are used
+// @Tags backup-blackouts
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "JWT token"
+// @Param id path string true "Database ID"
+// @Param request body SaveBlackoutWindowsRequest true "Blackout windows"
+// @Success 200 {array} BlackoutWindow
+// @Failure 400
+// @Failure 401
+// @Router /backup-blackouts/database/{id} [post]
+func (c *BlackoutWindowController) SaveDatabaseWindows(ctx *gin.Context) {
+	user, ok := users_middleware.GetUserFromContext(ctx)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	databaseID, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid database ID"})
+		return
+	}
+
+	var request SaveBlackoutWindowsRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	windows, err := c.blackoutWindowService.SaveDatabaseWindowsWithAuth(
+		user,
+		databaseID,
+		request.Windows,
+	)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, windows)
+}
+
+// GetDatabaseWindows
+// @Summary Get blackout windows of the database
+// @Description Get own blackout windows of the database
+// @Tags backup-blackouts
+// @Produce json
+// @Param Authorization header string true "JWT token"
+// @Param id path string true "Database ID"
+// @Success 200 {array} BlackoutWindow
+// @Failure 400
+// @Failure 401
+// @Router /backup-blackouts/database/{id} [get]
+func (c *BlackoutWindowController) GetDatabaseWindows(ctx *gin.Context) {
+	user, ok := users_middleware.GetUserFromContext(ctx)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	databaseID, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid database ID"})
+		return
+	}
+
+	windows, err := c.blackoutWindowService.GetDatabaseWindowsWithAuth(user, databaseID)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, windows)
+}
+
+// SaveWorkspaceWindows
+// @Summary Save default blackout windows of the workspace
+// @Description Replace blackout windows applied to databases of the workspace without own windows
+// @Tags backup-blackouts
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "JWT token"
+// @Param id path string true "Workspace ID"
+// @Param request body SaveBlackoutWindowsRequest true "Blackout windows"
+// @Success 200 {array} BlackoutWindow
+// @Failure 400
+// @Failure 401
+// @Router /backup-blackouts/workspace/{id} [post]
+func (c *BlackoutWindowController) SaveWorkspaceWindows(ctx *gin.Context) {
+	user, ok := users_middleware.GetUserFromContext(ctx)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	workspaceID, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid workspace ID"})
+		return
+	}
+
+	var request SaveBlackoutWindowsRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	windows, err := c.blackoutWindowService.SaveWorkspaceWindowsWithAuth(
+		user,
+		workspaceID,
+		request.Windows,
+	)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, windows)
+}
+
+// GetWorkspaceWindows
+// @Summary Get default blackout windows of the workspace
+// @Description Get blackout windows applied to databases of the workspace without own windows
+// @Tags backup-blackouts
+// @Produce json
+// @Param Authorization header string true "JWT token"
+// @Param id path string true "Workspace ID"
+// @Success 200 {array} BlackoutWindow
+// @Failure 400
+// @Failure 401
+// @Router /backup-blackouts/workspace/{id} [get]
+func (c *BlackoutWindowController) GetWorkspaceWindows(ctx *gin.Context) {
+	user, ok := users_middleware.GetUserFromContext(ctx)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	workspaceID, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid workspace ID"})
+		return
+	}
+
+	windows, err := c.blackoutWindowService.GetWorkspaceWindowsWithAuth(user, workspaceID)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, windows)
+}
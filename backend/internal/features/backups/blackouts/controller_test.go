@@ -0,0 +1,140 @@
+package backups_blackouts
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	"postgresus-backend/internal/features/databases"
+	"postgresus-backend/internal/features/notifiers"
+	users_enums "postgresus-backend/internal/features/users/enums"
+	users_testing "postgresus-backend/internal/features/users/testing"
+	workspaces_controllers "postgresus-backend/internal/features/workspaces/controllers"
+	workspaces_testing "postgresus-backend/internal/features/workspaces/testing"
+	test_utils "postgresus-backend/internal/util/testing"
+)
+
+func createTestRouter() *gin.Engine {
+	return workspaces_testing.CreateTestRouter(
+		workspaces_controllers.GetWorkspaceController(),
+		workspaces_controllers.GetMembershipController(),
+		GetBlackoutWindowController(),
+	)
+}
+
+func Test_SaveDatabaseWindows_WindowsReplacedAndReturned(t *testing.T) {
+	router := createTestRouter()
+	owner := users_testing.CreateTestUser(users_enums.UserRoleMember)
+	workspace := workspaces_testing.CreateTestWorkspace("Test Workspace", owner, router)
+	notifier := notifiers.CreateTestNotifier(workspace.ID)
+	database := databases.CreateTestDatabase(workspace.ID, nil, notifier)
+
+	defer func() {
+		databases.RemoveTestDatabase(database)
+		notifiers.RemoveTestNotifier(notifier)
+		workspaces_testing.RemoveTestWorkspace(workspace, router)
+	}()
+
+	url := "/api/v1/backup-blackouts/database/" + database.ID.String()
+
+	test_utils.MakePostRequest(t, router, url, "Bearer "+owner.Token, SaveBlackoutWindowsRequest{
+		Windows: []*BlackoutWindow{{StartTime: "12:00", EndTime: "13:00"}},
+	}, http.StatusOK)
+
+	var savedWindows []*BlackoutWindow
+	test_utils.MakePostRequestAndUnmarshal(t, router, url, "Bearer "+owner.Token, SaveBlackoutWindowsRequest{
+		Windows: []*BlackoutWindow{
+			{StartTime: "01:00", EndTime: "03:00", Weekdays: []int{1, 2}, Timezone: "Europe/Berlin"},
+		},
+	}, http.StatusOK, &savedWindows)
+
+	var windows []*BlackoutWindow
+	test_utils.MakeGetRequestAndUnmarshal(t, router, url, "Bearer "+owner.Token, http.StatusOK, &windows)
+
+	assert.Len(t, windows, 1)
+	assert.Equal(t, "01:00", windows[0].StartTime)
+	assert.Equal(t, []int{1, 2}, windows[0].Weekdays)
+	assert.Equal(t, "Europe/Berlin", windows[0].Timezone)
+	assert.Equal(t, database.ID, *windows[0].DatabaseID)
+}
+
+func Test_SaveDatabaseWindows_WhenUserIsViewer_ReturnsBadRequest(t *testing.T) {
+	router := createTestRouter()
+	owner := users_testing.CreateTestUser(users_enums.UserRoleMember)
+	workspace := workspaces_testing.CreateTestWorkspace("Test Workspace", owner, router)
+	notifier := notifiers.CreateTestNotifier(workspace.ID)
+	database := databases.CreateTestDatabase(workspace.ID, nil, notifier)
+
+	defer func() {
+		databases.RemoveTestDatabase(database)
+		notifiers.RemoveTestNotifier(notifier)
+		workspaces_testing.RemoveTestWorkspace(workspace, router)
+	}()
+
+	viewer := users_testing.CreateTestUser(users_enums.UserRoleMember)
+	workspaces_testing.AddMemberToWorkspace(
+		workspace,
+		viewer,
+		users_enums.WorkspaceRoleViewer,
+		owner.Token,
+		router,
+	)
+
+	resp := test_utils.MakePostRequest(
+		t,
+		router,
+		"/api/v1/backup-blackouts/database/"+database.ID.String(),
+		"Bearer "+viewer.Token,
+		SaveBlackoutWindowsRequest{
+			Windows: []*BlackoutWindow{{StartTime: "01:00", EndTime: "03:00"}},
+		},
+		http.StatusBadRequest,
+	)
+	assert.Contains(t, string(resp.Body), "insufficient permissions")
+}
+
+func Test_GetActiveBlackoutEnd_WhenDatabaseHasNoWindows_WorkspaceWindowsApplied(t *testing.T) {
+	router := createTestRouter()
+	owner := users_testing.CreateTestUser(users_enums.UserRoleMember)
+	workspace := workspaces_testing.CreateTestWorkspace("Test Workspace", owner, router)
+	notifier := notifiers.CreateTestNotifier(workspace.ID)
+	database := databases.CreateTestDatabase(workspace.ID, nil, notifier)
+
+	defer func() {
+		databases.RemoveTestDatabase(database)
+		notifiers.RemoveTestNotifier(notifier)
+		workspaces_testing.RemoveTestWorkspace(workspace, router)
+	}()
+
+	test_utils.MakePostRequest(
+		t,
+		router,
+		"/api/v1/backup-blackouts/workspace/"+workspace.ID.String(),
+		"Bearer "+owner.Token,
+		SaveBlackoutWindowsRequest{
+			Windows: []*BlackoutWindow{{StartTime: "01:00", EndTime: "03:00"}},
+		},
+		http.StatusOK,
+	)
+
+	insideOfWindow := time.Date(2024, 1, 15, 2, 0, 0, 0, time.UTC)
+
+	blackoutEnd, err := blackoutWindowService.GetActiveBlackoutEnd(database.ID, insideOfWindow)
+	assert.NoError(t, err)
+	assert.NotNil(t, blackoutEnd)
+	assert.Equal(t, time.Date(2024, 1, 15, 3, 0, 0, 0, time.UTC), *blackoutEnd)
+
+	// own windows of the database override the workspace ones
+	err = blackoutWindowRepository.ReplaceDatabaseWindows(
+		database.ID,
+		[]*BlackoutWindow{{StartTime: "12:00", EndTime: "13:00"}},
+	)
+	assert.NoError(t, err)
+
+	blackoutEnd, err = blackoutWindowService.GetActiveBlackoutEnd(database.ID, insideOfWindow)
+	assert.NoError(t, err)
+	assert.Nil(t, blackoutEnd)
+}
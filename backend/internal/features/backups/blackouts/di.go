@@ -0,0 +1,34 @@
+package backups_blackouts
+
+import (
+	audit_logs "postgresus-backend/internal/features/audit_logs"
+	"postgresus-backend/internal/features/databases"
+	workspaces_services "postgresus-backend/internal/features/workspaces/services"
+	"postgresus-backend/internal/util/logger"
+)
+
+var blackoutWindowRepository = &BlackoutWindowRepository{}
+
+var blackoutWindowService = &BlackoutWindowService{
+	blackoutWindowRepository,
+	databases.GetDatabaseService(),
+	workspaces_services.GetWorkspaceService(),
+	audit_logs.GetAuditLogService(),
+	logger.GetLogger(),
+}
+
+var blackoutWindowController = &BlackoutWindowController{
+	blackoutWindowService,
+}
+
+func SetupDependencies() {
+	databases.GetDatabaseService().AddDbCopyListener(blackoutWindowService)
+}
+
+func GetBlackoutWindowService() *BlackoutWindowService {
+	return blackoutWindowService
+}
+
+func GetBlackoutWindowController() *BlackoutWindowController {
+	return blackoutWindowController
+}
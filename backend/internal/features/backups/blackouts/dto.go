@@ -0,0 +1,5 @@
+package backups_blackouts
+
+type SaveBlackoutWindowsRequest struct {
+	Windows []*BlackoutWindow `json:"windows"`
+}
@@ -0,0 +1,152 @@
+package backups_blackouts
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// BlackoutWindow is a daily period when scheduled and retry backups are
+// not started. Windows of the workspace are applied to databases, which
+// have no own windows
+type BlackoutWindow struct {
+	ID          uuid.UUID  `json:"id"          gorm:"column:id;type:uuid;primaryKey"`
+	DatabaseID  *uuid.UUID `json:"databaseId"  gorm:"column:database_id;type:uuid"`
+	WorkspaceID *uuid.UUID `json:"workspaceId" gorm:"column:workspace_id;type:uuid"`
+
+	// "HH:MM". End before or equal to start means the window ends next day
+	StartTime string `json:"startTime" gorm:"column:start_time;type:text;not null"`
+	EndTime   string `json:"endTime"   gorm:"column:end_time;type:text;not null"`
+
+	// days when the window starts, 0 is Sunday. Empty means every day
+	Weekdays       []int  `json:"weekdays" gorm:"-"`
+	WeekdaysString string `json:"-"        gorm:"column:weekdays;type:text;not null"`
+
+	// IANA name, UTC when empty
+	Timezone string `json:"timezone" gorm:"column:timezone;type:text;not null"`
+}
+
+func (w *BlackoutWindow) TableName() string {
+	return "backup_blackout_windows"
+}
+
+func (w *BlackoutWindow) BeforeSave(tx *gorm.DB) error {
+	weekdays := make([]string, len(w.Weekdays))
+	for i, weekday := range w.Weekdays {
+		weekdays[i] = strconv.Itoa(weekday)
+	}
+
+	w.WeekdaysString = strings.Join(weekdays, ",")
+	return nil
+}
+
+func (w *BlackoutWindow) AfterFind(tx *gorm.DB) error {
+	w.Weekdays = []int{}
+
+	if w.WeekdaysString == "" {
+		return nil
+	}
+
+	for _, weekday := range strings.Split(w.WeekdaysString, ",") {
+		parsedWeekday, err := strconv.Atoi(weekday)
+		if err != nil {
+			return err
+		}
+
+		w.Weekdays = append(w.Weekdays, parsedWeekday)
+	}
+
+	return nil
+}
+
+func (w *BlackoutWindow) Validate() error {
+	if _, err := time.Parse("15:04", w.StartTime); err != nil {
+		return errors.New("start time of blackout window must be in HH:MM format")
+	}
+
+	if _, err := time.Parse("15:04", w.EndTime); err != nil {
+		return errors.New("end time of blackout window must be in HH:MM format")
+	}
+
+	for _, weekday := range w.Weekdays {
+		if weekday < 0 || weekday > 6 {
+			return errors.New("weekday of blackout window must be between 0 and 6")
+		}
+	}
+
+	if _, err := time.LoadLocation(w.Timezone); err != nil {
+		return fmt.Errorf("unknown timezone: %s", w.Timezone)
+	}
+
+	return nil
+}
+
+// GetActiveEnd returns end of the window if now is inside of it. The window
+// started yesterday is checked as well, because it may cross midnight
+func (w *BlackoutWindow) GetActiveEnd(now time.Time) (time.Time, bool) {
+	startTime, err := time.Parse("15:04", w.StartTime)
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	endTime, err := time.Parse("15:04", w.EndTime)
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	location, err := time.LoadLocation(w.Timezone)
+	if err != nil {
+		location = time.UTC
+	}
+
+	localNow := now.In(location)
+	isEndNextDay := !endTime.After(startTime)
+
+	for _, daysAgo := range []int{0, 1} {
+		startDay := time.Date(
+			localNow.Year(), localNow.Month(), localNow.Day()-daysAgo,
+			0, 0, 0, 0, location,
+		)
+
+		if len(w.Weekdays) > 0 && !slices.Contains(w.Weekdays, int(startDay.Weekday())) {
+			continue
+		}
+
+		start := time.Date(
+			startDay.Year(), startDay.Month(), startDay.Day(),
+			startTime.Hour(), startTime.Minute(), 0, 0, location,
+		)
+
+		endDay := startDay.Day()
+		if isEndNextDay {
+			endDay++
+		}
+
+		end := time.Date(
+			startDay.Year(), startDay.Month(), endDay,
+			endTime.Hour(), endTime.Minute(), 0, 0, location,
+		)
+
+		if !now.Before(start) && now.Before(end) {
+			return end.UTC(), true
+		}
+	}
+
+	return time.Time{}, false
+}
+
+func (w *BlackoutWindow) Copy(newDatabaseID uuid.UUID) *BlackoutWindow {
+	return &BlackoutWindow{
+		DatabaseID: &newDatabaseID,
+		StartTime:  w.StartTime,
+		EndTime:    w.EndTime,
+		Weekdays:   w.Weekdays,
+		Timezone:   w.Timezone,
+	}
+}
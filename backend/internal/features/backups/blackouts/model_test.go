@@ -0,0 +1,85 @@
+package backups_blackouts
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBlackoutWindow_GetActiveEnd(t *testing.T) {
+	t.Run("Inside of window: End returned", func(t *testing.T) {
+		window := &BlackoutWindow{StartTime: "01:00", EndTime: "03:00"}
+
+		end, isActive := window.GetActiveEnd(time.Date(2024, 1, 15, 2, 0, 0, 0, time.UTC))
+
+		assert.True(t, isActive)
+		assert.Equal(t, time.Date(2024, 1, 15, 3, 0, 0, 0, time.UTC), end)
+	})
+
+	t.Run("At the end of window: Not active", func(t *testing.T) {
+		window := &BlackoutWindow{StartTime: "01:00", EndTime: "03:00"}
+
+		_, isActive := window.GetActiveEnd(time.Date(2024, 1, 15, 3, 0, 0, 0, time.UTC))
+
+		assert.False(t, isActive)
+	})
+
+	t.Run("Window crosses midnight: Active after midnight", func(t *testing.T) {
+		window := &BlackoutWindow{StartTime: "22:00", EndTime: "02:00"}
+
+		end, isActive := window.GetActiveEnd(time.Date(2024, 1, 15, 1, 0, 0, 0, time.UTC))
+
+		assert.True(t, isActive)
+		assert.Equal(t, time.Date(2024, 1, 15, 2, 0, 0, 0, time.UTC), end)
+	})
+
+	t.Run("Weekday is the day when window starts", func(t *testing.T) {
+		// Monday 22:00 to Tuesday 02:00
+		window := &BlackoutWindow{StartTime: "22:00", EndTime: "02:00", Weekdays: []int{1}}
+
+		_, isActive := window.GetActiveEnd(time.Date(2024, 1, 16, 1, 0, 0, 0, time.UTC))
+		assert.True(t, isActive)
+
+		_, isActive = window.GetActiveEnd(time.Date(2024, 1, 16, 23, 0, 0, 0, time.UTC))
+		assert.False(t, isActive)
+	})
+
+	t.Run("Window is evaluated in its timezone", func(t *testing.T) {
+		window := &BlackoutWindow{StartTime: "01:00", EndTime: "03:00", Timezone: "Europe/Berlin"}
+
+		// 02:30 in Berlin
+		end, isActive := window.GetActiveEnd(time.Date(2024, 1, 15, 1, 30, 0, 0, time.UTC))
+		assert.True(t, isActive)
+		assert.Equal(t, time.Date(2024, 1, 15, 2, 0, 0, 0, time.UTC), end)
+
+		// 03:30 in Berlin
+		_, isActive = window.GetActiveEnd(time.Date(2024, 1, 15, 2, 30, 0, 0, time.UTC))
+		assert.False(t, isActive)
+	})
+}
+
+func TestBlackoutWindow_Validate(t *testing.T) {
+	t.Run("Valid window", func(t *testing.T) {
+		window := &BlackoutWindow{
+			StartTime: "01:00",
+			EndTime:   "03:00",
+			Weekdays:  []int{0, 6},
+			Timezone:  "Europe/Berlin",
+		}
+		assert.NoError(t, window.Validate())
+	})
+
+	t.Run("Invalid windows are rejected", func(t *testing.T) {
+		invalidWindows := []*BlackoutWindow{
+			{StartTime: "1am", EndTime: "03:00"},
+			{StartTime: "01:00", EndTime: "24:00"},
+			{StartTime: "01:00", EndTime: "03:00", Weekdays: []int{7}},
+			{StartTime: "01:00", EndTime: "03:00", Timezone: "Mars/Olympus"},
+		}
+
+		for _, window := range invalidWindows {
+			assert.Error(t, window.Validate())
+		}
+	})
+}
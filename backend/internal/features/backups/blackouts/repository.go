@@ -0,0 +1,85 @@
+package backups_blackouts
+
+import (
+	"postgresus-backend/internal/storage"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type BlackoutWindowRepository struct{}
+
+func (r *BlackoutWindowRepository) ReplaceDatabaseWindows(
+	databaseID uuid.UUID,
+	windows []*BlackoutWindow,
+) error {
+	for _, window := range windows {
+		window.ID = uuid.New()
+		window.DatabaseID = &databaseID
+		window.WorkspaceID = nil
+	}
+
+	return r.replaceWindows("database_id", databaseID, windows)
+}
+
+func (r *BlackoutWindowRepository) ReplaceWorkspaceWindows(
+	workspaceID uuid.UUID,
+	windows []*BlackoutWindow,
+) error {
+	for _, window := range windows {
+		window.ID = uuid.New()
+		window.DatabaseID = nil
+		window.WorkspaceID = &workspaceID
+	}
+
+	return r.replaceWindows("workspace_id", workspaceID, windows)
+}
+
+func (r *BlackoutWindowRepository) FindByDatabaseID(
+	databaseID uuid.UUID,
+) ([]*BlackoutWindow, error) {
+	return r.findWindows("database_id", databaseID)
+}
+
+func (r *BlackoutWindowRepository) FindByWorkspaceID(
+	workspaceID uuid.UUID,
+) ([]*BlackoutWindow, error) {
+	return r.findWindows("workspace_id", workspaceID)
+}
+
+func (r *BlackoutWindowRepository) replaceWindows(
+	ownerColumn string,
+	ownerID uuid.UUID,
+	windows []*BlackoutWindow,
+) error {
+	return storage.GetDb().Transaction(func(tx *gorm.DB) error {
+		if err := tx.
+			Where(ownerColumn+" = ?", ownerID).
+			Delete(&BlackoutWindow{}).Error; err != nil {
+			return err
+		}
+
+		if len(windows) == 0 {
+			return nil
+		}
+
+		return tx.Create(windows).Error
+	})
+}
+
+func (r *BlackoutWindowRepository) findWindows(
+	ownerColumn string,
+	ownerID uuid.UUID,
+) ([]*BlackoutWindow, error) {
+	var windows []*BlackoutWindow
+
+	if err := storage.
+		GetDb().
+		Where(ownerColumn+" = ?", ownerID).
+		Order("start_time ASC").
+		Find(&windows).Error; err != nil {
+		return nil, err
+	}
+
+	return windows, nil
+}
@@ -0,0 +1,218 @@
+package backups_blackouts
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	audit_logs "postgresus-backend/internal/features/audit_logs"
+	"postgresus-backend/internal/features/databases"
+	users_models "postgresus-backend/internal/features/users/models"
+	workspaces_services "postgresus-backend/internal/features/workspaces/services"
+
+	"github.com/google/uuid"
+)
+
+type BlackoutWindowService struct {
+	blackoutWindowRepository *BlackoutWindowRepository
+	databaseService          *databases.DatabaseService
+	workspaceService         *workspaces_services.WorkspaceService
+	auditLogService          *audit_logs.AuditLogService
+	logger                   *slog.Logger
+}
+
+func (s *BlackoutWindowService) SaveDatabaseWindowsWithAuth(
+	user *users_models.User,
+	databaseID uuid.UUID,
+	windows []*BlackoutWindow,
+) ([]*BlackoutWindow, error) {
+	if err := validateWindows(windows); err != nil {
+		return nil, err
+	}
+
+	database, err := s.databaseService.GetDatabaseByID(databaseID)
+	if err != nil {
+		return nil, err
+	}
+
+	if database.WorkspaceID == nil {
+		return nil, errors.New("cannot modify blackout windows for databases without workspace")
+	}
+
+	canManage, err := s.workspaceService.CanUserManageDBs(*database.WorkspaceID, user)
+	if err != nil {
+		return nil, err
+	}
+	if !canManage {
+		return nil, errors.New("insufficient permissions to modify blackout windows")
+	}
+
+	if err := s.blackoutWindowRepository.ReplaceDatabaseWindows(database.ID, windows); err != nil {
+		return nil, err
+	}
+
+	s.auditLogService.WriteAuditLog(
+		fmt.Sprintf("Backup blackout windows updated for database '%s'", database.Name),
+		&user.ID,
+		database.WorkspaceID,
+	)
+
+	return s.blackoutWindowRepository.FindByDatabaseID(database.ID)
+}
+
+func (s *BlackoutWindowService) GetDatabaseWindowsWithAuth(
+	user *users_models.User,
+	databaseID uuid.UUID,
+) ([]*BlackoutWindow, error) {
+	database, err := s.databaseService.GetDatabaseByID(databaseID)
+	if err != nil {
+		return nil, err
+	}
+
+	if database.WorkspaceID == nil {
+		return nil, errors.New("cannot access blackout windows for databases without workspace")
+	}
+
+	canAccess, _, err := s.workspaceService.CanUserAccessWorkspace(*database.WorkspaceID, user)
+	if err != nil {
+		return nil, err
+	}
+	if !canAccess {
+		return nil, errors.New("insufficient permissions to view blackout windows")
+	}
+
+	return s.blackoutWindowRepository.FindByDatabaseID(database.ID)
+}
+
+func (s *BlackoutWindowService) SaveWorkspaceWindowsWithAuth(
+	user *users_models.User,
+	workspaceID uuid.UUID,
+	windows []*BlackoutWindow,
+) ([]*BlackoutWindow, error) {
+	if err := validateWindows(windows); err != nil {
+		return nil, err
+	}
+
+	canManage, err := s.workspaceService.CanUserManageWorkspace(workspaceID, user)
+	if err != nil {
+		return nil, err
+	}
+	if !canManage {
+		return nil, errors.New("insufficient permissions to modify blackout windows")
+	}
+
+	if err := s.blackoutWindowRepository.ReplaceWorkspaceWindows(workspaceID, windows); err != nil {
+		return nil, err
+	}
+
+	s.auditLogService.WriteAuditLog(
+		"Default backup blackout windows of workspace updated",
+		&user.ID,
+		&workspaceID,
+	)
+
+	return s.blackoutWindowRepository.FindByWorkspaceID(workspaceID)
+}
+
+func (s *BlackoutWindowService) GetWorkspaceWindowsWithAuth(
+	user *users_models.User,
+	workspaceID uuid.UUID,
+) ([]*BlackoutWindow, error) {
+	canAccess, _, err := s.workspaceService.CanUserAccessWorkspace(workspaceID, user)
+	if err != nil {
+		return nil, err
+	}
+	if !canAccess {
+		return nil, errors.New("insufficient permissions to view blackout windows")
+	}
+
+	return s.blackoutWindowRepository.FindByWorkspaceID(workspaceID)
+}
+
+// GetActiveBlackoutEnd returns the time when backups of the database are
+// allowed again or nil when there is no active blackout window. Windows
+// of the workspace are used when the database has no own ones
+func (s *BlackoutWindowService) GetActiveBlackoutEnd(
+	databaseID uuid.UUID,
+	now time.Time,
+) (*time.Time, error) {
+	windows, err := s.getEffectiveWindows(databaseID)
+	if err != nil {
+		return nil, err
+	}
+
+	var latestEnd *time.Time
+
+	// overlapping windows are treated as one
+	for _, window := range windows {
+		end, isActive := window.GetActiveEnd(now)
+		if isActive && (latestEnd == nil || end.After(*latestEnd)) {
+			latestEnd = &end
+		}
+	}
+
+	return latestEnd, nil
+}
+
+func (s *BlackoutWindowService) OnDatabaseCopied(originalDatabaseID, newDatabaseID uuid.UUID) {
+	windows, err := s.blackoutWindowRepository.FindByDatabaseID(originalDatabaseID)
+	if err != nil {
+		s.logger.Error("Failed to find blackout windows of copied database", "error", err)
+		return
+	}
+
+	if len(windows) == 0 {
+		return
+	}
+
+	copiedWindows := make([]*BlackoutWindow, len(windows))
+	for i, window := range windows {
+		copiedWindows[i] = window.Copy(newDatabaseID)
+	}
+
+	if err := s.blackoutWindowRepository.ReplaceDatabaseWindows(
+		newDatabaseID,
+		copiedWindows,
+	); err != nil {
+		s.logger.Error("Failed to copy blackout windows", "error", err)
+	}
+}
+
+func (s *BlackoutWindowService) getEffectiveWindows(
+	databaseID uuid.UUID,
+) ([]*BlackoutWindow, error) {
+	windows, err := s.blackoutWindowRepository.FindByDatabaseID(databaseID)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(windows) > 0 {
+		return windows, nil
+	}
+
+	database, err := s.databaseService.GetDatabaseByID(databaseID)
+	if err != nil {
+		return nil, err
+	}
+
+	if database.WorkspaceID == nil {
+		return nil, nil
+	}
+
+	return s.blackoutWindowRepository.FindByWorkspaceID(*database.WorkspaceID)
+}
+
+func validateWindows(windows []*BlackoutWindow) error {
+	for _, window := range windows {
+		if window == nil {
+			return errors.New("blackout window is required")
+		}
+
+		if err := window.Validate(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
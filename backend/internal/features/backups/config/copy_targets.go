@@ -0,0 +1,86 @@
+package backups_config
+
+import (
+	"errors"
+
+	"postgresus-backend/internal/util/period"
+
+	"github.com/google/uuid"
+)
+
+// BackupCopyTarget is additional storage backups are copied to in the
+// background. Copies have their own retention, independent of the
+// retention of the backup storage
+type BackupCopyTarget struct {
+	StorageID   uuid.UUID           `json:"storageId"`
+	Frequency   BackupCopyFrequency `json:"frequency"`
+	StorePeriod period.Period       `json:"storePeriod"`
+}
+
+func (t *BackupCopyTarget) Validate() error {
+	if t.StorageID == uuid.Nil {
+		return errors.New("copy storage is required")
+	}
+
+	switch t.Frequency {
+	case BackupCopyFrequencyEveryBackup,
+		BackupCopyFrequencyDaily,
+		BackupCopyFrequencyWeekly,
+		BackupCopyFrequencyMonthly:
+	default:
+		return errors.New("copy frequency must be EVERY_BACKUP, DAILY, WEEKLY or MONTHLY")
+	}
+
+	if t.StorePeriod == "" {
+		return errors.New("copy store period is required")
+	}
+
+	if !t.StorePeriod.IsValid() {
+		return errors.New("copy store period is unknown")
+	}
+
+	return nil
+}
+
+// GetCopyTarget returns copy target of the storage or nil
+// if backups are not copied there automatically
+func (b *BackupConfig) GetCopyTarget(storageID uuid.UUID) *BackupCopyTarget {
+	for _, target := range b.CopyTargets {
+		if target.StorageID == storageID {
+			return target
+		}
+	}
+
+	return nil
+}
+
+func (b *BackupConfig) validateCopyTargets() error {
+	storageIDs := map[uuid.UUID]bool{}
+
+	for _, target := range b.CopyTargets {
+		if err := target.Validate(); err != nil {
+			return err
+		}
+
+		if b.StorageID != nil && *b.StorageID == target.StorageID {
+			return errors.New("backups cannot be copied to the storage they are saved to")
+		}
+
+		if storageIDs[target.StorageID] {
+			return errors.New("backups can be copied to the same storage only once")
+		}
+		storageIDs[target.StorageID] = true
+	}
+
+	return nil
+}
+
+func copyCopyTargets(targets []*BackupCopyTarget) []*BackupCopyTarget {
+	copied := make([]*BackupCopyTarget, 0, len(targets))
+	for _, target := range targets {
+		targetCopy := *target
+		copied = append(copied, &targetCopy)
+	}
+
+	return copied
+}
@@ -5,6 +5,10 @@ type BackupNotificationType string
 const (
 	NotificationBackupFailed  BackupNotificationType = "BACKUP_FAILED"
 	NotificationBackupSuccess BackupNotificationType = "BACKUP_SUCCESS"
+	// new database appeared on the server in whole-server mode
+	NotificationNewDatabaseFound BackupNotificationType = "NEW_DATABASE_FOUND"
+	// size or duration of completed backup differs much from the recent ones
+	NotificationBackupAnomaly BackupNotificationType = "BACKUP_ANOMALY"
 )
 
 type BackupEncryption string
@@ -13,3 +17,89 @@ const (
 	BackupEncryptionNone      BackupEncryption = "NONE"
 	BackupEncryptionEncrypted BackupEncryption = "ENCRYPTED"
 )
+
+type BackupMethod string
+
+const (
+	// logical dump of a single database
+	BackupMethodPgDump BackupMethod = "PG_DUMP"
+	// physical copy of the whole cluster, can be used for point-in-time recovery
+	BackupMethodPgBasebackup BackupMethod = "PG_BASEBACKUP"
+)
+
+type BackupFormat string
+
+const (
+	// pg_dump -Fc, restored via pg_restore
+	BackupFormatCustom BackupFormat = "CUSTOM"
+	// pg_dump -Fd -j, the directory is saved as tar. Unlike custom
+	// format it allows to dump tables in parallel
+	BackupFormatDirectory BackupFormat = "DIRECTORY"
+	// pg_dump -Fp, uncompressed .sql restored via psql
+	BackupFormatPlain BackupFormat = "PLAIN"
+)
+
+type BackupCompression string
+
+const (
+	BackupCompressionZstd BackupCompression = "ZSTD"
+	BackupCompressionLz4  BackupCompression = "LZ4"
+	BackupCompressionGzip BackupCompression = "GZIP"
+	BackupCompressionNone BackupCompression = "NONE"
+)
+
+const DefaultCompressionLevel = 5
+
+const DefaultMinKeepCount = 1
+
+const (
+	DefaultRetryDelaySeconds    = 60
+	DefaultRetryMaxDelaySeconds = 60 * 60
+	DefaultRetryJitterPercent   = 20
+)
+
+const (
+	DefaultSizeAnomalyThresholdPercent     = 50
+	DefaultDurationAnomalyThresholdPercent = 100
+)
+
+type RetentionPolicyType string
+
+const (
+	// backups older than store period are deleted
+	RetentionPolicyStorePeriod RetentionPolicyType = "STORE_PERIOD"
+	// grandfather-father-son: the last backup of each of the last N
+	// hours, days, weeks, months and years is kept
+	RetentionPolicyGfs RetentionPolicyType = "GFS"
+	// the last N completed backups are kept regardless of their age
+	RetentionPolicyCount RetentionPolicyType = "COUNT"
+)
+
+type BackupHookStage string
+
+const (
+	BackupHookStagePre  BackupHookStage = "PRE"
+	BackupHookStagePost BackupHookStage = "POST"
+)
+
+type BackupHookType string
+
+const (
+	// executed on the backed up database with its credentials
+	BackupHookTypeSql BackupHookType = "SQL"
+	// executed via sh -c on the host of Postgresus
+	BackupHookTypeShell BackupHookType = "SHELL"
+)
+
+const DefaultBackupHookTimeoutSeconds = 60
+
+type BackupCopyFrequency string
+
+const (
+	// every completed backup is copied
+	BackupCopyFrequencyEveryBackup BackupCopyFrequency = "EVERY_BACKUP"
+	// the first completed backup of each UTC day, ISO week or month is copied
+	BackupCopyFrequencyDaily   BackupCopyFrequency = "DAILY"
+	BackupCopyFrequencyWeekly  BackupCopyFrequency = "WEEKLY"
+	BackupCopyFrequencyMonthly BackupCopyFrequency = "MONTHLY"
+)
@@ -0,0 +1,70 @@
+package backups_config
+
+import (
+	"errors"
+	"time"
+
+	"postgresus-backend/internal/config"
+)
+
+const maxBackupHookTimeoutSeconds = 24 * 60 * 60
+
+// BackupHook is SQL or shell command executed before or after the backup,
+// e.g. to pause a replication consumer or to run CHECKPOINT
+type BackupHook struct {
+	Stage   BackupHookStage `json:"stage"`
+	Type    BackupHookType  `json:"type"`
+	Command string          `json:"command"`
+
+	// 0 means DefaultBackupHookTimeoutSeconds
+	TimeoutSeconds int `json:"timeoutSeconds"`
+
+	// otherwise failure is only written to the hooks output of the backup
+	IsFailBackupOnError bool `json:"isFailBackupOnError"`
+}
+
+func (h *BackupHook) Validate() error {
+	if h.Stage != BackupHookStagePre && h.Stage != BackupHookStagePost {
+		return errors.New("hook stage must be PRE or POST")
+	}
+
+	switch h.Type {
+	case BackupHookTypeSql:
+	case BackupHookTypeShell:
+		if !config.GetEnv().IsShellHooksEnabled {
+			return errors.New("shell hooks are disabled, set IS_SHELL_HOOKS_ENABLED to enable them")
+		}
+	default:
+		return errors.New("hook type must be SQL or SHELL")
+	}
+
+	if h.Command == "" {
+		return errors.New("hook command is required")
+	}
+
+	if h.TimeoutSeconds < 0 || h.TimeoutSeconds > maxBackupHookTimeoutSeconds {
+		return errors.New("hook timeout must be between 0 and 86400 seconds")
+	}
+
+	return nil
+}
+
+func (h *BackupHook) GetTimeout() time.Duration {
+	if h.TimeoutSeconds == 0 {
+		return DefaultBackupHookTimeoutSeconds * time.Second
+	}
+
+	return time.Duration(h.TimeoutSeconds) * time.Second
+}
+
+// GetHooks returns hooks of the stage in the order they are configured
+func (b *BackupConfig) GetHooks(stage BackupHookStage) []*BackupHook {
+	hooks := []*BackupHook{}
+	for _, hook := range b.Hooks {
+		if hook.Stage == stage {
+			hooks = append(hooks, hook)
+		}
+	}
+
+	return hooks
+}
@@ -1,16 +1,26 @@
 package backups_config
 
 import (
+	"encoding/json"
 	"errors"
+	"fmt"
+	"path"
 	"postgresus-backend/internal/features/intervals"
 	"postgresus-backend/internal/features/storages"
 	"postgresus-backend/internal/util/period"
+	"postgresus-backend/internal/util/tools"
 	"strings"
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
 
+// exclusions of the database are patterns for the single database,
+// applying them to every database of the server would drop unrelated data
+var ErrWholeServerBackupExclusions = errors.New(
+	"whole-server backup does not support schema and table exclusions of the database, remove them first",
+)
+
 type BackupConfig struct {
 	DatabaseID uuid.UUID `json:"databaseId" gorm:"column:database_id;type:uuid;primaryKey;not null"`
 
@@ -18,6 +28,18 @@ type BackupConfig struct {
 
 	StorePeriod period.Period `json:"storePeriod" gorm:"column:store_period;type:text;not null"`
 
+	RetentionPolicyType RetentionPolicyType `json:"retentionPolicyType" gorm:"column:retention_policy_type;type:text;not null;default:'STORE_PERIOD'"`
+	GfsHourlyCount      int                 `json:"gfsHourlyCount"      gorm:"column:gfs_hourly_count;type:int;not null;default:0"`
+	GfsDailyCount       int                 `json:"gfsDailyCount"       gorm:"column:gfs_daily_count;type:int;not null;default:0"`
+	GfsWeeklyCount      int                 `json:"gfsWeeklyCount"      gorm:"column:gfs_weekly_count;type:int;not null;default:0"`
+	GfsMonthlyCount     int                 `json:"gfsMonthlyCount"     gorm:"column:gfs_monthly_count;type:int;not null;default:0"`
+	GfsYearlyCount      int                 `json:"gfsYearlyCount"      gorm:"column:gfs_yearly_count;type:int;not null;default:0"`
+	KeepLastCount       int                 `json:"keepLastCount"       gorm:"column:keep_last_count;type:int;not null;default:0"`
+
+	// the last N completed backups are never deleted by any retention
+	// policy, so failing backups cannot lead to losing all good copies
+	MinKeepCount int `json:"minKeepCount" gorm:"column:min_keep_count;type:int;not null;default:1"`
+
 	BackupIntervalID uuid.UUID           `json:"backupIntervalId"         gorm:"column:backup_interval_id;type:uuid;not null"`
 	BackupInterval   *intervals.Interval `json:"backupInterval,omitempty" gorm:"foreignKey:BackupIntervalID"`
 
@@ -30,9 +52,54 @@ type BackupConfig struct {
 	IsRetryIfFailed     bool `json:"isRetryIfFailed"     gorm:"column:is_retry_if_failed;type:boolean;not null"`
 	MaxFailedTriesCount int  `json:"maxFailedTriesCount" gorm:"column:max_failed_tries_count;type:int;not null"`
 
+	// delay before retry is doubled after each failed try up to the maximum
+	// and randomized by jitter, so a database under maintenance is not hammered.
+	// Zero values are replaced by defaults on save
+	RetryDelaySeconds    int `json:"retryDelaySeconds"    gorm:"column:retry_delay_seconds;type:int;not null;default:0"`
+	RetryMaxDelaySeconds int `json:"retryMaxDelaySeconds" gorm:"column:retry_max_delay_seconds;type:int;not null;default:0"`
+	RetryJitterPercent   int `json:"retryJitterPercent"   gorm:"column:retry_jitter_percent;type:int;not null;default:0"`
+
+	// allowed deviation from the median of recent backups, 0 disables the check
+	SizeAnomalyThresholdPercent     int `json:"sizeAnomalyThresholdPercent"     gorm:"column:size_anomaly_threshold_percent;type:int;not null;default:0"`
+	DurationAnomalyThresholdPercent int `json:"durationAnomalyThresholdPercent" gorm:"column:duration_anomaly_threshold_percent;type:int;not null;default:0"`
+
+	// with PRIORITY queue order backups of higher priority start first
+	QueuePriority int `json:"queuePriority" gorm:"column:queue_priority;type:int;not null;default:0"`
+
 	CpuCount int `json:"cpuCount" gorm:"type:int;not null"`
 
 	Encryption BackupEncryption `json:"encryption" gorm:"column:encryption;type:text;not null;default:'NONE'"`
+
+	BackupMethod BackupMethod `json:"backupMethod" gorm:"column:backup_method;type:text;not null;default:'PG_DUMP'"`
+
+	Format BackupFormat `json:"format" gorm:"column:format;type:text;not null;default:'CUSTOM'"`
+
+	// applied by pg_dump itself, so pg_restore reads the dump as is.
+	// Already compressed data (bytea, images) is cheaper to keep as is
+	Compression      BackupCompression `json:"compression"      gorm:"column:compression;type:text;not null;default:'ZSTD'"`
+	CompressionLevel int               `json:"compressionLevel" gorm:"column:compression_level;type:int;not null;default:5"`
+
+	// roles and tablespaces are not included into pg_dump, so they
+	// are saved via pg_dumpall as a separate file of the same backup
+	IsBackupGlobals bool `json:"isBackupGlobals" gorm:"column:is_backup_globals;type:boolean;not null;default:false"`
+
+	// backs up every non-template database of the server as separate
+	// artifact of the same backup. Patterns are matched via path.Match
+	IsWholeServerBackup    bool     `json:"isWholeServerBackup" gorm:"column:is_whole_server_backup;type:boolean;not null;default:false"`
+	IncludeDatabases       []string `json:"includeDatabases"    gorm:"-"`
+	IncludeDatabasesString string   `json:"-"                   gorm:"column:include_databases;type:text;not null;default:''"`
+	ExcludeDatabases       []string `json:"excludeDatabases"    gorm:"-"`
+	ExcludeDatabasesString string   `json:"-"                   gorm:"column:exclude_databases;type:text;not null;default:''"`
+
+	// continuously streams WAL to the storage, so backups can be
+	// restored to any point in time between them
+	IsWalArchivingEnabled bool `json:"isWalArchivingEnabled" gorm:"column:is_wal_archiving_enabled;type:boolean;not null;default:false"`
+
+	Hooks       []*BackupHook `json:"hooks" gorm:"-"`
+	HooksString string        `json:"-"     gorm:"column:hooks;type:text;not null;default:'[]'"`
+
+	CopyTargets       []*BackupCopyTarget `json:"copyTargets" gorm:"-"`
+	CopyTargetsString string              `json:"-"           gorm:"column:copy_targets;type:text;not null;default:'[]'"`
 }
 
 func (h *BackupConfig) TableName() string {
@@ -53,6 +120,33 @@ func (b *BackupConfig) BeforeSave(tx *gorm.DB) error {
 		b.SendNotificationsOnString = ""
 	}
 
+	if b.BackupMethod == "" {
+		b.BackupMethod = BackupMethodPgDump
+	}
+
+	if b.Format == "" {
+		b.Format = BackupFormatCustom
+	}
+
+	if b.RetentionPolicyType == "" {
+		b.RetentionPolicyType = RetentionPolicyStorePeriod
+	}
+
+	b.IncludeDatabasesString = strings.Join(b.IncludeDatabases, ",")
+	b.ExcludeDatabasesString = strings.Join(b.ExcludeDatabases, ",")
+
+	hooks, err := marshalJsonList(b.Hooks)
+	if err != nil {
+		return err
+	}
+	b.HooksString = hooks
+
+	copyTargets, err := marshalJsonList(b.CopyTargets)
+	if err != nil {
+		return err
+	}
+	b.CopyTargetsString = copyTargets
+
 	return nil
 }
 
@@ -69,6 +163,32 @@ func (b *BackupConfig) AfterFind(tx *gorm.DB) error {
 		b.SendNotificationsOn = []BackupNotificationType{}
 	}
 
+	if b.IncludeDatabasesString != "" {
+		b.IncludeDatabases = strings.Split(b.IncludeDatabasesString, ",")
+	} else {
+		b.IncludeDatabases = []string{}
+	}
+
+	if b.ExcludeDatabasesString != "" {
+		b.ExcludeDatabases = strings.Split(b.ExcludeDatabasesString, ",")
+	} else {
+		b.ExcludeDatabases = []string{}
+	}
+
+	b.Hooks = []*BackupHook{}
+	if b.HooksString != "" {
+		if err := json.Unmarshal([]byte(b.HooksString), &b.Hooks); err != nil {
+			return err
+		}
+	}
+
+	b.CopyTargets = []*BackupCopyTarget{}
+	if b.CopyTargetsString != "" {
+		if err := json.Unmarshal([]byte(b.CopyTargetsString), &b.CopyTargets); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
@@ -90,26 +210,276 @@ func (b *BackupConfig) Validate() error {
 		return errors.New("max failed tries count must be greater than 0")
 	}
 
+	if b.RetryDelaySeconds < 0 || b.RetryMaxDelaySeconds < 0 {
+		return errors.New("retry delay cannot be negative")
+	}
+
+	if b.RetryMaxDelaySeconds > 0 && b.RetryMaxDelaySeconds < b.RetryDelaySeconds {
+		return errors.New("max retry delay must not be less than retry delay")
+	}
+
+	if b.RetryJitterPercent < 0 || b.RetryJitterPercent > 100 {
+		return errors.New("retry jitter must be between 0 and 100 percent")
+	}
+
+	if b.SizeAnomalyThresholdPercent < 0 || b.DurationAnomalyThresholdPercent < 0 {
+		return errors.New("anomaly threshold cannot be negative")
+	}
+
 	if b.Encryption != "" && b.Encryption != BackupEncryptionNone &&
 		b.Encryption != BackupEncryptionEncrypted {
 		return errors.New("encryption must be NONE or ENCRYPTED")
 	}
 
+	if b.BackupMethod != "" && b.BackupMethod != BackupMethodPgDump &&
+		b.BackupMethod != BackupMethodPgBasebackup {
+		return errors.New("backup method must be PG_DUMP or PG_BASEBACKUP")
+	}
+
+	if b.Format != "" && b.Format != BackupFormatCustom && b.Format != BackupFormatDirectory &&
+		b.Format != BackupFormatPlain {
+		return errors.New("format must be CUSTOM, DIRECTORY or PLAIN")
+	}
+
+	if b.Format != "" && b.Format != BackupFormatCustom &&
+		b.BackupMethod == BackupMethodPgBasebackup {
+		return errors.New("format is applied to pg_dump backups only")
+	}
+
+	if err := b.validateCompressionLevel(); err != nil {
+		return err
+	}
+
+	if err := b.validateRetentionPolicy(); err != nil {
+		return err
+	}
+
+	if b.IsBackupGlobals && b.BackupMethod == BackupMethodPgBasebackup {
+		return errors.New("globals are already included into physical base backup")
+	}
+
+	if b.IsWholeServerBackup && b.BackupMethod == BackupMethodPgBasebackup {
+		return errors.New("physical base backup always contains the whole server")
+	}
+
+	for _, pattern := range append(b.IncludeDatabases, b.ExcludeDatabases...) {
+		if _, err := path.Match(pattern, ""); err != nil || strings.Contains(pattern, ",") {
+			return fmt.Errorf("invalid database pattern: %s", pattern)
+		}
+	}
+
+	for _, hook := range b.Hooks {
+		if err := hook.Validate(); err != nil {
+			return err
+		}
+	}
+
+	if err := b.validateCopyTargets(); err != nil {
+		return err
+	}
+
 	return nil
 }
 
+// GetAppliedCompression returns compression actually applied to the backup.
+// Plain SQL is kept readable by psql and base backup tar is extracted as is
+func (b *BackupConfig) GetAppliedCompression() BackupCompression {
+	if b.Format == BackupFormatPlain || b.BackupMethod == BackupMethodPgBasebackup {
+		return BackupCompressionNone
+	}
+
+	return b.Compression
+}
+
+// ValidateCompressionForVersion checks the compression is supported by pg_dump
+// of the database version. Before PostgreSQL 16 pg_dump supports only gzip
+func (b *BackupConfig) ValidateCompressionForVersion(version tools.PostgresqlVersion) error {
+	compression := b.GetAppliedCompression()
+	if compression != BackupCompressionZstd && compression != BackupCompressionLz4 {
+		return nil
+	}
+
+	if isOnlyGzipSupported(version) {
+		return fmt.Errorf(
+			"%s compression requires PostgreSQL 16 or newer, use GZIP or NONE for PostgreSQL %s",
+			b.Compression,
+			version,
+		)
+	}
+
+	return nil
+}
+
+// IsDatabaseIncluded checks the database against include and exclude
+// patterns. Empty include patterns mean all databases are included
+func (b *BackupConfig) IsDatabaseIncluded(databaseName string) bool {
+	for _, pattern := range b.ExcludeDatabases {
+		if isMatched, _ := path.Match(pattern, databaseName); isMatched {
+			return false
+		}
+	}
+
+	if len(b.IncludeDatabases) == 0 {
+		return true
+	}
+
+	for _, pattern := range b.IncludeDatabases {
+		if isMatched, _ := path.Match(pattern, databaseName); isMatched {
+			return true
+		}
+	}
+
+	return false
+}
+
 func (b *BackupConfig) Copy(newDatabaseID uuid.UUID) *BackupConfig {
 	return &BackupConfig{
 		DatabaseID:          newDatabaseID,
 		IsBackupsEnabled:    b.IsBackupsEnabled,
 		StorePeriod:         b.StorePeriod,
+		RetentionPolicyType: b.RetentionPolicyType,
+		GfsHourlyCount:      b.GfsHourlyCount,
+		GfsDailyCount:       b.GfsDailyCount,
+		GfsWeeklyCount:      b.GfsWeeklyCount,
+		GfsMonthlyCount:     b.GfsMonthlyCount,
+		GfsYearlyCount:      b.GfsYearlyCount,
+		KeepLastCount:       b.KeepLastCount,
+		MinKeepCount:        b.MinKeepCount,
 		BackupIntervalID:    uuid.Nil,
 		BackupInterval:      b.BackupInterval.Copy(),
 		StorageID:           b.StorageID,
 		SendNotificationsOn: b.SendNotificationsOn,
 		IsRetryIfFailed:     b.IsRetryIfFailed,
 		MaxFailedTriesCount: b.MaxFailedTriesCount,
+		QueuePriority:       b.QueuePriority,
 		CpuCount:            b.CpuCount,
 		Encryption:          b.Encryption,
+		BackupMethod:        b.BackupMethod,
+		Format:              b.Format,
+		Compression:         b.Compression,
+		CompressionLevel:    b.CompressionLevel,
+		IsBackupGlobals:     b.IsBackupGlobals,
+		IsWholeServerBackup: b.IsWholeServerBackup,
+		IncludeDatabases:    b.IncludeDatabases,
+		ExcludeDatabases:    b.ExcludeDatabases,
+
+		IsWalArchivingEnabled: b.IsWalArchivingEnabled,
+		Hooks:                 copyHooks(b.Hooks),
+		CopyTargets:           copyCopyTargets(b.CopyTargets),
+
+		SizeAnomalyThresholdPercent:     b.SizeAnomalyThresholdPercent,
+		DurationAnomalyThresholdPercent: b.DurationAnomalyThresholdPercent,
+
+		RetryDelaySeconds:    b.RetryDelaySeconds,
+		RetryMaxDelaySeconds: b.RetryMaxDelaySeconds,
+		RetryJitterPercent:   b.RetryJitterPercent,
+	}
+}
+
+// GetDefaultCompression returns zstd where pg_dump supports it,
+// otherwise gzip
+func GetDefaultCompression(version tools.PostgresqlVersion) BackupCompression {
+	if isOnlyGzipSupported(version) {
+		return BackupCompressionGzip
 	}
+
+	return BackupCompressionZstd
+}
+
+func (b *BackupConfig) validateCompressionLevel() error {
+	switch b.Compression {
+	case "", BackupCompressionNone:
+		return nil
+	case BackupCompressionGzip:
+		if b.CompressionLevel < 1 || b.CompressionLevel > 9 {
+			return errors.New("gzip compression level must be between 1 and 9")
+		}
+	case BackupCompressionLz4:
+		if b.CompressionLevel < 1 || b.CompressionLevel > 12 {
+			return errors.New("lz4 compression level must be between 1 and 12")
+		}
+	case BackupCompressionZstd:
+		if b.CompressionLevel < 1 || b.CompressionLevel > 22 {
+			return errors.New("zstd compression level must be between 1 and 22")
+		}
+	default:
+		return errors.New("compression must be ZSTD, LZ4, GZIP or NONE")
+	}
+
+	return nil
+}
+
+func (b *BackupConfig) validateRetentionPolicy() error {
+	if b.MinKeepCount < 0 {
+		return errors.New("minimum kept backups count cannot be negative")
+	}
+
+	switch b.RetentionPolicyType {
+	case "", RetentionPolicyStorePeriod:
+		return nil
+	case RetentionPolicyCount:
+		if b.KeepLastCount <= 0 {
+			return errors.New("count of kept backups must be greater than 0")
+		}
+
+		return nil
+	case RetentionPolicyGfs:
+		counts := []int{
+			b.GfsHourlyCount,
+			b.GfsDailyCount,
+			b.GfsWeeklyCount,
+			b.GfsMonthlyCount,
+			b.GfsYearlyCount,
+		}
+
+		isAnyKept := false
+		for _, count := range counts {
+			if count < 0 {
+				return errors.New("GFS backups count cannot be negative")
+			}
+
+			if count > 0 {
+				isAnyKept = true
+			}
+		}
+
+		if !isAnyKept {
+			return errors.New("GFS retention policy must keep at least one hourly, daily, weekly, monthly or yearly backup")
+		}
+
+		return nil
+	default:
+		return errors.New("retention policy must be STORE_PERIOD, GFS or COUNT")
+	}
+}
+
+// marshalJsonList saves nil list as empty one
+func marshalJsonList[T any](list []T) (string, error) {
+	if list == nil {
+		return "[]", nil
+	}
+
+	data, err := json.Marshal(list)
+	if err != nil {
+		return "", err
+	}
+
+	return string(data), nil
+}
+
+func copyHooks(hooks []*BackupHook) []*BackupHook {
+	copied := make([]*BackupHook, 0, len(hooks))
+	for _, hook := range hooks {
+		hookCopy := *hook
+		copied = append(copied, &hookCopy)
+	}
+
+	return copied
+}
+
+func isOnlyGzipSupported(version tools.PostgresqlVersion) bool {
+	return version == tools.PostgresqlVersion12 ||
+		version == tools.PostgresqlVersion13 ||
+		version == tools.PostgresqlVersion14 ||
+		version == tools.PostgresqlVersion15
 }
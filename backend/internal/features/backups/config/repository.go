@@ -1,6 +1,7 @@
 package backups_config
 
 import (
+	"encoding/json"
 	"errors"
 	"postgresus-backend/internal/storage"
 
@@ -92,10 +93,33 @@ func (r *BackupConfigRepository) GetWithEnabledBackups() ([]*BackupConfig, error
 func (r *BackupConfigRepository) IsStorageUsing(storageID uuid.UUID) (bool, error) {
 	var count int64
 
+	// copy targets are stored as JSON array of objects
+	copyTargetFilter, err := json.Marshal([]map[string]string{{"storageId": storageID.String()}})
+	if err != nil {
+		return false, err
+	}
+
 	if err := storage.
 		GetDb().
 		Table("backup_configs").
-		Where("storage_id = ?", storageID).
+		Where(
+			"storage_id = ? OR copy_targets::jsonb @> ?::jsonb",
+			storageID,
+			string(copyTargetFilter),
+		).
+		Count(&count).Error; err != nil {
+		return false, err
+	}
+
+	if count > 0 {
+		return true, nil
+	}
+
+	// completed copies stay in the storage after the copy target is removed
+	if err := storage.
+		GetDb().
+		Table("backup_copies").
+		Where("storage_id = ? AND status = ?", storageID, "COMPLETED").
 		Count(&count).Error; err != nil {
 		return false, err
 	}
@@ -9,6 +9,7 @@ import (
 	users_models "postgresus-backend/internal/features/users/models"
 	workspaces_services "postgresus-backend/internal/features/workspaces/services"
 	"postgresus-backend/internal/util/period"
+	"postgresus-backend/internal/util/tools"
 
 	"github.com/google/uuid"
 )
@@ -53,6 +54,32 @@ func (s *BackupConfigService) SaveBackupConfigWithAuth(
 		return nil, errors.New("insufficient permissions to modify backup configuration")
 	}
 
+	for _, target := range backupConfig.CopyTargets {
+		copyStorage, err := s.storageService.GetStorage(user, target.StorageID)
+		if err != nil {
+			return nil, err
+		}
+
+		if copyStorage.WorkspaceID != *database.WorkspaceID {
+			return nil, errors.New("backups can be copied only to storages of the database workspace")
+		}
+	}
+
+	if backupConfig.IsWalArchivingEnabled {
+		existingConfig, err := s.GetBackupConfigByDbId(backupConfig.DatabaseID)
+		if err != nil {
+			return nil, err
+		}
+
+		// checked when archiving is turned on, so other settings can
+		// be changed while the database is unavailable
+		if existingConfig == nil || !existingConfig.IsWalArchivingEnabled {
+			if err := s.databaseService.CheckReplicationAllowed(database); err != nil {
+				return nil, err
+			}
+		}
+	}
+
 	return s.SaveBackupConfig(backupConfig)
 }
 
@@ -63,6 +90,61 @@ func (s *BackupConfigService) SaveBackupConfig(
 		return nil, err
 	}
 
+	version, err := s.getDatabaseVersion(backupConfig.DatabaseID)
+	if err != nil {
+		return nil, err
+	}
+
+	if backupConfig.IsWholeServerBackup {
+		database, err := s.databaseService.GetDatabaseByID(backupConfig.DatabaseID)
+		if err != nil {
+			return nil, err
+		}
+
+		if database.Postgresql != nil && database.Postgresql.HasExclusions() {
+			return nil, ErrWholeServerBackupExclusions
+		}
+	}
+
+	// 0 comes from clients unaware of the setting, retention
+	// removing every backup is never intended
+	if backupConfig.MinKeepCount == 0 {
+		backupConfig.MinKeepCount = DefaultMinKeepCount
+	}
+
+	// retries without delay hammer the database under maintenance
+	if backupConfig.RetryDelaySeconds == 0 {
+		backupConfig.RetryDelaySeconds = DefaultRetryDelaySeconds
+	}
+
+	if backupConfig.RetryMaxDelaySeconds == 0 {
+		backupConfig.RetryMaxDelaySeconds = max(
+			DefaultRetryMaxDelaySeconds,
+			backupConfig.RetryDelaySeconds,
+		)
+	}
+
+	if backupConfig.RetryJitterPercent == 0 {
+		backupConfig.RetryJitterPercent = DefaultRetryJitterPercent
+	}
+
+	if backupConfig.Compression == "" {
+		backupConfig.Compression = GetDefaultCompression(version)
+
+		if backupConfig.CompressionLevel == 0 {
+			backupConfig.CompressionLevel = DefaultCompressionLevel
+		}
+
+		// level is validated only when compression is set
+		if err := backupConfig.validateCompressionLevel(); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := backupConfig.ValidateCompressionForVersion(version); err != nil {
+		return nil, err
+	}
+
 	// Check if there's an existing backup config for this database
 	existingConfig, err := s.GetBackupConfigByDbId(backupConfig.DatabaseID)
 	if err != nil {
@@ -156,7 +238,12 @@ func (s *BackupConfigService) initializeDefaultConfig(
 ) error {
 	timeOfDay := "04:00"
 
-	_, err := s.backupConfigRepository.Save(&BackupConfig{
+	version, err := s.getDatabaseVersion(databaseID)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.backupConfigRepository.Save(&BackupConfig{
 		DatabaseID:       databaseID,
 		IsBackupsEnabled: false,
 		StorePeriod:      period.PeriodWeek,
@@ -167,16 +254,44 @@ func (s *BackupConfigService) initializeDefaultConfig(
 		SendNotificationsOn: []BackupNotificationType{
 			NotificationBackupFailed,
 			NotificationBackupSuccess,
+			NotificationBackupAnomaly,
 		},
 		CpuCount:            1,
 		IsRetryIfFailed:     true,
 		MaxFailedTriesCount: 3,
 		Encryption:          BackupEncryptionNone,
+		BackupMethod:        BackupMethodPgDump,
+		Format:              BackupFormatCustom,
+		Compression:         GetDefaultCompression(version),
+		CompressionLevel:    DefaultCompressionLevel,
+		MinKeepCount:        DefaultMinKeepCount,
+
+		SizeAnomalyThresholdPercent:     DefaultSizeAnomalyThresholdPercent,
+		DurationAnomalyThresholdPercent: DefaultDurationAnomalyThresholdPercent,
+
+		RetryDelaySeconds:    DefaultRetryDelaySeconds,
+		RetryMaxDelaySeconds: DefaultRetryMaxDelaySeconds,
+		RetryJitterPercent:   DefaultRetryJitterPercent,
 	})
 
 	return err
 }
 
+func (s *BackupConfigService) getDatabaseVersion(
+	databaseID uuid.UUID,
+) (tools.PostgresqlVersion, error) {
+	database, err := s.databaseService.GetDatabaseByID(databaseID)
+	if err != nil {
+		return "", err
+	}
+
+	if database.Postgresql == nil {
+		return "", nil
+	}
+
+	return database.Postgresql.Version, nil
+}
+
 func storageIDsEqual(id1, id2 *uuid.UUID) bool {
 	if id1 == nil && id2 == nil {
 		return true
@@ -0,0 +1,224 @@
+package backups_wal
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"postgresus-backend/internal/config"
+	"postgresus-backend/internal/features/backups/backups"
+	backups_config "postgresus-backend/internal/features/backups/config"
+	"postgresus-backend/internal/features/databases"
+
+	"github.com/google/uuid"
+)
+
+type WalArchivingBackgroundService struct {
+	walArchiveService   *WalArchiveService
+	walStreamersManager *WalStreamersManager
+	walReceiver         *PostgresqlWalReceiver
+	backupConfigService *backups_config.BackupConfigService
+	backupService       *backups.BackupService
+	databaseService     *databases.DatabaseService
+	logger              *slog.Logger
+}
+
+func (s *WalArchivingBackgroundService) Run() {
+	// connects to every database, so it does not delay archiving
+	go s.dropUnusedReplicationSlots()
+
+	for {
+		if config.IsShouldShutdown() {
+			s.walStreamersManager.StopAll()
+			return
+		}
+
+		archivingConfigs, err := s.getWalArchivingConfigs()
+		if err != nil {
+			s.logger.Error("Failed to get WAL archiving configs", "error", err)
+		} else {
+			s.syncStreamers(archivingConfigs)
+
+			for _, backupConfig := range archivingConfigs {
+				if err := s.uploadCompletedSegments(backupConfig); err != nil {
+					s.logger.Error(
+						"Failed to upload WAL segments",
+						"databaseId",
+						backupConfig.DatabaseID,
+						"error",
+						err,
+					)
+				}
+
+				if err := s.cleanObsoleteSegments(backupConfig.DatabaseID); err != nil {
+					s.logger.Error(
+						"Failed to clean obsolete WAL segments",
+						"databaseId",
+						backupConfig.DatabaseID,
+						"error",
+						err,
+					)
+				}
+			}
+		}
+
+		time.Sleep(10 * time.Second)
+	}
+}
+
+// dropUnusedReplicationSlots drops slots of databases without archiving.
+// Streamers are kept in memory only, so archiving turned off while
+// Postgresus was stopped leaves the slot holding WAL on the server
+func (s *WalArchivingBackgroundService) dropUnusedReplicationSlots() {
+	allDatabases, err := s.databaseService.GetAllDatabases()
+	if err != nil {
+		s.logger.Error("Failed to get databases", "error", err)
+		return
+	}
+
+	for _, database := range allDatabases {
+		if config.IsShouldShutdown() {
+			return
+		}
+
+		if database.Type != databases.DatabaseTypePostgres || database.Postgresql == nil {
+			continue
+		}
+
+		// archiving may be turned on while other databases are checked
+		archivingConfigs, err := s.getWalArchivingConfigs()
+		if err != nil {
+			s.logger.Error("Failed to get WAL archiving configs", "error", err)
+			return
+		}
+
+		isArchiving := slices.ContainsFunc(
+			archivingConfigs,
+			func(backupConfig *backups_config.BackupConfig) bool {
+				return backupConfig.DatabaseID == database.ID
+			},
+		)
+		if !isArchiving {
+			s.walArchiveService.dropReplicationSlot(database)
+		}
+	}
+}
+
+func (s *WalArchivingBackgroundService) getWalArchivingConfigs() (
+	[]*backups_config.BackupConfig,
+	error,
+) {
+	enabledBackupConfigs, err := s.backupConfigService.GetBackupConfigsWithEnabledBackups()
+	if err != nil {
+		return nil, err
+	}
+
+	archivingConfigs := make([]*backups_config.BackupConfig, 0)
+	for _, backupConfig := range enabledBackupConfigs {
+		if backupConfig.IsWalArchivingEnabled && backupConfig.StorageID != nil {
+			archivingConfigs = append(archivingConfigs, backupConfig)
+		}
+	}
+
+	return archivingConfigs, nil
+}
+
+func (s *WalArchivingBackgroundService) syncStreamers(
+	archivingConfigs []*backups_config.BackupConfig,
+) {
+	archivingDatabaseIDs := make([]uuid.UUID, 0, len(archivingConfigs))
+
+	for _, backupConfig := range archivingConfigs {
+		archivingDatabaseIDs = append(archivingDatabaseIDs, backupConfig.DatabaseID)
+
+		if s.walStreamersManager.IsStreaming(backupConfig.DatabaseID) {
+			continue
+		}
+
+		database, err := s.databaseService.GetDatabaseByID(backupConfig.DatabaseID)
+		if err != nil {
+			s.logger.Error(
+				"Failed to get database by ID",
+				"databaseId",
+				backupConfig.DatabaseID,
+				"error",
+				err,
+			)
+			continue
+		}
+
+		walDirectory := GetWalDirectory(database.ID)
+		if err := os.MkdirAll(walDirectory, 0700); err != nil {
+			s.logger.Error("Failed to create WAL directory", "directory", walDirectory, "error", err)
+			continue
+		}
+
+		s.walStreamersManager.Start(database.ID, func(ctx context.Context) error {
+			// role may lose the attribute after archiving is turned on
+			if err := s.databaseService.CheckReplicationAllowed(database); err != nil {
+				return err
+			}
+
+			return s.walReceiver.Receive(ctx, database, walDirectory)
+		})
+	}
+
+	for _, databaseID := range s.walStreamersManager.GetStartedDatabaseIDs() {
+		if !slices.Contains(archivingDatabaseIDs, databaseID) {
+			s.walArchiveService.stopStreaming(databaseID)
+		}
+	}
+}
+
+func (s *WalArchivingBackgroundService) uploadCompletedSegments(
+	backupConfig *backups_config.BackupConfig,
+) error {
+	walDirectory := GetWalDirectory(backupConfig.DatabaseID)
+
+	entries, err := os.ReadDir(walDirectory)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+
+		return err
+	}
+
+	fileNames := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		if !entry.IsDir() && IsWalFileName(entry.Name()) {
+			fileNames = append(fileNames, entry.Name())
+		}
+	}
+
+	// upload in WAL order, so archive never has gaps before the last segment
+	slices.Sort(fileNames)
+
+	for _, fileName := range fileNames {
+		if err := s.walArchiveService.ArchiveSegmentFile(
+			backupConfig,
+			filepath.Join(walDirectory, fileName),
+		); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *WalArchivingBackgroundService) cleanObsoleteSegments(databaseID uuid.UUID) error {
+	oldestBackup, err := s.backupService.GetOldestCompletedBackup(databaseID)
+	if err != nil {
+		return err
+	}
+
+	// without any backup WAL is still needed for the first one
+	if oldestBackup == nil {
+		return nil
+	}
+
+	return s.walArchiveService.DeleteSegmentsBefore(databaseID, oldestBackup.CreatedAt)
+}
@@ -0,0 +1,57 @@
+package backups_wal
+
+import (
+	"net/http"
+
+	users_middleware "postgresus-backend/internal/features/users/middleware"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type WalArchiveController struct {
+	walArchiveService *WalArchiveService
+}
+
+func (c *WalArchiveController) RegisterRoutes(router *gin.RouterGroup) {
+	router.GET("/wal-archive/status", c.GetWalArchiveStatus)
+}
+
+// GetWalArchiveStatus
+// @Summary Get WAL archive status
+// @Description Get WAL streaming state and archived WAL range for a database
+// @Tags wal-archive
+// @Produce json
+// @Param database_id query string true "Database ID"
+// @Success 200 {object} WalArchiveStatus
+// @Failure 400
+// @Failure 401
+// @Failure 500
+// @Router /wal-archive/status [get]
+func (c *WalArchiveController) GetWalArchiveStatus(ctx *gin.Context) {
+	user, ok := users_middleware.GetUserFromContext(ctx)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	var request GetWalArchiveStatusRequest
+	if err := ctx.ShouldBindQuery(&request); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	databaseID, err := uuid.Parse(request.DatabaseID)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid database_id"})
+		return
+	}
+
+	status, err := c.walArchiveService.GetWalArchiveStatus(user, databaseID)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, status)
+}
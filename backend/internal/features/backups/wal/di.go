@@ -0,0 +1,64 @@
+package backups_wal
+
+import (
+	"postgresus-backend/internal/features/backups/backups"
+	backups_config "postgresus-backend/internal/features/backups/config"
+	"postgresus-backend/internal/features/databases"
+	encryption_secrets "postgresus-backend/internal/features/encryption/secrets"
+	"postgresus-backend/internal/features/storages"
+	workspaces_services "postgresus-backend/internal/features/workspaces/services"
+	"postgresus-backend/internal/util/encryption"
+	"postgresus-backend/internal/util/logger"
+)
+
+var walSegmentRepository = &WalSegmentRepository{}
+
+var walStreamersManager = NewWalStreamersManager()
+
+var walReceiver = &PostgresqlWalReceiver{
+	logger.GetLogger(),
+	encryption.GetFieldEncryptor(),
+}
+
+var walArchiveService = &WalArchiveService{
+	walSegmentRepository,
+	walStreamersManager,
+	walReceiver,
+	databases.GetDatabaseService(),
+	storages.GetStorageService(),
+	backups_config.GetBackupConfigService(),
+	workspaces_services.GetWorkspaceService(),
+	encryption_secrets.GetSecretKeyService(),
+	encryption.GetFieldEncryptor(),
+	logger.GetLogger(),
+}
+
+var walArchivingBackgroundService = &WalArchivingBackgroundService{
+	walArchiveService,
+	walStreamersManager,
+	walReceiver,
+	backups_config.GetBackupConfigService(),
+	backups.GetBackupService(),
+	databases.GetDatabaseService(),
+	logger.GetLogger(),
+}
+
+var walArchiveController = &WalArchiveController{
+	walArchiveService,
+}
+
+func SetupDependencies() {
+	databases.GetDatabaseService().AddDbRemoveListener(walArchiveService)
+}
+
+func GetWalArchiveService() *WalArchiveService {
+	return walArchiveService
+}
+
+func GetWalArchivingBackgroundService() *WalArchivingBackgroundService {
+	return walArchivingBackgroundService
+}
+
+func GetWalArchiveController() *WalArchiveController {
+	return walArchiveController
+}
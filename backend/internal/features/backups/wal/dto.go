@@ -0,0 +1,20 @@
+package backups_wal
+
+import (
+	"time"
+)
+
+type GetWalArchiveStatusRequest struct {
+	DatabaseID string `form:"database_id" binding:"required"`
+}
+
+type WalArchiveStatus struct {
+	IsEnabled   bool    `json:"isEnabled"`
+	IsStreaming bool    `json:"isStreaming"`
+	LastError   *string `json:"lastError"`
+
+	SegmentsCount int64      `json:"segmentsCount"`
+	SizeMb        float64    `json:"sizeMb"`
+	FirstWalTime  *time.Time `json:"firstWalTime"`
+	LastWalTime   *time.Time `json:"lastWalTime"`
+}
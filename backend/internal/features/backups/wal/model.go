@@ -0,0 +1,30 @@
+package backups_wal
+
+import (
+	backups_config "postgresus-backend/internal/features/backups/config"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type WalSegment struct {
+	ID uuid.UUID `json:"id" gorm:"column:id;type:uuid;primaryKey"`
+
+	DatabaseID uuid.UUID `json:"databaseId" gorm:"column:database_id;type:uuid;not null"`
+	StorageID  uuid.UUID `json:"storageId"  gorm:"column:storage_id;type:uuid;not null"`
+
+	// name of the segment (or timeline history file) as produced by
+	// PostgreSQL, restore_command requests files by this name
+	FileName  string `json:"fileName"  gorm:"column:file_name;type:text;not null"`
+	SizeBytes int64  `json:"sizeBytes" gorm:"column:size_bytes;not null;default:0"`
+
+	EncryptionSalt *string                         `json:"-"          gorm:"column:encryption_salt"`
+	EncryptionIV   *string                         `json:"-"          gorm:"column:encryption_iv"`
+	Encryption     backups_config.BackupEncryption `json:"encryption" gorm:"column:encryption;type:text;not null;default:'NONE'"`
+
+	// SHA-256 of the stored file, segments archived before
+	// checksums were introduced have no checksum
+	Checksum *string `json:"checksum" gorm:"column:checksum;type:text"`
+
+	CreatedAt time.Time `json:"createdAt" gorm:"column:created_at"`
+}
@@ -0,0 +1,142 @@
+package backups_wal
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// used when size of archived segments is unknown, it is
+// the default --wal-segsize of initdb
+const defaultWalSegmentSizeBytes = 16 * 1024 * 1024
+
+var (
+	walSegmentNameRegex = regexp.MustCompile(`^[0-9A-F]{24}$`)
+	lsnRegex            = regexp.MustCompile(`^([0-9A-Fa-f]{1,8})/([0-9A-Fa-f]{1,8})$`)
+)
+
+// selectRecoverySegments returns WAL segments needed to replay the base
+// backup which starts in startWalFileName. Segments are compared by
+// position in WAL, segments of the start and later timelines are
+// included. With target LSN segments after the one containing
+// the target are not needed. History files are always included
+func selectRecoverySegments(
+	segments []*WalSegment,
+	startWalFileName string,
+	targetLsn *string,
+) ([]*WalSegment, error) {
+	if !walSegmentNameRegex.MatchString(startWalFileName) {
+		return nil, fmt.Errorf("invalid start WAL file name of the base backup: %s", startWalFileName)
+	}
+
+	startTimeline := startWalFileName[:8]
+	startPosition := startWalFileName[8:]
+
+	var targetPosition *string
+	if targetLsn != nil {
+		position, err := getLsnSegmentPosition(*targetLsn, getWalSegmentSizeBytes(segments))
+		if err != nil {
+			return nil, err
+		}
+
+		if position < startPosition {
+			return nil, errors.New("recovery target LSN is before the start of the base backup")
+		}
+
+		targetPosition = &position
+	}
+
+	walSegments := []*WalSegment{}
+	historyFiles := []*WalSegment{}
+	isStartSegmentArchived := false
+	lastPosition := ""
+
+	for _, segment := range segments {
+		if isHistoryFile(segment.FileName) {
+			historyFiles = append(historyFiles, segment)
+			continue
+		}
+
+		if !walSegmentNameRegex.MatchString(segment.FileName) {
+			continue
+		}
+
+		timeline := segment.FileName[:8]
+		position := segment.FileName[8:]
+
+		if timeline < startTimeline || position < startPosition {
+			continue
+		}
+
+		if targetPosition != nil && position > *targetPosition {
+			continue
+		}
+
+		if position == startPosition {
+			isStartSegmentArchived = true
+		}
+
+		lastPosition = max(lastPosition, position)
+		walSegments = append(walSegments, segment)
+	}
+
+	if !isStartSegmentArchived {
+		return nil, fmt.Errorf(
+			"WAL segment %s needed by the base backup is not archived, cannot recover to point in time",
+			startWalFileName,
+		)
+	}
+
+	if targetPosition != nil && lastPosition < *targetPosition {
+		return nil, errors.New("recovery target LSN is after the latest archived WAL")
+	}
+
+	slices.SortFunc(walSegments, func(a, b *WalSegment) int {
+		return strings.Compare(a.FileName, b.FileName)
+	})
+
+	return append(walSegments, historyFiles...), nil
+}
+
+// getLsnSegmentPosition returns the part of the segment file name after
+// the timeline for the segment containing the LSN in format X/Y
+func getLsnSegmentPosition(lsn string, segmentSizeBytes int64) (string, error) {
+	match := lsnRegex.FindStringSubmatch(lsn)
+	if match == nil {
+		return "", fmt.Errorf("invalid LSN: %s", lsn)
+	}
+
+	high, err := strconv.ParseUint(match[1], 16, 32)
+	if err != nil {
+		return "", fmt.Errorf("invalid LSN: %s", lsn)
+	}
+
+	low, err := strconv.ParseUint(match[2], 16, 32)
+	if err != nil {
+		return "", fmt.Errorf("invalid LSN: %s", lsn)
+	}
+
+	segmentNumber := (high<<32 | low) / uint64(segmentSizeBytes)
+	segmentsPerXLogID := uint64(0x100000000) / uint64(segmentSizeBytes)
+
+	return fmt.Sprintf(
+		"%08X%08X",
+		segmentNumber/segmentsPerXLogID,
+		segmentNumber%segmentsPerXLogID,
+	), nil
+}
+
+// archived segments are complete, so their size is the
+// wal_segment_size of the server
+func getWalSegmentSizeBytes(segments []*WalSegment) int64 {
+	for _, segment := range segments {
+		if !isHistoryFile(segment.FileName) && segment.SizeBytes > 0 {
+			return segment.SizeBytes
+		}
+	}
+
+	return defaultWalSegmentSizeBytes
+}
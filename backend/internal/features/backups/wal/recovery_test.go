@@ -0,0 +1,120 @@
+package backups_wal
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_SelectRecoverySegments_SegmentsFromBackupStartReturnedInOrder(t *testing.T) {
+	segments := []*WalSegment{
+		createTestWalSegment("000000010000000000000004"),
+		createTestWalSegment("000000010000000000000001"),
+		createTestWalSegment("00000002.history"),
+		createTestWalSegment("000000010000000000000002"),
+		createTestWalSegment("000000010000000000000003"),
+		createTestWalSegment("000000020000000000000004"),
+	}
+
+	selectedSegments, err := selectRecoverySegments(segments, "000000010000000000000002", nil)
+	assert.NoError(t, err)
+
+	assert.Equal(t, []string{
+		"000000010000000000000002",
+		"000000010000000000000003",
+		"000000010000000000000004",
+		"000000020000000000000004",
+		"00000002.history",
+	}, getTestWalFileNames(selectedSegments))
+}
+
+func Test_SelectRecoverySegments_WithTargetLsn_SegmentsAfterTargetSkipped(t *testing.T) {
+	segments := []*WalSegment{
+		createTestWalSegment("000000010000000000000002"),
+		createTestWalSegment("000000010000000000000003"),
+		createTestWalSegment("000000010000000000000004"),
+	}
+
+	// 0/3000060 is in the third 16 MB segment
+	targetLsn := "0/3000060"
+
+	selectedSegments, err := selectRecoverySegments(segments, "000000010000000000000002", &targetLsn)
+	assert.NoError(t, err)
+
+	assert.Equal(t, []string{
+		"000000010000000000000002",
+		"000000010000000000000003",
+	}, getTestWalFileNames(selectedSegments))
+}
+
+func Test_SelectRecoverySegments_StartSegmentNotArchived_ErrorReturned(t *testing.T) {
+	segments := []*WalSegment{
+		createTestWalSegment("000000010000000000000003"),
+		createTestWalSegment("000000010000000000000004"),
+	}
+
+	_, err := selectRecoverySegments(segments, "000000010000000000000002", nil)
+	assert.ErrorContains(t, err, "000000010000000000000002")
+}
+
+func Test_SelectRecoverySegments_TargetLsnAfterArchive_ErrorReturned(t *testing.T) {
+	segments := []*WalSegment{
+		createTestWalSegment("000000010000000000000002"),
+		createTestWalSegment("000000010000000000000003"),
+	}
+
+	targetLsn := "0/5000000"
+
+	_, err := selectRecoverySegments(segments, "000000010000000000000002", &targetLsn)
+	assert.ErrorContains(t, err, "after the latest archived WAL")
+}
+
+func Test_SelectRecoverySegments_TargetLsnBeforeBackupStart_ErrorReturned(t *testing.T) {
+	segments := []*WalSegment{
+		createTestWalSegment("000000010000000000000002"),
+	}
+
+	targetLsn := "0/1000000"
+
+	_, err := selectRecoverySegments(segments, "000000010000000000000002", &targetLsn)
+	assert.ErrorContains(t, err, "before the start of the base backup")
+}
+
+func Test_GetLsnSegmentPosition_PositionMatchesSegmentFileName(t *testing.T) {
+	cases := []struct {
+		lsn              string
+		segmentSizeBytes int64
+		position         string
+	}{
+		{"0/2000028", 16 * 1024 * 1024, "0000000000000002"},
+		{"1/FF000000", 16 * 1024 * 1024, "00000001000000FF"},
+		{"A/1C000000", 64 * 1024 * 1024, "0000000A00000007"},
+	}
+
+	for _, c := range cases {
+		position, err := getLsnSegmentPosition(c.lsn, c.segmentSizeBytes)
+		assert.NoError(t, err)
+		assert.Equal(t, c.position, position, c.lsn)
+	}
+
+	_, err := getLsnSegmentPosition("not-lsn", defaultWalSegmentSizeBytes)
+	assert.Error(t, err)
+}
+
+func createTestWalSegment(fileName string) *WalSegment {
+	segment := &WalSegment{FileName: fileName}
+	if !isHistoryFile(fileName) {
+		segment.SizeBytes = defaultWalSegmentSizeBytes
+	}
+
+	return segment
+}
+
+func getTestWalFileNames(segments []*WalSegment) []string {
+	fileNames := []string{}
+	for _, segment := range segments {
+		fileNames = append(fileNames, segment.FileName)
+	}
+
+	return fileNames
+}
@@ -0,0 +1,134 @@
+package backups_wal
+
+import (
+	"errors"
+	"postgresus-backend/internal/storage"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type WalSegmentRepository struct{}
+
+func (r *WalSegmentRepository) Save(segment *WalSegment) error {
+	if segment.DatabaseID == uuid.Nil || segment.StorageID == uuid.Nil {
+		return errors.New("database ID and storage ID are required")
+	}
+
+	db := storage.GetDb()
+
+	isNew := segment.ID == uuid.Nil
+	if isNew {
+		segment.ID = uuid.New()
+		return db.Create(segment).
+			Error
+	}
+
+	return db.Save(segment).
+		Error
+}
+
+func (r *WalSegmentRepository) FindByDatabaseID(databaseID uuid.UUID) ([]*WalSegment, error) {
+	var segments []*WalSegment
+
+	if err := storage.
+		GetDb().
+		Where("database_id = ?", databaseID).
+		Order("created_at ASC").
+		Find(&segments).Error; err != nil {
+		return nil, err
+	}
+
+	return segments, nil
+}
+
+func (r *WalSegmentRepository) FindByDatabaseIDAndFileName(
+	databaseID uuid.UUID,
+	fileName string,
+) (*WalSegment, error) {
+	var segment WalSegment
+
+	if err := storage.
+		GetDb().
+		Where("database_id = ? AND file_name = ?", databaseID, fileName).
+		First(&segment).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	return &segment, nil
+}
+
+func (r *WalSegmentRepository) FindByDatabaseIDCreatedBefore(
+	databaseID uuid.UUID,
+	date time.Time,
+) ([]*WalSegment, error) {
+	var segments []*WalSegment
+
+	if err := storage.
+		GetDb().
+		Where("database_id = ? AND created_at < ?", databaseID, date).
+		Order("created_at ASC").
+		Find(&segments).Error; err != nil {
+		return nil, err
+	}
+
+	return segments, nil
+}
+
+func (r *WalSegmentRepository) FindFirstByDatabaseID(databaseID uuid.UUID) (*WalSegment, error) {
+	return r.findEdgeByDatabaseID(databaseID, "created_at ASC")
+}
+
+func (r *WalSegmentRepository) FindLastByDatabaseID(databaseID uuid.UUID) (*WalSegment, error) {
+	return r.findEdgeByDatabaseID(databaseID, "created_at DESC")
+}
+
+func (r *WalSegmentRepository) GetStatsByDatabaseID(
+	databaseID uuid.UUID,
+) (count int64, sizeBytes int64, err error) {
+	var stats struct {
+		Count     int64
+		SizeBytes int64
+	}
+
+	if err := storage.
+		GetDb().
+		Model(&WalSegment{}).
+		Select("COUNT(*) AS count, COALESCE(SUM(size_bytes), 0) AS size_bytes").
+		Where("database_id = ?", databaseID).
+		Scan(&stats).Error; err != nil {
+		return 0, 0, err
+	}
+
+	return stats.Count, stats.SizeBytes, nil
+}
+
+func (r *WalSegmentRepository) DeleteByID(id uuid.UUID) error {
+	return storage.GetDb().Delete(&WalSegment{}, "id = ?", id).Error
+}
+
+func (r *WalSegmentRepository) findEdgeByDatabaseID(
+	databaseID uuid.UUID,
+	order string,
+) (*WalSegment, error) {
+	var segment WalSegment
+
+	if err := storage.
+		GetDb().
+		Where("database_id = ?", databaseID).
+		Order(order).
+		First(&segment).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	return &segment, nil
+}
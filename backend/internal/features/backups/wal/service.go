@@ -0,0 +1,387 @@
+package backups_wal
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"postgresus-backend/internal/config"
+	"postgresus-backend/internal/features/backups/backups/encryption"
+	backups_config "postgresus-backend/internal/features/backups/config"
+	"postgresus-backend/internal/features/databases"
+	encryption_secrets "postgresus-backend/internal/features/encryption/secrets"
+	"postgresus-backend/internal/features/storages"
+	users_models "postgresus-backend/internal/features/users/models"
+	workspaces_services "postgresus-backend/internal/features/workspaces/services"
+	"postgresus-backend/internal/util/checksum"
+	util_encryption "postgresus-backend/internal/util/encryption"
+
+	"github.com/google/uuid"
+)
+
+const dropReplicationSlotTimeout = 30 * time.Second
+
+// completed WAL segments and timeline history files, pg_receivewal
+// keeps the segment it is currently writing with .partial suffix
+var walFileNameRegex = regexp.MustCompile(`^([0-9A-F]{24}|[0-9A-F]{8}\.history)$`)
+
+type WalArchiveService struct {
+	walSegmentRepository *WalSegmentRepository
+	walStreamersManager  *WalStreamersManager
+	walReceiver          *PostgresqlWalReceiver
+	databaseService      *databases.DatabaseService
+	storageService       *storages.StorageService
+	backupConfigService  *backups_config.BackupConfigService
+	workspaceService     *workspaces_services.WorkspaceService
+	secretKeyService     *encryption_secrets.SecretKeyService
+	fieldEncryptor       util_encryption.FieldEncryptor
+	logger               *slog.Logger
+}
+
+func (s *WalArchiveService) OnBeforeDatabaseRemove(databaseID uuid.UUID) error {
+	s.stopStreaming(databaseID)
+
+	if err := os.RemoveAll(GetWalDirectory(databaseID)); err != nil {
+		s.logger.Error("Failed to remove WAL directory", "databaseId", databaseID, "error", err)
+	}
+
+	segments, err := s.walSegmentRepository.FindByDatabaseID(databaseID)
+	if err != nil {
+		return err
+	}
+
+	for _, segment := range segments {
+		if err := s.deleteSegment(segment); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *WalArchiveService) GetWalArchiveStatus(
+	user *users_models.User,
+	databaseID uuid.UUID,
+) (*WalArchiveStatus, error) {
+	database, err := s.databaseService.GetDatabaseByID(databaseID)
+	if err != nil {
+		return nil, err
+	}
+
+	if database.WorkspaceID == nil {
+		return nil, errors.New("cannot get WAL archive for database without workspace")
+	}
+
+	canAccess, _, err := s.workspaceService.CanUserAccessWorkspace(*database.WorkspaceID, user)
+	if err != nil {
+		return nil, err
+	}
+	if !canAccess {
+		return nil, errors.New("insufficient permissions to access WAL archive for this database")
+	}
+
+	backupConfig, err := s.backupConfigService.GetBackupConfigByDbId(databaseID)
+	if err != nil {
+		return nil, err
+	}
+
+	segmentsCount, sizeBytes, err := s.walSegmentRepository.GetStatsByDatabaseID(databaseID)
+	if err != nil {
+		return nil, err
+	}
+
+	status := &WalArchiveStatus{
+		IsEnabled:     backupConfig.IsBackupsEnabled && backupConfig.IsWalArchivingEnabled,
+		IsStreaming:   s.walStreamersManager.IsStreaming(databaseID),
+		LastError:     s.walStreamersManager.GetLastError(databaseID),
+		SegmentsCount: segmentsCount,
+		SizeMb:        float64(sizeBytes) / (1024 * 1024),
+	}
+
+	firstSegment, err := s.walSegmentRepository.FindFirstByDatabaseID(databaseID)
+	if err != nil {
+		return nil, err
+	}
+	if firstSegment != nil {
+		status.FirstWalTime = &firstSegment.CreatedAt
+	}
+
+	lastSegment, err := s.walSegmentRepository.FindLastByDatabaseID(databaseID)
+	if err != nil {
+		return nil, err
+	}
+	if lastSegment != nil {
+		status.LastWalTime = &lastSegment.CreatedAt
+	}
+
+	return status, nil
+}
+
+// GetSegmentsForRecovery returns WAL needed to replay the base backup
+// starting in startWalFileName up to target LSN or, when it is not set,
+// up to the end of archive. Recovery to target time stops by itself,
+// so all the following WAL is returned for it
+func (s *WalArchiveService) GetSegmentsForRecovery(
+	databaseID uuid.UUID,
+	startWalFileName string,
+	targetTime *time.Time,
+	targetLsn *string,
+) ([]*WalSegment, error) {
+	segments, err := s.walSegmentRepository.FindByDatabaseID(databaseID)
+	if err != nil {
+		return nil, err
+	}
+
+	walSegments, err := selectRecoverySegments(segments, startWalFileName, targetLsn)
+	if err != nil {
+		return nil, err
+	}
+
+	// segment is uploaded only after it is filled, so WAL containing
+	// the target time cannot be archived before the target
+	if targetTime != nil {
+		lastSegment, err := s.walSegmentRepository.FindLastByDatabaseID(databaseID)
+		if err != nil {
+			return nil, err
+		}
+
+		if lastSegment == nil || lastSegment.CreatedAt.Before(*targetTime) {
+			return nil, errors.New("recovery target time is after the latest archived WAL")
+		}
+	}
+
+	return walSegments, nil
+}
+
+// ArchiveSegmentFile uploads the completed segment file to the
+// database storage and removes the local copy
+func (s *WalArchiveService) ArchiveSegmentFile(
+	backupConfig *backups_config.BackupConfig,
+	filePath string,
+) error {
+	fileName := filepath.Base(filePath)
+
+	existingSegment, err := s.walSegmentRepository.FindByDatabaseIDAndFileName(
+		backupConfig.DatabaseID,
+		fileName,
+	)
+	if err != nil {
+		return err
+	}
+
+	// pg_receivewal may receive the segment again if it was restarted
+	// before local copy had been removed
+	if existingSegment != nil {
+		return os.Remove(filePath)
+	}
+
+	if backupConfig.StorageID == nil {
+		return errors.New("backup config storage ID is not defined")
+	}
+
+	storage, err := s.storageService.GetStorageByID(*backupConfig.StorageID)
+	if err != nil {
+		return err
+	}
+
+	fileInfo, err := os.Stat(filePath)
+	if err != nil {
+		return err
+	}
+
+	segment := &WalSegment{
+		ID:         uuid.New(),
+		DatabaseID: backupConfig.DatabaseID,
+		StorageID:  storage.ID,
+		FileName:   fileName,
+		SizeBytes:  fileInfo.Size(),
+		Encryption: backups_config.BackupEncryptionNone,
+		CreatedAt:  time.Now().UTC(),
+	}
+
+	if err := s.uploadSegmentFile(backupConfig, storage, segment, filePath); err != nil {
+		return fmt.Errorf("failed to upload WAL segment %s: %w", fileName, err)
+	}
+
+	if err := s.walSegmentRepository.Save(segment); err != nil {
+		return err
+	}
+
+	return os.Remove(filePath)
+}
+
+// DeleteSegmentsBefore removes WAL that cannot be replayed anymore
+// because there is no backup taken before it
+func (s *WalArchiveService) DeleteSegmentsBefore(databaseID uuid.UUID, date time.Time) error {
+	segments, err := s.walSegmentRepository.FindByDatabaseIDCreatedBefore(databaseID, date)
+	if err != nil {
+		return err
+	}
+
+	for _, segment := range segments {
+		if isHistoryFile(segment.FileName) {
+			continue
+		}
+
+		if err := s.deleteSegment(segment); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func GetWalDirectory(databaseID uuid.UUID) string {
+	return filepath.Join(config.GetEnv().WalFolder, databaseID.String())
+}
+
+func IsWalFileName(fileName string) bool {
+	return walFileNameRegex.MatchString(fileName)
+}
+
+// stopStreaming stops the streamer, if any, and drops the slot. The slot
+// is dropped even without running streamer: pg_receivewal may have exited
+// or Postgresus may have been restarted, but the slot still holds WAL
+func (s *WalArchiveService) stopStreaming(databaseID uuid.UUID) {
+	s.walStreamersManager.Stop(databaseID)
+
+	database, err := s.databaseService.GetDatabaseByID(databaseID)
+	if err != nil {
+		s.logger.Error("Failed to get database by ID", "databaseId", databaseID, "error", err)
+		return
+	}
+
+	s.dropReplicationSlot(database)
+}
+
+func (s *WalArchiveService) dropReplicationSlot(database *databases.Database) {
+	ctx, cancel := context.WithTimeout(context.Background(), dropReplicationSlotTimeout)
+	defer cancel()
+
+	isDropped, err := s.walReceiver.DropReplicationSlot(ctx, database)
+	if err != nil {
+		s.logger.Error(
+			"Failed to drop replication slot",
+			"databaseId",
+			database.ID,
+			"error",
+			err,
+		)
+		return
+	}
+
+	if isDropped {
+		s.logger.Info("Dropped replication slot", "databaseId", database.ID)
+	}
+}
+
+func (s *WalArchiveService) uploadSegmentFile(
+	backupConfig *backups_config.BackupConfig,
+	storage *storages.Storage,
+	segment *WalSegment,
+	filePath string,
+) error {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := file.Close(); err != nil {
+			s.logger.Error("Failed to close WAL segment file", "error", err)
+		}
+	}()
+
+	storageReader, pipeWriter := io.Pipe()
+	// checksum of the stored bytes, same as for backups
+	storageWriter := checksum.NewWriter(pipeWriter)
+
+	var segmentWriter io.WriteCloser = storageWriter
+	var salt, nonce []byte
+
+	if backupConfig.Encryption == backups_config.BackupEncryptionEncrypted {
+		salt, err = encryption.GenerateSalt()
+		if err != nil {
+			return fmt.Errorf("failed to generate salt: %w", err)
+		}
+
+		nonce, err = encryption.GenerateNonce()
+		if err != nil {
+			return fmt.Errorf("failed to generate nonce: %w", err)
+		}
+
+		masterKey, err := s.secretKeyService.GetSecretKey()
+		if err != nil {
+			return fmt.Errorf("failed to get master key: %w", err)
+		}
+
+		segmentWriter, err = encryption.NewEncryptionWriter(
+			storageWriter,
+			masterKey,
+			segment.ID,
+			salt,
+			nonce,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to create encrypting writer: %w", err)
+		}
+	}
+
+	go func() {
+		_, copyErr := io.Copy(segmentWriter, file)
+		if copyErr == nil {
+			copyErr = segmentWriter.Close()
+		}
+		pipeWriter.CloseWithError(copyErr)
+	}()
+
+	if err := storage.SaveFile(
+		context.Background(),
+		s.fieldEncryptor,
+		s.logger,
+		segment.ID,
+		storageReader,
+	); err != nil {
+		_ = storageReader.CloseWithError(err)
+		return err
+	}
+
+	segmentChecksum := storageWriter.GetChecksum()
+	segment.Checksum = &segmentChecksum
+
+	if backupConfig.Encryption == backups_config.BackupEncryptionEncrypted {
+		saltBase64 := base64.StdEncoding.EncodeToString(salt)
+		nonceBase64 := base64.StdEncoding.EncodeToString(nonce)
+		segment.EncryptionSalt = &saltBase64
+		segment.EncryptionIV = &nonceBase64
+		segment.Encryption = backups_config.BackupEncryptionEncrypted
+	}
+
+	return nil
+}
+
+func (s *WalArchiveService) deleteSegment(segment *WalSegment) error {
+	storage, err := s.storageService.GetStorageByID(segment.StorageID)
+	if err != nil {
+		return err
+	}
+
+	// same as for backups: storage may be unavailable, but
+	// the record should be removed anyway
+	if err := storage.DeleteFile(s.fieldEncryptor, segment.ID); err != nil {
+		s.logger.Error("Failed to delete WAL segment file", "segmentId", segment.ID, "error", err)
+	}
+
+	return s.walSegmentRepository.DeleteByID(segment.ID)
+}
+
+func isHistoryFile(fileName string) bool {
+	return strings.HasSuffix(fileName, ".history")
+}
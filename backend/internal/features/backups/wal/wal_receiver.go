@@ -0,0 +1,166 @@
+package backups_wal
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"postgresus-backend/internal/config"
+	"postgresus-backend/internal/features/databases"
+	"postgresus-backend/internal/util/encryption"
+	"postgresus-backend/internal/util/tools"
+
+	"github.com/google/uuid"
+)
+
+const (
+	replicationSlotPrefix = "postgresus_"
+	pgConnectTimeout      = 30
+)
+
+// PostgresqlWalReceiver streams WAL of the database via pg_receivewal.
+// Streaming uses a physical replication slot, so the server keeps
+// WAL that is not received yet (for example, while Postgresus restarts)
+type PostgresqlWalReceiver struct {
+	logger         *slog.Logger
+	fieldEncryptor encryption.FieldEncryptor
+}
+
+// Receive blocks until streaming is stopped via ctx or pg_receivewal exits
+func (r *PostgresqlWalReceiver) Receive(
+	ctx context.Context,
+	database *databases.Database,
+	walDirectory string,
+) error {
+	slotName := GetReplicationSlotName(database.ID)
+
+	err := r.runPgReceiveWal(
+		ctx,
+		database,
+		[]string{"--create-slot", "--if-not-exists", "--slot", slotName},
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create replication slot %s: %w", slotName, err)
+	}
+
+	r.logger.Info(
+		"Starting WAL streaming",
+		"databaseId",
+		database.ID,
+		"slot",
+		slotName,
+		"walDirectory",
+		walDirectory,
+	)
+
+	return r.runPgReceiveWal(
+		ctx,
+		database,
+		[]string{"-D", walDirectory, "--slot", slotName, "--no-loop"},
+	)
+}
+
+// DropReplicationSlot removes the slot, otherwise the server keeps
+// WAL for it forever and runs out of disk space. Missing slot is not
+// an error, so it is safe to call whenever archiving is not needed
+func (r *PostgresqlWalReceiver) DropReplicationSlot(
+	ctx context.Context,
+	database *databases.Database,
+) (bool, error) {
+	if database.Postgresql == nil {
+		return false, errors.New("postgresql database configuration is required for WAL archiving")
+	}
+
+	return database.Postgresql.DropReplicationSlotIfExists(
+		ctx,
+		r.logger,
+		r.fieldEncryptor,
+		database.ID,
+		GetReplicationSlotName(database.ID),
+	)
+}
+
+func GetReplicationSlotName(databaseID uuid.UUID) string {
+	return replicationSlotPrefix + strings.ReplaceAll(databaseID.String(), "-", "_")
+}
+
+func (r *PostgresqlWalReceiver) runPgReceiveWal(
+	ctx context.Context,
+	database *databases.Database,
+	args []string,
+) error {
+	pg := database.Postgresql
+	if pg == nil {
+		return errors.New("postgresql database configuration is required for WAL archiving")
+	}
+
+	password, err := r.fieldEncryptor.Decrypt(database.ID, pg.Password)
+	if err != nil {
+		return fmt.Errorf("failed to decrypt database password: %w", err)
+	}
+
+	pgpassFile, err := tools.CreateTempPgpassFile(pg.Host, pg.Port, pg.Username, password)
+	if err != nil {
+		return fmt.Errorf("failed to create temporary .pgpass file: %w", err)
+	}
+	defer func() {
+		if pgpassFile != "" {
+			_ = os.RemoveAll(filepath.Dir(pgpassFile))
+		}
+	}()
+
+	pgBin := tools.GetPostgresqlExecutable(
+		pg.Version,
+		"pg_receivewal",
+		config.GetEnv().EnvMode,
+		config.GetEnv().PostgresesInstallDir,
+	)
+
+	args = append(args,
+		"--no-password",
+		"-h", pg.Host,
+		"-p", strconv.Itoa(pg.Port),
+		"-U", pg.Username,
+	)
+
+	cmd := exec.CommandContext(ctx, pgBin, args...)
+	cmd.Env = append(os.Environ(),
+		"PGPASSFILE="+pgpassFile,
+		"PGCONNECT_TIMEOUT="+strconv.Itoa(pgConnectTimeout),
+		"PGSSLCERT=",
+		"PGSSLKEY=",
+		"PGSSLROOTCERT=",
+		"PGSSLCRL=",
+	)
+
+	if pg.IsHttps {
+		cmd.Env = append(cmd.Env, "PGSSLMODE=require")
+	} else {
+		cmd.Env = append(cmd.Env, "PGSSLMODE=prefer")
+	}
+
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() != nil {
+			return nil
+		}
+
+		return fmt.Errorf(
+			"%s failed: %w – stderr: %s",
+			filepath.Base(pgBin),
+			err,
+			strings.TrimSpace(stderr.String()),
+		)
+	}
+
+	return nil
+}
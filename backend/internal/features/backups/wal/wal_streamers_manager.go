@@ -0,0 +1,124 @@
+package backups_wal
+
+import (
+	"context"
+	"sync"
+
+	"github.com/google/uuid"
+)
+
+type walStreamer struct {
+	cancel context.CancelFunc
+}
+
+type WalStreamersManager struct {
+	mu         sync.RWMutex
+	streamers  map[uuid.UUID]*walStreamer
+	lastErrors map[uuid.UUID]string
+}
+
+func NewWalStreamersManager() *WalStreamersManager {
+	return &WalStreamersManager{
+		streamers:  make(map[uuid.UUID]*walStreamer),
+		lastErrors: make(map[uuid.UUID]string),
+	}
+}
+
+// Start runs stream in background unless the database is already streaming.
+// When stream returns, the database is considered not streaming anymore, so
+// the next sync will start it again
+func (m *WalStreamersManager) Start(
+	databaseID uuid.UUID,
+	stream func(ctx context.Context) error,
+) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, isRunning := m.streamers[databaseID]; isRunning {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	streamer := &walStreamer{cancel}
+	m.streamers[databaseID] = streamer
+
+	go func() {
+		err := stream(ctx)
+
+		m.mu.Lock()
+		defer m.mu.Unlock()
+
+		// the streamer may be already stopped and replaced by a new one
+		if m.streamers[databaseID] == streamer {
+			delete(m.streamers, databaseID)
+		}
+
+		if err != nil {
+			m.lastErrors[databaseID] = err.Error()
+		} else {
+			delete(m.lastErrors, databaseID)
+		}
+	}()
+}
+
+func (m *WalStreamersManager) Stop(databaseID uuid.UUID) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if streamer, isRunning := m.streamers[databaseID]; isRunning {
+		streamer.cancel()
+		delete(m.streamers, databaseID)
+	}
+
+	delete(m.lastErrors, databaseID)
+}
+
+func (m *WalStreamersManager) StopAll() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for databaseID, streamer := range m.streamers {
+		streamer.cancel()
+		delete(m.streamers, databaseID)
+	}
+}
+
+func (m *WalStreamersManager) IsStreaming(databaseID uuid.UUID) bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	_, isRunning := m.streamers[databaseID]
+	return isRunning
+}
+
+func (m *WalStreamersManager) GetLastError(databaseID uuid.UUID) *string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	lastError, isExists := m.lastErrors[databaseID]
+	if !isExists {
+		return nil
+	}
+
+	return &lastError
+}
+
+// GetStartedDatabaseIDs returns databases streaming now and databases
+// whose streamer exited with error, both may have replication slot
+func (m *WalStreamersManager) GetStartedDatabaseIDs() []uuid.UUID {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	databaseIDs := make([]uuid.UUID, 0, len(m.streamers)+len(m.lastErrors))
+	for databaseID := range m.streamers {
+		databaseIDs = append(databaseIDs, databaseID)
+	}
+
+	for databaseID := range m.lastErrors {
+		if _, isRunning := m.streamers[databaseID]; !isRunning {
+			databaseIDs = append(databaseIDs, databaseID)
+		}
+	}
+
+	return databaseIDs
+}
@@ -0,0 +1,30 @@
+package backups_wal
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_GetStartedDatabaseIDs_ExitedStreamer_DatabaseReturnedUntilStopped(t *testing.T) {
+	manager := NewWalStreamersManager()
+	databaseID := uuid.New()
+
+	manager.Start(databaseID, func(ctx context.Context) error {
+		return errors.New("pg_receivewal exited")
+	})
+
+	assert.Eventually(t, func() bool {
+		return manager.GetLastError(databaseID) != nil
+	}, time.Second, 10*time.Millisecond)
+
+	assert.False(t, manager.IsStreaming(databaseID))
+	assert.Equal(t, []uuid.UUID{databaseID}, manager.GetStartedDatabaseIDs())
+
+	manager.Stop(databaseID)
+	assert.Empty(t, manager.GetStartedDatabaseIDs())
+}
@@ -2,6 +2,7 @@ package postgresql
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"log/slog"
@@ -13,6 +14,7 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"gorm.io/gorm"
 )
 
@@ -34,6 +36,21 @@ type PostgresqlDatabase struct {
 	// backup settings
 	IncludeSchemas       []string `json:"includeSchemas" gorm:"-"`
 	IncludeSchemasString string   `json:"-"              gorm:"column:include_schemas;type:text;not null;default:''"`
+
+	// pg_dump patterns, passed as -N, -T and --exclude-table-data
+	ExcludeSchemas         []string `json:"excludeSchemas"   gorm:"-"`
+	ExcludeSchemasString   string   `json:"-"                gorm:"column:exclude_schemas;type:text;not null;default:''"`
+	ExcludeTables          []string `json:"excludeTables"    gorm:"-"`
+	ExcludeTablesString    string   `json:"-"                gorm:"column:exclude_tables;type:text;not null;default:''"`
+	ExcludeTableData       []string `json:"excludeTableData" gorm:"-"`
+	ExcludeTableDataString string   `json:"-"                gorm:"column:exclude_table_data;type:text;not null;default:''"`
+
+	// standbys to dump from instead of the primary, tried in order.
+	// Max lag 0 means DefaultMaxReplicaLagSeconds
+	Replicas              []*PostgresqlReplica  `json:"replicas"              gorm:"-"`
+	ReplicasString        string                `json:"-"                     gorm:"column:replicas;type:text;not null;default:'[]'"`
+	MaxReplicaLagSeconds  int                   `json:"maxReplicaLagSeconds"  gorm:"column:max_replica_lag_seconds;type:int;not null;default:0"`
+	ReplicaFallbackPolicy ReplicaFallbackPolicy `json:"replicaFallbackPolicy" gorm:"column:replica_fallback_policy;type:text;not null;default:'FALLBACK_TO_PRIMARY'"`
 }
 
 func (p *PostgresqlDatabase) TableName() string {
@@ -47,6 +64,31 @@ func (p *PostgresqlDatabase) BeforeSave(_ *gorm.DB) error {
 		p.IncludeSchemasString = ""
 	}
 
+	p.ExcludeSchemasString = strings.Join(p.ExcludeSchemas, ",")
+	p.ExcludeTablesString = strings.Join(p.ExcludeTables, ",")
+	p.ExcludeTableDataString = strings.Join(p.ExcludeTableData, ",")
+
+	if p.ReplicaFallbackPolicy == "" {
+		p.ReplicaFallbackPolicy = ReplicaFallbackPolicyPrimary
+	}
+
+	if p.Replicas == nil {
+		p.ReplicasString = "[]"
+		return nil
+	}
+
+	for _, replica := range p.Replicas {
+		if replica.ID == uuid.Nil {
+			replica.ID = uuid.New()
+		}
+	}
+
+	replicas, err := json.Marshal(p.Replicas)
+	if err != nil {
+		return err
+	}
+	p.ReplicasString = string(replicas)
+
 	return nil
 }
 
@@ -57,6 +99,17 @@ func (p *PostgresqlDatabase) AfterFind(_ *gorm.DB) error {
 		p.IncludeSchemas = []string{}
 	}
 
+	p.ExcludeSchemas = splitPatterns(p.ExcludeSchemasString)
+	p.ExcludeTables = splitPatterns(p.ExcludeTablesString)
+	p.ExcludeTableData = splitPatterns(p.ExcludeTableDataString)
+
+	p.Replicas = []*PostgresqlReplica{}
+	if p.ReplicasString != "" {
+		if err := json.Unmarshal([]byte(p.ReplicasString), &p.Replicas); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
@@ -77,9 +130,45 @@ func (p *PostgresqlDatabase) Validate() error {
 		return errors.New("password is required")
 	}
 
+	for _, patterns := range [][]string{p.ExcludeSchemas, p.ExcludeTables, p.ExcludeTableData} {
+		for _, pattern := range patterns {
+			if strings.TrimSpace(pattern) == "" {
+				return errors.New("exclude pattern cannot be empty")
+			}
+
+			// patterns are persisted as comma separated string
+			if strings.Contains(pattern, ",") {
+				return fmt.Errorf("exclude pattern cannot contain comma: %s", pattern)
+			}
+		}
+	}
+
+	for _, replica := range p.Replicas {
+		if err := replica.Validate(); err != nil {
+			return err
+		}
+	}
+
+	if p.MaxReplicaLagSeconds < 0 {
+		return errors.New("max replica lag cannot be negative")
+	}
+
+	if p.ReplicaFallbackPolicy != "" &&
+		p.ReplicaFallbackPolicy != ReplicaFallbackPolicyPrimary &&
+		p.ReplicaFallbackPolicy != ReplicaFallbackPolicyFail {
+		return errors.New("replica fallback policy must be FALLBACK_TO_PRIMARY or FAIL")
+	}
+
 	return nil
 }
 
+// HasExclusions tells schemas, tables or table data are excluded from dumps
+func (p *PostgresqlDatabase) HasExclusions() bool {
+	return len(p.ExcludeSchemas) > 0 ||
+		len(p.ExcludeTables) > 0 ||
+		len(p.ExcludeTableData) > 0
+}
+
 func (p *PostgresqlDatabase) TestConnection(
 	logger *slog.Logger,
 	encryptor encryption.FieldEncryptor,
@@ -97,6 +186,9 @@ func (p *PostgresqlDatabase) HideSensitiveData() {
 	}
 
 	p.Password = ""
+	for _, replica := range p.Replicas {
+		replica.Password = ""
+	}
 }
 
 func (p *PostgresqlDatabase) Update(incoming *PostgresqlDatabase) {
@@ -107,6 +199,12 @@ func (p *PostgresqlDatabase) Update(incoming *PostgresqlDatabase) {
 	p.Database = incoming.Database
 	p.IsHttps = incoming.IsHttps
 	p.IncludeSchemas = incoming.IncludeSchemas
+	p.ExcludeSchemas = incoming.ExcludeSchemas
+	p.ExcludeTables = incoming.ExcludeTables
+	p.ExcludeTableData = incoming.ExcludeTableData
+	p.Replicas = mergeReplicas(p.Replicas, incoming.Replicas)
+	p.MaxReplicaLagSeconds = incoming.MaxReplicaLagSeconds
+	p.ReplicaFallbackPolicy = incoming.ReplicaFallbackPolicy
 
 	if incoming.Password != "" {
 		p.Password = incoming.Password
@@ -125,6 +223,18 @@ func (p *PostgresqlDatabase) EncryptSensitiveFields(
 		p.Password = encrypted
 	}
 
+	for _, replica := range p.Replicas {
+		if replica.Password == "" {
+			continue
+		}
+
+		encrypted, err := encryptor.Encrypt(databaseID, replica.Password)
+		if err != nil {
+			return err
+		}
+		replica.Password = encrypted
+	}
+
 	return nil
 }
 
@@ -172,6 +282,250 @@ func (p *PostgresqlDatabase) PopulateVersionIfEmpty(
 	return nil
 }
 
+// GetServerDatabaseNames returns names of all databases on the server
+// that can be backed up: templates and databases that do not allow
+// connections are skipped
+func (p *PostgresqlDatabase) GetServerDatabaseNames(
+	logger *slog.Logger,
+	encryptor encryption.FieldEncryptor,
+	databaseID uuid.UUID,
+) ([]string, error) {
+	if p.Database == nil || *p.Database == "" {
+		return nil, errors.New("database name is required to connect to the server")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+
+	password, err := decryptPasswordIfNeeded(p.Password, encryptor, databaseID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt password: %w", err)
+	}
+
+	conn, err := pgx.Connect(ctx, buildConnectionStringForDB(p, *p.Database, password))
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to database: %w", err)
+	}
+	defer func() {
+		if closeErr := conn.Close(ctx); closeErr != nil {
+			logger.Error("Failed to close connection", "error", closeErr)
+		}
+	}()
+
+	rows, err := conn.Query(
+		ctx,
+		"SELECT datname FROM pg_database WHERE NOT datistemplate AND datallowconn ORDER BY datname",
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list databases: %w", err)
+	}
+
+	databaseNames, err := pgx.CollectRows(rows, pgx.RowTo[string])
+	if err != nil {
+		return nil, fmt.Errorf("failed to list databases: %w", err)
+	}
+
+	return databaseNames, nil
+}
+
+// ExecuteSql runs SQL of backup hook on the database. Several statements
+// can be passed at once. Notices raised by the SQL are returned as output
+func (p *PostgresqlDatabase) ExecuteSql(
+	ctx context.Context,
+	logger *slog.Logger,
+	encryptor encryption.FieldEncryptor,
+	databaseID uuid.UUID,
+	sql string,
+) (string, error) {
+	if p.Database == nil || *p.Database == "" {
+		return "", errors.New("database name is required to execute SQL")
+	}
+
+	password, err := decryptPasswordIfNeeded(p.Password, encryptor, databaseID)
+	if err != nil {
+		return "", fmt.Errorf("failed to decrypt password: %w", err)
+	}
+
+	connConfig, err := pgx.ParseConfig(buildConnectionStringForDB(p, *p.Database, password))
+	if err != nil {
+		return "", fmt.Errorf("failed to parse connection string: %w", err)
+	}
+
+	var output strings.Builder
+	connConfig.OnNotice = func(_ *pgconn.PgConn, notice *pgconn.Notice) {
+		output.WriteString(notice.Severity + ": " + notice.Message + "\n")
+	}
+
+	conn, err := pgx.ConnectConfig(ctx, connConfig)
+	if err != nil {
+		return "", fmt.Errorf("failed to connect to database: %w", err)
+	}
+	defer func() {
+		if closeErr := conn.Close(context.Background()); closeErr != nil {
+			logger.Error("Failed to close connection", "error", closeErr)
+		}
+	}()
+
+	commandTag, err := conn.Exec(ctx, sql)
+	if err != nil {
+		return output.String(), err
+	}
+
+	output.WriteString(commandTag.String())
+	return output.String(), nil
+}
+
+// GetDatabaseSizeBytes returns pg_database_size of the database or, for
+// the whole server, the sum over all databases which can be backed up
+func (p *PostgresqlDatabase) GetDatabaseSizeBytes(
+	logger *slog.Logger,
+	encryptor encryption.FieldEncryptor,
+	databaseID uuid.UUID,
+	isWholeServer bool,
+) (int64, error) {
+	if p.Database == nil || *p.Database == "" {
+		return 0, errors.New("database name is required to get database size")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+
+	password, err := decryptPasswordIfNeeded(p.Password, encryptor, databaseID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to decrypt password: %w", err)
+	}
+
+	conn, err := pgx.Connect(ctx, buildConnectionStringForDB(p, *p.Database, password))
+	if err != nil {
+		return 0, fmt.Errorf("failed to connect to database: %w", err)
+	}
+	defer func() {
+		if closeErr := conn.Close(ctx); closeErr != nil {
+			logger.Error("Failed to close connection", "error", closeErr)
+		}
+	}()
+
+	query := "SELECT pg_database_size(current_database())"
+	if isWholeServer {
+		query = `
+			SELECT COALESCE(SUM(pg_database_size(datname)), 0)::bigint
+			FROM pg_database
+			WHERE NOT datistemplate AND datallowconn
+		`
+	}
+
+	var sizeBytes int64
+	if err := conn.QueryRow(ctx, query).Scan(&sizeBytes); err != nil {
+		return 0, fmt.Errorf("failed to get database size: %w", err)
+	}
+
+	return sizeBytes, nil
+}
+
+// IsUserReplicationAllowed checks the user can stream WAL, it
+// requires REPLICATION attribute of the role or superuser
+func (p *PostgresqlDatabase) IsUserReplicationAllowed(
+	ctx context.Context,
+	logger *slog.Logger,
+	encryptor encryption.FieldEncryptor,
+	databaseID uuid.UUID,
+) (bool, error) {
+	if p.Database == nil || *p.Database == "" {
+		return false, errors.New("database name is required to check replication permission")
+	}
+
+	password, err := decryptPasswordIfNeeded(p.Password, encryptor, databaseID)
+	if err != nil {
+		return false, fmt.Errorf("failed to decrypt password: %w", err)
+	}
+
+	conn, err := pgx.Connect(ctx, buildConnectionStringForDB(p, *p.Database, password))
+	if err != nil {
+		return false, fmt.Errorf("failed to connect to database: %w", err)
+	}
+	defer func() {
+		if closeErr := conn.Close(ctx); closeErr != nil {
+			logger.Error("Failed to close connection", "error", closeErr)
+		}
+	}()
+
+	var isReplicationAllowed bool
+	err = conn.QueryRow(ctx, `
+		SELECT rolreplication OR rolsuper
+		FROM pg_roles
+		WHERE rolname = current_user
+	`).Scan(&isReplicationAllowed)
+	if err != nil {
+		return false, fmt.Errorf("failed to check role attributes: %w", err)
+	}
+
+	return isReplicationAllowed, nil
+}
+
+// DropReplicationSlotIfExists drops the physical replication slot and
+// tells whether it existed. Streaming to the slot is terminated first,
+// because active slot cannot be dropped
+func (p *PostgresqlDatabase) DropReplicationSlotIfExists(
+	ctx context.Context,
+	logger *slog.Logger,
+	encryptor encryption.FieldEncryptor,
+	databaseID uuid.UUID,
+	slotName string,
+) (bool, error) {
+	if p.Database == nil || *p.Database == "" {
+		return false, errors.New("database name is required to drop replication slot")
+	}
+
+	password, err := decryptPasswordIfNeeded(p.Password, encryptor, databaseID)
+	if err != nil {
+		return false, fmt.Errorf("failed to decrypt password: %w", err)
+	}
+
+	conn, err := pgx.Connect(ctx, buildConnectionStringForDB(p, *p.Database, password))
+	if err != nil {
+		return false, fmt.Errorf("failed to connect to database: %w", err)
+	}
+	defer func() {
+		if closeErr := conn.Close(context.Background()); closeErr != nil {
+			logger.Error("Failed to close connection", "error", closeErr)
+		}
+	}()
+
+	for {
+		var activePid *int32
+		err := conn.QueryRow(
+			ctx,
+			"SELECT active_pid FROM pg_replication_slots WHERE slot_name = $1",
+			slotName,
+		).Scan(&activePid)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return false, nil
+		}
+		if err != nil {
+			return false, fmt.Errorf("failed to find replication slot: %w", err)
+		}
+
+		if activePid == nil {
+			if _, err := conn.Exec(ctx, "SELECT pg_drop_replication_slot($1)", slotName); err != nil {
+				return false, fmt.Errorf("failed to drop replication slot: %w", err)
+			}
+
+			return true, nil
+		}
+
+		// pg_receivewal may still be exiting after it is stopped
+		if _, err := conn.Exec(ctx, "SELECT pg_terminate_backend($1)", *activePid); err != nil {
+			return false, fmt.Errorf("failed to terminate replication connection: %w", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return false, fmt.Errorf("replication slot is still active: %w", ctx.Err())
+		case <-time.After(500 * time.Millisecond):
+		}
+	}
+}
+
 // IsUserReadOnly checks if the database user has read-only privileges.
 //
 // This method performs a comprehensive security check by examining:
@@ -699,6 +1053,14 @@ func decryptPasswordIfNeeded(
 	return encryptor.Decrypt(databaseID, password)
 }
 
+func splitPatterns(patternsString string) []string {
+	if patternsString == "" {
+		return []string{}
+	}
+
+	return strings.Split(patternsString, ",")
+}
+
 func isSupabaseConnection(host, username string) bool {
 	return strings.Contains(strings.ToLower(host), "supabase") ||
 		strings.Contains(strings.ToLower(username), "supabase")
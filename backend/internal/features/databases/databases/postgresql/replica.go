@@ -0,0 +1,253 @@
+package postgresql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"postgresus-backend/internal/util/encryption"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+type ReplicaFallbackPolicy string
+
+const (
+	// primary is dumped when there is no healthy replica
+	ReplicaFallbackPolicyPrimary ReplicaFallbackPolicy = "FALLBACK_TO_PRIMARY"
+	// backup fails when there is no healthy replica
+	ReplicaFallbackPolicyFail ReplicaFallbackPolicy = "FAIL"
+)
+
+// used when max replica lag is not set
+const DefaultMaxReplicaLagSeconds = 300
+
+const replicaCheckTimeout = 10 * time.Second
+
+// PostgresqlReplica is connection profile of a standby of the database.
+// Database name is the same as on the primary
+type PostgresqlReplica struct {
+	ID       uuid.UUID `json:"id"`
+	Host     string    `json:"host"`
+	Port     int       `json:"port"`
+	Username string    `json:"username"`
+	Password string    `json:"password"`
+	IsHttps  bool      `json:"isHttps"`
+}
+
+func (r *PostgresqlReplica) Validate() error {
+	if r.Host == "" {
+		return errors.New("replica host is required")
+	}
+
+	if r.Port == 0 {
+		return errors.New("replica port is required")
+	}
+
+	if r.Username == "" {
+		return errors.New("replica username is required")
+	}
+
+	if r.Password == "" {
+		return errors.New("replica password is required")
+	}
+
+	return nil
+}
+
+// SelectBackupSource returns connection to dump the database from: the first
+// healthy replica with acceptable lag or, depending on policy, the primary
+func (p *PostgresqlDatabase) SelectBackupSource(
+	ctx context.Context,
+	logger *slog.Logger,
+	encryptor encryption.FieldEncryptor,
+	databaseID uuid.UUID,
+) (*PostgresqlDatabase, error) {
+	if len(p.Replicas) == 0 {
+		return p, nil
+	}
+
+	replicaErrors := []string{}
+
+	for _, replica := range p.Replicas {
+		replicaDb := p.withReplicaConnection(replica)
+
+		err := replicaDb.checkReplicaHealth(ctx, logger, encryptor, databaseID, p.getMaxReplicaLagSeconds())
+		if err == nil {
+			return replicaDb, nil
+		}
+
+		logger.Warn(
+			"Replica cannot be used for backup",
+			"databaseId",
+			databaseID,
+			"replica",
+			replicaDb.GetAddress(),
+			"error",
+			err,
+		)
+		replicaErrors = append(replicaErrors, fmt.Sprintf("%s: %v", replicaDb.GetAddress(), err))
+	}
+
+	if p.ReplicaFallbackPolicy == ReplicaFallbackPolicyFail {
+		return nil, fmt.Errorf("no healthy replica to back up from: %s", strings.Join(replicaErrors, "; "))
+	}
+
+	logger.Warn("No healthy replica, backing up from primary", "databaseId", databaseID)
+	return p, nil
+}
+
+// GetAddress returns host:port the database is connected to
+func (p *PostgresqlDatabase) GetAddress() string {
+	return fmt.Sprintf("%s:%d", p.Host, p.Port)
+}
+
+func (p *PostgresqlDatabase) withReplicaConnection(replica *PostgresqlReplica) *PostgresqlDatabase {
+	replicaDb := *p
+	replicaDb.Host = replica.Host
+	replicaDb.Port = replica.Port
+	replicaDb.Username = replica.Username
+	replicaDb.Password = replica.Password
+	replicaDb.IsHttps = replica.IsHttps
+	replicaDb.Replicas = nil
+
+	return &replicaDb
+}
+
+// checkReplicaHealth checks the server is in recovery and its replay lag
+func (p *PostgresqlDatabase) checkReplicaHealth(
+	ctx context.Context,
+	logger *slog.Logger,
+	encryptor encryption.FieldEncryptor,
+	databaseID uuid.UUID,
+	maxLagSeconds int,
+) error {
+	if p.Database == nil || *p.Database == "" {
+		return errors.New("database name is required to connect to replica")
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, replicaCheckTimeout)
+	defer cancel()
+
+	password, err := decryptPasswordIfNeeded(p.Password, encryptor, databaseID)
+	if err != nil {
+		return fmt.Errorf("failed to decrypt password: %w", err)
+	}
+
+	conn, err := pgx.Connect(ctx, buildConnectionStringForDB(p, *p.Database, password))
+	if err != nil {
+		return fmt.Errorf("failed to connect: %w", err)
+	}
+	defer func() {
+		if closeErr := conn.Close(context.Background()); closeErr != nil {
+			logger.Error("Failed to close connection", "error", closeErr)
+		}
+	}()
+
+	var isInRecovery bool
+	var receiverStatus *string
+	var isReplayCaughtUp bool
+	var replayLagSeconds *float64
+	err = conn.QueryRow(ctx, `
+		SELECT
+			pg_is_in_recovery(),
+			(SELECT status FROM pg_stat_wal_receiver LIMIT 1),
+			COALESCE(pg_last_wal_receive_lsn() = pg_last_wal_replay_lsn(), false),
+			EXTRACT(EPOCH FROM now() - pg_last_xact_replay_timestamp())::float8
+	`).Scan(&isInRecovery, &receiverStatus, &isReplayCaughtUp, &replayLagSeconds)
+	if err != nil {
+		return fmt.Errorf("failed to check replication lag: %w", err)
+	}
+
+	if !isInRecovery {
+		return errors.New("server is not a standby")
+	}
+
+	return checkReplicaLag(
+		getReplicaLagSeconds(receiverStatus, isReplayCaughtUp, replayLagSeconds),
+		maxLagSeconds,
+	)
+}
+
+func (p *PostgresqlDatabase) getMaxReplicaLagSeconds() int {
+	if p.MaxReplicaLagSeconds == 0 {
+		return DefaultMaxReplicaLagSeconds
+	}
+
+	return p.MaxReplicaLagSeconds
+}
+
+// getReplicaLagSeconds returns zero lag only for streaming standby which
+// replayed everything received: replay timestamp does not move while the
+// primary is idle. Disconnected standby receives nothing, so its received
+// and replayed WAL match while it falls behind, time since the last
+// replayed transaction is used for it
+func getReplicaLagSeconds(
+	receiverStatus *string,
+	isReplayCaughtUp bool,
+	replayLagSeconds *float64,
+) *float64 {
+	if receiverStatus != nil && *receiverStatus == "streaming" && isReplayCaughtUp {
+		lagSeconds := 0.0
+		return &lagSeconds
+	}
+
+	return replayLagSeconds
+}
+
+// checkReplicaLag treats unknown lag as too big: the
+// standby has not replayed anything since it started
+func checkReplicaLag(lagSeconds *float64, maxLagSeconds int) error {
+	if lagSeconds == nil {
+		return errors.New("replication lag is unknown")
+	}
+
+	if *lagSeconds > float64(maxLagSeconds) {
+		return fmt.Errorf(
+			"replication lag is %.0f seconds, maximum is %d seconds",
+			*lagSeconds,
+			maxLagSeconds,
+		)
+	}
+
+	return nil
+}
+
+// mergeReplicas keeps passwords of existing replicas, because
+// passwords are hidden from the client and come back empty
+func mergeReplicas(existing, incoming []*PostgresqlReplica) []*PostgresqlReplica {
+	existingByID := map[uuid.UUID]*PostgresqlReplica{}
+	for _, replica := range existing {
+		existingByID[replica.ID] = replica
+	}
+
+	merged := make([]*PostgresqlReplica, 0, len(incoming))
+	for _, replica := range incoming {
+		replicaCopy := *replica
+
+		if existingReplica, ok := existingByID[replica.ID]; ok &&
+			replica.ID != uuid.Nil && replicaCopy.Password == "" {
+			replicaCopy.Password = existingReplica.Password
+		}
+
+		merged = append(merged, &replicaCopy)
+	}
+
+	return merged
+}
+
+// CopyReplicas copies replicas for another database under new IDs
+func CopyReplicas(replicas []*PostgresqlReplica) []*PostgresqlReplica {
+	copied := make([]*PostgresqlReplica, 0, len(replicas))
+	for _, replica := range replicas {
+		replicaCopy := *replica
+		replicaCopy.ID = uuid.New()
+		copied = append(copied, &replicaCopy)
+	}
+
+	return copied
+}
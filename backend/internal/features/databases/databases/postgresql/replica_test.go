@@ -0,0 +1,129 @@
+package postgresql
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_SelectBackupSource_NoReplicas_PrimaryReturned(t *testing.T) {
+	pg := createReplicaTestDatabase(ReplicaFallbackPolicyFail)
+
+	source, err := pg.SelectBackupSource(context.Background(), createReplicaTestLogger(), nil, uuid.New())
+
+	assert.NoError(t, err)
+	assert.Equal(t, "primary:5432", source.GetAddress())
+}
+
+func Test_SelectBackupSource_ReplicaUnavailable_FallbackByPolicy(t *testing.T) {
+	t.Run("Primary is used with fallback policy", func(t *testing.T) {
+		pg := createReplicaTestDatabase(ReplicaFallbackPolicyPrimary)
+		pg.Replicas = []*PostgresqlReplica{createUnavailableReplica()}
+
+		source, err := pg.SelectBackupSource(context.Background(), createReplicaTestLogger(), nil, uuid.New())
+
+		assert.NoError(t, err)
+		assert.Equal(t, "primary:5432", source.GetAddress())
+	})
+
+	t.Run("Backup fails with fail policy", func(t *testing.T) {
+		pg := createReplicaTestDatabase(ReplicaFallbackPolicyFail)
+		pg.Replicas = []*PostgresqlReplica{createUnavailableReplica()}
+
+		source, err := pg.SelectBackupSource(context.Background(), createReplicaTestLogger(), nil, uuid.New())
+
+		assert.Error(t, err)
+		assert.Nil(t, source)
+		assert.Contains(t, err.Error(), "127.0.0.1:1")
+	})
+}
+
+func Test_CheckReplicaLag_LagComparedWithMaximum(t *testing.T) {
+	smallLag := 10.0
+	bigLag := 600.0
+
+	assert.NoError(t, checkReplicaLag(&smallLag, 300))
+	assert.Error(t, checkReplicaLag(&bigLag, 300))
+	assert.Error(t, checkReplicaLag(nil, 300))
+}
+
+func Test_GetReplicaLagSeconds_ReceiverDisconnected_ReplayLagUsed(t *testing.T) {
+	replayLag := 900.0
+	streaming := "streaming"
+	stopping := "stopping"
+
+	// idle primary, everything received is replayed
+	lagSeconds := getReplicaLagSeconds(&streaming, true, &replayLag)
+	assert.Equal(t, 0.0, *lagSeconds)
+
+	// nothing is received, so received and replayed WAL match
+	lagSeconds = getReplicaLagSeconds(nil, true, &replayLag)
+	assert.Error(t, checkReplicaLag(lagSeconds, 300))
+
+	lagSeconds = getReplicaLagSeconds(&stopping, true, &replayLag)
+	assert.Equal(t, replayLag, *lagSeconds)
+
+	lagSeconds = getReplicaLagSeconds(&streaming, false, &replayLag)
+	assert.Equal(t, replayLag, *lagSeconds)
+}
+
+func Test_UpdatePostgresqlDatabase_ReplicaPasswordHidden_PasswordKept(t *testing.T) {
+	replicaID := uuid.New()
+
+	pg := createReplicaTestDatabase(ReplicaFallbackPolicyPrimary)
+	pg.Replicas = []*PostgresqlReplica{{
+		ID:       replicaID,
+		Host:     "replica",
+		Port:     5432,
+		Username: "postgres",
+		Password: "secret",
+	}}
+
+	incoming := createReplicaTestDatabase(ReplicaFallbackPolicyPrimary)
+	incoming.Replicas = []*PostgresqlReplica{
+		{ID: replicaID, Host: "replica", Port: 5433, Username: "postgres"},
+		{Host: "new-replica", Port: 5432, Username: "postgres", Password: "new-secret"},
+	}
+
+	pg.Update(incoming)
+
+	assert.Len(t, pg.Replicas, 2)
+	assert.Equal(t, 5433, pg.Replicas[0].Port)
+	assert.Equal(t, "secret", pg.Replicas[0].Password)
+	assert.Equal(t, "new-secret", pg.Replicas[1].Password)
+
+	pg.HideSensitiveData()
+	assert.Empty(t, pg.Replicas[0].Password)
+	assert.Empty(t, pg.Replicas[1].Password)
+}
+
+func createReplicaTestDatabase(policy ReplicaFallbackPolicy) *PostgresqlDatabase {
+	databaseName := "postgres"
+
+	return &PostgresqlDatabase{
+		Host:                  "primary",
+		Port:                  5432,
+		Username:              "postgres",
+		Password:              "password",
+		Database:              &databaseName,
+		ReplicaFallbackPolicy: policy,
+	}
+}
+
+func createUnavailableReplica() *PostgresqlReplica {
+	return &PostgresqlReplica{
+		ID:       uuid.New(),
+		Host:     "127.0.0.1",
+		Port:     1,
+		Username: "postgres",
+		Password: "password",
+	}
+}
+
+func createReplicaTestLogger() *slog.Logger {
+	return slog.New(slog.NewTextHandler(os.Stdout, nil))
+}
@@ -1,6 +1,7 @@
 package databases
 
 import (
+	"context"
 	"errors"
 	"log/slog"
 	"postgresus-backend/internal/features/databases/databases/postgresql"
@@ -85,6 +86,19 @@ func (d *Database) PopulateVersionIfEmpty(
 	return nil
 }
 
+func (d *Database) ExecuteSql(
+	ctx context.Context,
+	logger *slog.Logger,
+	encryptor encryption.FieldEncryptor,
+	sql string,
+) (string, error) {
+	if d.Postgresql == nil {
+		return "", errors.New("postgresql database is required")
+	}
+
+	return d.Postgresql.ExecuteSql(ctx, logger, encryptor, d.ID, sql)
+}
+
 func (d *Database) Update(incoming *Database) {
 	d.Name = incoming.Name
 	d.Type = incoming.Type
@@ -402,6 +402,15 @@ func (s *DatabaseService) CopyDatabase(
 				Password:   existingDatabase.Postgresql.Password,
 				Database:   existingDatabase.Postgresql.Database,
 				IsHttps:    existingDatabase.Postgresql.IsHttps,
+
+				IncludeSchemas:   existingDatabase.Postgresql.IncludeSchemas,
+				ExcludeSchemas:   existingDatabase.Postgresql.ExcludeSchemas,
+				ExcludeTables:    existingDatabase.Postgresql.ExcludeTables,
+				ExcludeTableData: existingDatabase.Postgresql.ExcludeTableData,
+
+				Replicas:              postgresql.CopyReplicas(existingDatabase.Postgresql.Replicas),
+				MaxReplicaLagSeconds:  existingDatabase.Postgresql.MaxReplicaLagSeconds,
+				ReplicaFallbackPolicy: existingDatabase.Postgresql.ReplicaFallbackPolicy,
 			}
 		}
 	}
@@ -533,6 +542,38 @@ func (s *DatabaseService) IsUserReadOnly(
 	)
 }
 
+// CheckReplicationAllowed returns error when the user of the
+// database cannot stream WAL for archiving
+func (s *DatabaseService) CheckReplicationAllowed(database *Database) error {
+	if database.Type != DatabaseTypePostgres || database.Postgresql == nil {
+		return errors.New("WAL archiving is only supported for PostgreSQL databases")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+
+	isReplicationAllowed, err := database.Postgresql.IsUserReplicationAllowed(
+		ctx,
+		s.logger,
+		s.fieldEncryptor,
+		database.ID,
+	)
+	if err != nil {
+		return err
+	}
+
+	if !isReplicationAllowed {
+		return fmt.Errorf(
+			"user %s has no REPLICATION attribute, it is required for WAL archiving. "+
+				"Grant it via ALTER ROLE \"%s\" WITH REPLICATION",
+			database.Postgresql.Username,
+			database.Postgresql.Username,
+		)
+	}
+
+	return nil
+}
+
 func (s *DatabaseService) CreateReadOnlyUser(
 	user *users_models.User,
 	database *Database,
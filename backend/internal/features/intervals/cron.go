@@ -0,0 +1,253 @@
+package intervals
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CronSchedule is a parsed standard 5-field cron expression:
+// minute, hour, day of month, month and day of week
+type CronSchedule struct {
+	minutes     []bool
+	hours       []bool
+	daysOfMonth []bool
+	months      []bool
+	daysOfWeek  []bool
+
+	// cron matches either day of month or day of week
+	// when both of them are restricted
+	isDayOfMonthRestricted bool
+	isDayOfWeekRestricted  bool
+}
+
+type cronField struct {
+	name  string
+	min   int
+	max   int
+	names map[string]int
+}
+
+var (
+	cronMinuteField = cronField{name: "minute", min: 0, max: 59}
+	cronHourField   = cronField{name: "hour", min: 0, max: 23}
+	cronDayField    = cronField{name: "day of month", min: 1, max: 31}
+	cronMonthField  = cronField{
+		name: "month",
+		min:  1,
+		max:  12,
+		names: map[string]int{
+			"JAN": 1, "FEB": 2, "MAR": 3, "APR": 4, "MAY": 5, "JUN": 6,
+			"JUL": 7, "AUG": 8, "SEP": 9, "OCT": 10, "NOV": 11, "DEC": 12,
+		},
+	}
+	// 7 is accepted as Sunday as well
+	cronWeekdayField = cronField{
+		name: "day of week",
+		min:  0,
+		max:  7,
+		names: map[string]int{
+			"SUN": 0, "MON": 1, "TUE": 2, "WED": 3, "THU": 4, "FRI": 5, "SAT": 6,
+		},
+	}
+)
+
+// how far back the previous slot is searched. Covers leap day schedules
+const cronLookbackYears = 5
+
+var daysInMonthMax = []int{0, 31, 29, 31, 30, 31, 30, 31, 31, 30, 31, 30, 31}
+
+func ParseCronExpression(expression string) (*CronSchedule, error) {
+	fields := strings.Fields(expression)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf(
+			"cron expression must have 5 fields (minute, hour, day of month, month, day of week), got %d",
+			len(fields),
+		)
+	}
+
+	schedule := &CronSchedule{}
+	var err error
+
+	if schedule.minutes, err = parseCronField(fields[0], cronMinuteField); err != nil {
+		return nil, err
+	}
+	if schedule.hours, err = parseCronField(fields[1], cronHourField); err != nil {
+		return nil, err
+	}
+	if schedule.daysOfMonth, err = parseCronField(fields[2], cronDayField); err != nil {
+		return nil, err
+	}
+	if schedule.months, err = parseCronField(fields[3], cronMonthField); err != nil {
+		return nil, err
+	}
+	if schedule.daysOfWeek, err = parseCronField(fields[4], cronWeekdayField); err != nil {
+		return nil, err
+	}
+
+	if schedule.daysOfWeek[7] {
+		schedule.daysOfWeek[0] = true
+	}
+
+	schedule.isDayOfMonthRestricted = !strings.HasPrefix(fields[2], "*")
+	schedule.isDayOfWeekRestricted = !strings.HasPrefix(fields[4], "*")
+
+	if !schedule.hasPossibleDay() {
+		return nil, fmt.Errorf("cron expression %q never matches any date", expression)
+	}
+
+	return schedule, nil
+}
+
+// Prev returns the latest scheduled minute at or before t.
+// False is returned if there is no such minute within lookback
+func (s *CronSchedule) Prev(t time.Time) (time.Time, bool) {
+	loc := t.Location()
+	current := t.Truncate(time.Minute)
+	limit := current.AddDate(-cronLookbackYears, 0, 0)
+
+	for !current.Before(limit) {
+		if !s.months[int(current.Month())] {
+			// last minute of the previous month
+			current = time.Date(current.Year(), current.Month(), 1, 0, 0, 0, 0, loc).
+				Add(-time.Minute)
+			continue
+		}
+
+		if !s.isDayMatched(current) {
+			current = time.Date(current.Year(), current.Month(), current.Day(), 0, 0, 0, 0, loc).
+				Add(-time.Minute)
+			continue
+		}
+
+		if !s.hours[current.Hour()] {
+			current = time.Date(
+				current.Year(), current.Month(), current.Day(),
+				current.Hour(), 0, 0, 0, loc,
+			).Add(-time.Minute)
+			continue
+		}
+
+		if !s.minutes[current.Minute()] {
+			current = current.Add(-time.Minute)
+			continue
+		}
+
+		return current, true
+	}
+
+	return time.Time{}, false
+}
+
+func (s *CronSchedule) isDayMatched(t time.Time) bool {
+	isDayOfMonthMatched := s.daysOfMonth[t.Day()]
+	isDayOfWeekMatched := s.daysOfWeek[int(t.Weekday())]
+
+	if s.isDayOfMonthRestricted && s.isDayOfWeekRestricted {
+		return isDayOfMonthMatched || isDayOfWeekMatched
+	}
+
+	return isDayOfMonthMatched && isDayOfWeekMatched
+}
+
+// hasPossibleDay rejects expressions like "0 0 30 2 *"
+func (s *CronSchedule) hasPossibleDay() bool {
+	if s.isDayOfWeekRestricted {
+		return true
+	}
+
+	for month := 1; month <= 12; month++ {
+		if !s.months[month] {
+			continue
+		}
+
+		for day := 1; day <= daysInMonthMax[month]; day++ {
+			if s.daysOfMonth[day] {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func parseCronField(value string, field cronField) ([]bool, error) {
+	matched := make([]bool, field.max+1)
+
+	for _, part := range strings.Split(value, ",") {
+		if part == "" {
+			return nil, fmt.Errorf("invalid %s field: %q", field.name, value)
+		}
+
+		rangePart, stepPart, isStepped := strings.Cut(part, "/")
+
+		step := 1
+		if isStepped {
+			parsedStep, err := strconv.Atoi(stepPart)
+			if err != nil || parsedStep <= 0 {
+				return nil, fmt.Errorf("invalid step in %s field: %q", field.name, part)
+			}
+			step = parsedStep
+		}
+
+		start, end := field.min, field.max
+
+		switch {
+		case rangePart == "*":
+		case strings.Contains(rangePart, "-"):
+			startPart, endPart, _ := strings.Cut(rangePart, "-")
+
+			var err error
+			if start, err = parseCronValue(startPart, field); err != nil {
+				return nil, err
+			}
+			if end, err = parseCronValue(endPart, field); err != nil {
+				return nil, err
+			}
+
+			if start > end {
+				return nil, fmt.Errorf("invalid range in %s field: %q", field.name, part)
+			}
+		default:
+			var err error
+			if start, err = parseCronValue(rangePart, field); err != nil {
+				return nil, err
+			}
+
+			// "5/15" means from 5 to the end with step 15
+			if !isStepped {
+				end = start
+			}
+		}
+
+		for v := start; v <= end; v += step {
+			matched[v] = true
+		}
+	}
+
+	return matched, nil
+}
+
+func parseCronValue(value string, field cronField) (int, error) {
+	if number, ok := field.names[strings.ToUpper(value)]; ok {
+		return number, nil
+	}
+
+	number, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid value in %s field: %q", field.name, value)
+	}
+
+	if number < field.min || number > field.max {
+		return 0, fmt.Errorf(
+			"%s must be between %d and %d, got %d",
+			field.name,
+			field.min,
+			field.max,
+			number,
+		)
+	}
+
+	return number, nil
+}
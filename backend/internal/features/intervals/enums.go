@@ -7,4 +7,6 @@ const (
 	IntervalDaily   IntervalType = "DAILY"
 	IntervalWeekly  IntervalType = "WEEKLY"
 	IntervalMonthly IntervalType = "MONTHLY"
+	// standard 5-field cron expression
+	IntervalCron IntervalType = "CRON"
 )
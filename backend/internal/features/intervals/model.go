@@ -2,6 +2,7 @@ package intervals
 
 import (
 	"errors"
+	"fmt"
 	"time"
 
 	"github.com/google/uuid"
@@ -17,6 +18,11 @@ type Interval struct {
 	Weekday *int `json:"weekday,omitempty"    gorm:"type:int"`
 	// only for MONTHLY
 	DayOfMonth *int `json:"dayOfMonth,omitempty" gorm:"type:int"`
+	// only for CRON
+	CronExpression *string `json:"cronExpression,omitempty" gorm:"type:text"`
+
+	// IANA name, slots are evaluated in this zone. UTC when empty
+	Timezone *string `json:"timezone,omitempty" gorm:"type:text"`
 }
 
 func (i *Interval) BeforeSave(tx *gorm.DB) error {
@@ -24,6 +30,12 @@ func (i *Interval) BeforeSave(tx *gorm.DB) error {
 }
 
 func (i *Interval) Validate() error {
+	if i.Timezone != nil && *i.Timezone != "" {
+		if _, err := time.LoadLocation(*i.Timezone); err != nil {
+			return fmt.Errorf("unknown timezone: %s", *i.Timezone)
+		}
+	}
+
 	// for daily, weekly and monthly intervals time of day is required
 	if (i.Interval == IntervalDaily || i.Interval == IntervalWeekly || i.Interval == IntervalMonthly) &&
 		i.TimeOfDay == nil {
@@ -40,6 +52,16 @@ func (i *Interval) Validate() error {
 		return errors.New("day of month is required for monthly intervals")
 	}
 
+	if i.Interval == IntervalCron {
+		if i.CronExpression == nil || *i.CronExpression == "" {
+			return errors.New("cron expression is required for cron intervals")
+		}
+
+		if _, err := ParseCronExpression(*i.CronExpression); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
@@ -50,15 +72,24 @@ func (i *Interval) ShouldTriggerBackup(now time.Time, lastBackupTime *time.Time)
 		return true
 	}
 
+	// slots are built via time.Date in the zone of now: skipped local time
+	// is moved forward and repeated local time resolves to one instant, so
+	// each slot fires once on DST changes
+	location := i.getLocation()
+	now = now.In(location)
+	lastBackup := lastBackupTime.In(location)
+
 	switch i.Interval {
 	case IntervalHourly:
-		return now.Sub(*lastBackupTime) >= time.Hour
+		return now.Sub(lastBackup) >= time.Hour
 	case IntervalDaily:
-		return i.shouldTriggerDaily(now, *lastBackupTime)
+		return i.shouldTriggerDaily(now, lastBackup)
 	case IntervalWeekly:
-		return i.shouldTriggerWeekly(now, *lastBackupTime)
+		return i.shouldTriggerWeekly(now, lastBackup)
 	case IntervalMonthly:
-		return i.shouldTriggerMonthly(now, *lastBackupTime)
+		return i.shouldTriggerMonthly(now, lastBackup)
+	case IntervalCron:
+		return i.shouldTriggerCron(now, lastBackup)
 	default:
 		return false
 	}
@@ -66,14 +97,31 @@ func (i *Interval) ShouldTriggerBackup(now time.Time, lastBackupTime *time.Time)
 
 func (i *Interval) Copy() *Interval {
 	return &Interval{
-		ID:         uuid.Nil,
-		Interval:   i.Interval,
-		TimeOfDay:  i.TimeOfDay,
-		Weekday:    i.Weekday,
-		DayOfMonth: i.DayOfMonth,
+		ID:             uuid.Nil,
+		Interval:       i.Interval,
+		TimeOfDay:      i.TimeOfDay,
+		Weekday:        i.Weekday,
+		DayOfMonth:     i.DayOfMonth,
+		CronExpression: i.CronExpression,
+		Timezone:       i.Timezone,
 	}
 }
 
+// getLocation returns zone of the interval. Unknown zone falls back to UTC,
+// it is rejected by Validate on save
+func (i *Interval) getLocation() *time.Location {
+	if i.Timezone == nil || *i.Timezone == "" {
+		return time.UTC
+	}
+
+	location, err := time.LoadLocation(*i.Timezone)
+	if err != nil {
+		return time.UTC
+	}
+
+	return location
+}
+
 // daily trigger: honour the TimeOfDay slot and catch up the previous one
 func (i *Interval) shouldTriggerDaily(now, lastBackup time.Time) bool {
 	if i.TimeOfDay == nil {
@@ -94,7 +142,12 @@ func (i *Interval) shouldTriggerDaily(now, lastBackup time.Time) bool {
 	// The last scheduled slot that should already have happened
 	var lastScheduled time.Time
 	if now.Before(todayTgt) {
-		lastScheduled = todayTgt.AddDate(0, 0, -1)
+		// built from the date, not todayTgt.AddDate: if today's slot falls
+		// into a skipped hour, it is shifted and would shift yesterday's too
+		lastScheduled = time.Date(
+			now.Year(), now.Month(), now.Day()-1,
+			t.Hour(), t.Minute(), 0, 0, now.Location(),
+		)
 	} else {
 		lastScheduled = todayTgt
 	}
@@ -187,6 +240,25 @@ func (i *Interval) shouldTriggerMonthly(now, lastBackup time.Time) bool {
 	return lastBackup.Before(getStartOfMonth(now))
 }
 
+// cron trigger: fire when the latest scheduled slot has no backup after it
+func (i *Interval) shouldTriggerCron(now, lastBackup time.Time) bool {
+	if i.CronExpression == nil {
+		return false
+	}
+
+	schedule, err := ParseCronExpression(*i.CronExpression)
+	if err != nil {
+		return false
+	}
+
+	lastScheduled, ok := schedule.Prev(now)
+	if !ok {
+		return false
+	}
+
+	return lastBackup.Before(lastScheduled)
+}
+
 func isSameDay(a, b time.Time) bool {
 	y1, m1, d1 := a.Date()
 	y2, m2, d2 := b.Date()
@@ -457,6 +457,163 @@ func TestInterval_ShouldTriggerBackup_Monthly(t *testing.T) {
 	)
 }
 
+func TestInterval_ShouldTriggerBackup_Cron(t *testing.T) {
+	t.Run("Every 15 minutes, last backup before latest slot: Trigger backup", func(t *testing.T) {
+		expression := "*/15 * * * *"
+		interval := &Interval{ID: uuid.New(), Interval: IntervalCron, CronExpression: &expression}
+
+		now := time.Date(2024, 1, 15, 10, 31, 0, 0, time.UTC)
+		lastBackup := time.Date(2024, 1, 15, 10, 15, 30, 0, time.UTC)
+
+		assert.True(t, interval.ShouldTriggerBackup(now, &lastBackup))
+	})
+
+	t.Run("Every 15 minutes, last backup after latest slot: Do not trigger backup", func(t *testing.T) {
+		expression := "*/15 * * * *"
+		interval := &Interval{ID: uuid.New(), Interval: IntervalCron, CronExpression: &expression}
+
+		now := time.Date(2024, 1, 15, 10, 44, 0, 0, time.UTC)
+		lastBackup := time.Date(2024, 1, 15, 10, 30, 5, 0, time.UTC)
+
+		assert.False(t, interval.ShouldTriggerBackup(now, &lastBackup))
+	})
+
+	t.Run("Weekdays at 02:00 and 14:00, Monday 14:00 reached: Trigger backup", func(t *testing.T) {
+		expression := "0 2,14 * * 1-5"
+		interval := &Interval{ID: uuid.New(), Interval: IntervalCron, CronExpression: &expression}
+
+		now := time.Date(2024, 1, 15, 14, 0, 0, 0, time.UTC) // Monday
+		lastBackup := time.Date(2024, 1, 15, 2, 0, 10, 0, time.UTC)
+
+		assert.True(t, interval.ShouldTriggerBackup(now, &lastBackup))
+	})
+
+	t.Run("Weekdays at 02:00 and 14:00, weekend: Do not trigger backup", func(t *testing.T) {
+		expression := "0 2,14 * * 1-5"
+		interval := &Interval{ID: uuid.New(), Interval: IntervalCron, CronExpression: &expression}
+
+		now := time.Date(2024, 1, 14, 15, 0, 0, 0, time.UTC) // Sunday
+		lastBackup := time.Date(2024, 1, 12, 14, 0, 10, 0, time.UTC)
+
+		assert.False(t, interval.ShouldTriggerBackup(now, &lastBackup))
+	})
+
+	t.Run("Weekdays at 02:00, Friday slot missed on weekend: Trigger backup", func(t *testing.T) {
+		expression := "0 2 * * MON-FRI"
+		interval := &Interval{ID: uuid.New(), Interval: IntervalCron, CronExpression: &expression}
+
+		now := time.Date(2024, 1, 13, 9, 0, 0, 0, time.UTC) // Saturday
+		lastBackup := time.Date(2024, 1, 11, 2, 0, 10, 0, time.UTC)
+
+		assert.True(t, interval.ShouldTriggerBackup(now, &lastBackup))
+	})
+}
+
+func TestInterval_ShouldTriggerBackup_Timezone(t *testing.T) {
+	timezone := "Europe/Berlin"
+	timeOfDay := "02:30"
+	interval := &Interval{
+		ID:        uuid.New(),
+		Interval:  IntervalDaily,
+		TimeOfDay: &timeOfDay,
+		Timezone:  &timezone,
+	}
+
+	t.Run("Daily slot is evaluated in interval timezone", func(t *testing.T) {
+		lastBackup := time.Date(2024, 1, 14, 1, 30, 10, 0, time.UTC)
+
+		// 02:00 in Berlin, slot is not reached yet
+		now := time.Date(2024, 1, 15, 1, 0, 0, 0, time.UTC)
+		assert.False(t, interval.ShouldTriggerBackup(now, &lastBackup))
+
+		// 02:31 in Berlin
+		now = time.Date(2024, 1, 15, 1, 31, 0, 0, time.UTC)
+		assert.True(t, interval.ShouldTriggerBackup(now, &lastBackup))
+	})
+
+	t.Run("Slot in skipped hour: Triggered once after the gap", func(t *testing.T) {
+		// 2024-03-31 clocks jump from 02:00 to 03:00 in Berlin
+		lastBackup := time.Date(2024, 3, 30, 1, 30, 10, 0, time.UTC)
+
+		// 01:59 in Berlin, before the gap
+		now := time.Date(2024, 3, 31, 0, 59, 0, 0, time.UTC)
+		assert.False(t, interval.ShouldTriggerBackup(now, &lastBackup))
+
+		// 03:31 in Berlin
+		now = time.Date(2024, 3, 31, 1, 31, 0, 0, time.UTC)
+		assert.True(t, interval.ShouldTriggerBackup(now, &lastBackup))
+
+		lastBackup = now
+		now = time.Date(2024, 3, 31, 10, 0, 0, 0, time.UTC)
+		assert.False(t, interval.ShouldTriggerBackup(now, &lastBackup))
+	})
+
+	t.Run("Slot in repeated hour: Triggered only once", func(t *testing.T) {
+		// 2024-10-27 clocks go back from 03:00 to 02:00 in Berlin
+		lastBackup := time.Date(2024, 10, 26, 0, 30, 10, 0, time.UTC)
+
+		now := time.Date(2024, 10, 27, 1, 31, 0, 0, time.UTC)
+		assert.True(t, interval.ShouldTriggerBackup(now, &lastBackup))
+
+		// both passes of 02:30 are already covered by the backup
+		lastBackup = now
+		for _, now := range []time.Time{
+			time.Date(2024, 10, 27, 1, 45, 0, 0, time.UTC),
+			time.Date(2024, 10, 27, 12, 0, 0, 0, time.UTC),
+		} {
+			assert.False(t, interval.ShouldTriggerBackup(now, &lastBackup))
+		}
+	})
+
+	t.Run("Weekly slot uses weekday in interval timezone", func(t *testing.T) {
+		timezone := "Asia/Tokyo"
+		timeOfDay := "01:00"
+		weekday := 1 // Monday
+		interval := &Interval{
+			ID:        uuid.New(),
+			Interval:  IntervalWeekly,
+			TimeOfDay: &timeOfDay,
+			Weekday:   &weekday,
+			Timezone:  &timezone,
+		}
+
+		// Sunday 16:30 UTC is Monday 01:30 in Tokyo
+		now := time.Date(2024, 1, 14, 16, 30, 0, 0, time.UTC)
+		lastBackup := time.Date(2024, 1, 7, 16, 0, 10, 0, time.UTC)
+
+		assert.True(t, interval.ShouldTriggerBackup(now, &lastBackup))
+	})
+}
+
+func TestCronSchedule_Prev(t *testing.T) {
+	t.Run("Day of month and day of week restricted: Either matches", func(t *testing.T) {
+		schedule, err := ParseCronExpression("30 4 1 * SUN")
+		assert.NoError(t, err)
+
+		prev, ok := schedule.Prev(time.Date(2024, 1, 16, 0, 0, 0, 0, time.UTC))
+		assert.True(t, ok)
+		assert.Equal(t, time.Date(2024, 1, 14, 4, 30, 0, 0, time.UTC), prev)
+	})
+
+	t.Run("Leap day schedule: Previous leap year found", func(t *testing.T) {
+		schedule, err := ParseCronExpression("0 0 29 2 *")
+		assert.NoError(t, err)
+
+		prev, ok := schedule.Prev(time.Date(2027, 3, 1, 0, 0, 0, 0, time.UTC))
+		assert.True(t, ok)
+		assert.Equal(t, time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC), prev)
+	})
+
+	t.Run("Range with step: Only stepped values match", func(t *testing.T) {
+		schedule, err := ParseCronExpression("10-40/15 * * * *")
+		assert.NoError(t, err)
+
+		prev, ok := schedule.Prev(time.Date(2024, 1, 15, 10, 39, 0, 0, time.UTC))
+		assert.True(t, ok)
+		assert.Equal(t, time.Date(2024, 1, 15, 10, 25, 0, 0, time.UTC), prev)
+	})
+}
+
 func TestInterval_Validate(t *testing.T) {
 	t.Run("Daily interval requires time of day", func(t *testing.T) {
 		interval := &Interval{
@@ -526,4 +683,62 @@ func TestInterval_Validate(t *testing.T) {
 		err := interval.Validate()
 		assert.NoError(t, err)
 	})
+
+	t.Run("Cron interval requires expression", func(t *testing.T) {
+		interval := &Interval{
+			ID:       uuid.New(),
+			Interval: IntervalCron,
+		}
+		err := interval.Validate()
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "cron expression is required")
+	})
+
+	t.Run("Invalid cron expressions are rejected", func(t *testing.T) {
+		invalidExpressions := []string{
+			"* * * *",
+			"60 * * * *",
+			"* 24 * * *",
+			"* * 0 * *",
+			"* * * 13 *",
+			"* * * * 8",
+			"*/0 * * * *",
+			"30-10 * * * *",
+			"a * * * *",
+			"1,,2 * * * *",
+			"0 0 30 2 *",
+		}
+
+		for _, expression := range invalidExpressions {
+			interval := &Interval{
+				ID:             uuid.New(),
+				Interval:       IntervalCron,
+				CronExpression: &expression,
+			}
+			assert.Error(t, interval.Validate(), expression)
+		}
+	})
+
+	t.Run("Valid cron interval", func(t *testing.T) {
+		expression := "0 2,14 * * 1-5"
+		interval := &Interval{
+			ID:             uuid.New(),
+			Interval:       IntervalCron,
+			CronExpression: &expression,
+		}
+		err := interval.Validate()
+		assert.NoError(t, err)
+	})
+
+	t.Run("Unknown timezone is rejected", func(t *testing.T) {
+		timezone := "Mars/Olympus"
+		interval := &Interval{
+			ID:       uuid.New(),
+			Interval: IntervalHourly,
+			Timezone: &timezone,
+		}
+		err := interval.Validate()
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "unknown timezone")
+	})
 }
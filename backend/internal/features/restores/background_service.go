@@ -2,9 +2,19 @@ package restores
 
 import (
 	"log/slog"
+	"os"
+	"path/filepath"
+	"time"
+
+	"postgresus-backend/internal/config"
 	"postgresus-backend/internal/features/restores/enums"
+	"postgresus-backend/internal/features/restores/models"
 )
 
+// prepared data directories are large and contain copy of the
+// database, so they are kept only long enough to be picked up
+const preparedDataDirectoryTTL = 24 * time.Hour
+
 type RestoreBackgroundService struct {
 	restoreRepository *RestoreRepository
 	logger            *slog.Logger
@@ -15,6 +25,38 @@ func (s *RestoreBackgroundService) Run() {
 		s.logger.Error("Failed to fail restores in progress", "error", err)
 		panic(err)
 	}
+
+	for {
+		if config.IsShouldShutdown() {
+			return
+		}
+
+		if err := s.removeExpiredDataDirectories(); err != nil {
+			s.logger.Error("Failed to remove expired restore data directories", "error", err)
+		}
+
+		time.Sleep(1 * time.Minute)
+	}
+}
+
+func (s *RestoreBackgroundService) removeExpiredDataDirectories() error {
+	restores, err := s.restoreRepository.FindWithDataDirectoryCreatedBefore(
+		time.Now().UTC().Add(-preparedDataDirectoryTTL),
+	)
+	if err != nil {
+		return err
+	}
+
+	for _, restore := range restores {
+		removeRestoreDirectory(s.logger, restore)
+
+		restore.DataDirectory = nil
+		if err := s.restoreRepository.Save(restore); err != nil {
+			return err
+		}
+	}
+
+	return nil
 }
 
 func (s *RestoreBackgroundService) failRestoresInProgress() error {
@@ -28,6 +70,9 @@ func (s *RestoreBackgroundService) failRestoresInProgress() error {
 		restore.Status = enums.RestoreStatusFailed
 		restore.FailMessage = &failMessage
 
+		// point-in-time restore interrupted in the middle of download
+		removeRestoreDirectory(s.logger, restore)
+
 		if err := s.restoreRepository.Save(restore); err != nil {
 			return err
 		}
@@ -35,3 +80,11 @@ func (s *RestoreBackgroundService) failRestoresInProgress() error {
 
 	return nil
 }
+
+func removeRestoreDirectory(logger *slog.Logger, restore *models.Restore) {
+	restoreDir := filepath.Join(config.GetEnv().RestoresFolder, restore.ID.String())
+
+	if err := os.RemoveAll(restoreDir); err != nil {
+		logger.Error("Failed to remove restore directory", "directory", restoreDir, "error", err)
+	}
+}
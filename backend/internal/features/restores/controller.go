@@ -15,6 +15,10 @@ type RestoreController struct {
 func (c *RestoreController) RegisterRoutes(router *gin.RouterGroup) {
 	router.GET("/restores/:backupId", c.GetRestores)
 	router.POST("/restores/:backupId/restore", c.RestoreBackup)
+	router.POST(
+		"/restores/:backupId/point-in-time-data-directory",
+		c.PreparePointInTimeDataDirectory,
+	)
 }
 
 // GetRestores
@@ -84,3 +88,46 @@ func (c *RestoreController) RestoreBackup(ctx *gin.Context) {
 
 	ctx.JSON(http.StatusOK, gin.H{"message": "restore started successfully"})
 }
+
+// PreparePointInTimeDataDirectory
+// @Summary Prepare data directory for point-in-time recovery
+// @Description Prepare a data directory from the physical base backup and archived WAL. PostgreSQL started on the directory replays WAL up to the target time or LSN. The directory is removed after 24 hours
+// @Tags restores
+// @Accept json
+// @Produce json
+// @Param backupId path string true "Backup ID"
+// @Param request body PointInTimeRestoreRequest true "Recovery target"
+// @Success 200 {object} map[string]string
+// @Failure 400
+// @Failure 401
+// @Router /restores/{backupId}/point-in-time-data-directory [post]
+func (c *RestoreController) PreparePointInTimeDataDirectory(ctx *gin.Context) {
+	user, ok := users_middleware.GetUserFromContext(ctx)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	backupID, err := uuid.Parse(ctx.Param("backupId"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid backup ID"})
+		return
+	}
+
+	var requestDTO PointInTimeRestoreRequest
+	if err := ctx.ShouldBindJSON(&requestDTO); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := c.restoreService.PreparePointInTimeDataDirectoryWithAuth(
+		user,
+		backupID,
+		requestDTO,
+	); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "data directory preparation started successfully"})
+}
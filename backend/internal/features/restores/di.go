@@ -6,7 +6,6 @@ import (
 	backups_config "postgresus-backend/internal/features/backups/config"
 	"postgresus-backend/internal/features/databases"
 	"postgresus-backend/internal/features/restores/usecases"
-	"postgresus-backend/internal/features/storages"
 	workspaces_services "postgresus-backend/internal/features/workspaces/services"
 	"postgresus-backend/internal/util/encryption"
 	"postgresus-backend/internal/util/logger"
@@ -16,7 +15,6 @@ var restoreRepository = &RestoreRepository{}
 var restoreService = &RestoreService{
 	backups.GetBackupService(),
 	restoreRepository,
-	storages.GetStorageService(),
 	backups_config.GetBackupConfigService(),
 	usecases.GetRestoreBackupUsecase(),
 	databases.GetDatabaseService(),
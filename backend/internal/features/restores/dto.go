@@ -1,9 +1,51 @@
 package restores
 
 import (
+	"errors"
 	"postgresus-backend/internal/features/databases/databases/postgresql"
+	"regexp"
+	"time"
+
+	"github.com/google/uuid"
 )
 
+var lsnRegex = regexp.MustCompile(`^[0-9A-Fa-f]{1,8}/[0-9A-Fa-f]{1,8}$`)
+
 type RestoreBackupRequest struct {
 	PostgresqlDatabase *postgresql.PostgresqlDatabase `json:"postgresqlDatabase"`
+
+	// applies roles and tablespaces saved with the backup before pg_restore
+	IsRestoreGlobals bool `json:"isRestoreGlobals"`
+
+	// required for whole-server backups to choose the database to restore
+	ArtifactID *uuid.UUID `json:"artifactId"`
+
+	// storage of the backup or of its copy to restore from,
+	// by default the backup storage or any completed copy
+	StorageID *uuid.UUID `json:"storageId"`
+}
+
+type PointInTimeRestoreRequest struct {
+	// when both targets are empty, WAL is replayed up to the end of archive
+	TargetTime *time.Time `json:"targetTime"`
+	TargetLsn  *string    `json:"targetLsn"`
+
+	// storage of the backup or of its copy to restore from
+	StorageID *uuid.UUID `json:"storageId"`
+}
+
+func (r *PointInTimeRestoreRequest) Validate() error {
+	if r.TargetTime != nil && r.TargetLsn != nil {
+		return errors.New("only one of target time or target LSN can be specified")
+	}
+
+	if r.TargetLsn != nil && !lsnRegex.MatchString(*r.TargetLsn) {
+		return errors.New("target LSN should be in format XXXXXXXX/XXXXXXXX")
+	}
+
+	if r.TargetTime != nil && r.TargetTime.After(time.Now().UTC()) {
+		return errors.New("target time cannot be in the future")
+	}
+
+	return nil
 }
@@ -6,4 +6,8 @@ const (
 	RestoreStatusInProgress RestoreStatus = "IN_PROGRESS"
 	RestoreStatusCompleted  RestoreStatus = "COMPLETED"
 	RestoreStatusFailed     RestoreStatus = "FAILED"
+
+	// point-in-time restore prepared PGDATA directory, WAL is
+	// replayed by PostgreSQL when it is started on the directory
+	RestoreStatusDataDirectoryPrepared RestoreStatus = "DATA_DIRECTORY_PREPARED"
 )
@@ -15,8 +15,16 @@ type Restore struct {
 	BackupID uuid.UUID `json:"backupId" gorm:"column:backup_id;type:uuid;not null"`
 	Backup   *backups.Backup
 
+	// database of whole-server backup which is restored
+	ArtifactID *uuid.UUID `json:"artifactId" gorm:"column:artifact_id;type:uuid"`
+
 	FailMessage *string `json:"failMessage" gorm:"column:fail_message"`
 
+	// filled only for point-in-time restores
+	RecoveryTargetTime *time.Time `json:"recoveryTargetTime" gorm:"column:recovery_target_time"`
+	RecoveryTargetLsn  *string    `json:"recoveryTargetLsn"  gorm:"column:recovery_target_lsn"`
+	DataDirectory      *string    `json:"dataDirectory"      gorm:"column:data_directory"`
+
 	RestoreDurationMs int64     `json:"restoreDurationMs" gorm:"column:restore_duration_ms;default:0"`
 	CreatedAt         time.Time `json:"createdAt"         gorm:"column:created_at;default:now()"`
 }
@@ -4,6 +4,7 @@ import (
 	"postgresus-backend/internal/features/restores/enums"
 	"postgresus-backend/internal/features/restores/models"
 	"postgresus-backend/internal/storage"
+	"time"
 
 	"github.com/google/uuid"
 )
@@ -70,6 +71,21 @@ func (r *RestoreRepository) FindByStatus(status enums.RestoreStatus) ([]*models.
 	return restores, nil
 }
 
+func (r *RestoreRepository) FindWithDataDirectoryCreatedBefore(
+	createdBefore time.Time,
+) ([]*models.Restore, error) {
+	var restores []*models.Restore
+
+	if err := storage.
+		GetDb().
+		Where("data_directory IS NOT NULL AND created_at < ?", createdBefore).
+		Find(&restores).Error; err != nil {
+		return nil, err
+	}
+
+	return restores, nil
+}
+
 func (r *RestoreRepository) DeleteByID(id uuid.UUID) error {
 	return storage.GetDb().Delete(&models.Restore{}, "id = ?", id).Error
 }
@@ -11,7 +11,6 @@ import (
 	"postgresus-backend/internal/features/restores/enums"
 	"postgresus-backend/internal/features/restores/models"
 	"postgresus-backend/internal/features/restores/usecases"
-	"postgresus-backend/internal/features/storages"
 	users_models "postgresus-backend/internal/features/users/models"
 	workspaces_services "postgresus-backend/internal/features/workspaces/services"
 	"postgresus-backend/internal/util/encryption"
@@ -24,7 +23,6 @@ import (
 type RestoreService struct {
 	backupService        *backups.BackupService
 	restoreRepository    *RestoreRepository
-	storageService       *storages.StorageService
 	backupConfigService  *backups_config.BackupConfigService
 	restoreBackupUsecase *usecases.RestoreBackupUsecase
 	databaseService      *databases.DatabaseService
@@ -47,6 +45,8 @@ func (s *RestoreService) OnBeforeBackupRemove(backup *backups.Backup) error {
 	}
 
 	for _, restore := range restores {
+		removeRestoreDirectory(s.logger, restore)
+
 		if err := s.restoreRepository.DeleteByID(restore.ID); err != nil {
 			return err
 		}
@@ -117,6 +117,22 @@ func (s *RestoreService) RestoreBackupWithAuth(
 		return errors.New("insufficient permissions to restore this backup")
 	}
 
+	if backup.Method == backups_config.BackupMethodPgBasebackup {
+		return errors.New("physical base backup cannot be restored via pg_restore, use point-in-time restore")
+	}
+
+	if requestDTO.IsRestoreGlobals && backup.GlobalsFileID == nil {
+		return errors.New("backup does not contain globals, it was made without globals backup enabled")
+	}
+
+	if err := validateRestoreArtifact(backup, requestDTO.ArtifactID); err != nil {
+		return err
+	}
+
+	if _, err := s.backupService.GetBackupStorage(backup, requestDTO.StorageID); err != nil {
+		return err
+	}
+
 	backupDatabase, err := s.databaseService.GetDatabase(user, backup.DatabaseID)
 	if err != nil {
 		return err
@@ -150,6 +166,75 @@ func (s *RestoreService) RestoreBackupWithAuth(
 	return nil
 }
 
+func (s *RestoreService) PreparePointInTimeDataDirectoryWithAuth(
+	user *users_models.User,
+	backupID uuid.UUID,
+	requestDTO PointInTimeRestoreRequest,
+) error {
+	if err := requestDTO.Validate(); err != nil {
+		return err
+	}
+
+	backup, err := s.backupService.GetBackup(backupID)
+	if err != nil {
+		return err
+	}
+
+	database, err := s.databaseService.GetDatabaseByID(backup.DatabaseID)
+	if err != nil {
+		return err
+	}
+
+	if database.WorkspaceID == nil {
+		return errors.New("cannot restore backup for database without workspace")
+	}
+
+	canAccess, _, err := s.workspaceService.CanUserAccessWorkspace(
+		*database.WorkspaceID,
+		user,
+	)
+	if err != nil {
+		return err
+	}
+	if !canAccess {
+		return errors.New("insufficient permissions to restore this backup")
+	}
+
+	if backup.Status != backups.BackupStatusCompleted {
+		return errors.New("backup is not completed")
+	}
+
+	if backup.Method != backups_config.BackupMethodPgBasebackup {
+		return errors.New("point-in-time restore requires physical base backup made via pg_basebackup")
+	}
+
+	if requestDTO.TargetTime != nil && requestDTO.TargetTime.Before(backup.CreatedAt) {
+		return errors.New("target time cannot be before the backup creation time")
+	}
+
+	if _, err := s.backupService.GetBackupStorage(backup, requestDTO.StorageID); err != nil {
+		return err
+	}
+
+	go func() {
+		if err := s.PreparePointInTimeDataDirectory(backup, requestDTO); err != nil {
+			s.logger.Error("Failed to prepare point-in-time data directory", "error", err)
+		}
+	}()
+
+	s.auditLogService.WriteAuditLog(
+		fmt.Sprintf(
+			"Point-in-time data directory preparation started from backup %s for database: %s",
+			backupID.String(),
+			database.Name,
+		),
+		&user.ID,
+		database.WorkspaceID,
+	)
+
+	return nil
+}
+
 func (s *RestoreService) RestoreBackup(
 	backup *backups.Backup,
 	requestDTO RestoreBackupRequest,
@@ -173,8 +258,9 @@ func (s *RestoreService) RestoreBackup(
 		ID:     uuid.New(),
 		Status: enums.RestoreStatusInProgress,
 
-		BackupID: backup.ID,
-		Backup:   backup,
+		BackupID:   backup.ID,
+		Backup:     backup,
+		ArtifactID: requestDTO.ArtifactID,
 
 		CreatedAt:         time.Now().UTC(),
 		RestoreDurationMs: 0,
@@ -192,7 +278,7 @@ func (s *RestoreService) RestoreBackup(
 		return err
 	}
 
-	storage, err := s.storageService.GetStorageByID(backup.StorageID)
+	storage, err := s.backupService.GetBackupStorage(backup, requestDTO.StorageID)
 	if err != nil {
 		return err
 	}
@@ -221,6 +307,7 @@ func (s *RestoreService) RestoreBackup(
 		restoringToDB,
 		backup,
 		storage,
+		requestDTO.IsRestoreGlobals,
 	)
 	if err != nil {
 		errMsg := err.Error()
@@ -244,3 +331,85 @@ func (s *RestoreService) RestoreBackup(
 
 	return nil
 }
+
+func (s *RestoreService) PreparePointInTimeDataDirectory(
+	backup *backups.Backup,
+	requestDTO PointInTimeRestoreRequest,
+) error {
+	database, err := s.databaseService.GetDatabaseByID(backup.DatabaseID)
+	if err != nil {
+		return err
+	}
+
+	restore := models.Restore{
+		ID:     uuid.New(),
+		Status: enums.RestoreStatusInProgress,
+
+		BackupID: backup.ID,
+		Backup:   backup,
+
+		RecoveryTargetTime: requestDTO.TargetTime,
+		RecoveryTargetLsn:  requestDTO.TargetLsn,
+
+		CreatedAt:         time.Now().UTC(),
+		RestoreDurationMs: 0,
+	}
+
+	if err := s.restoreRepository.Save(&restore); err != nil {
+		return err
+	}
+
+	storage, err := s.backupService.GetBackupStorage(backup, requestDTO.StorageID)
+	if err != nil {
+		return err
+	}
+
+	start := time.Now().UTC()
+
+	dataDirectory, err := s.restoreBackupUsecase.ExecutePointInTime(
+		restore,
+		database,
+		backup,
+		storage,
+	)
+	if err != nil {
+		errMsg := err.Error()
+		restore.FailMessage = &errMsg
+		restore.Status = enums.RestoreStatusFailed
+		restore.RestoreDurationMs = time.Since(start).Milliseconds()
+
+		if err := s.restoreRepository.Save(&restore); err != nil {
+			return err
+		}
+
+		return err
+	}
+
+	restore.DataDirectory = &dataDirectory
+	restore.Status = enums.RestoreStatusDataDirectoryPrepared
+	restore.RestoreDurationMs = time.Since(start).Milliseconds()
+
+	return s.restoreRepository.Save(&restore)
+}
+
+func validateRestoreArtifact(backup *backups.Backup, artifactID *uuid.UUID) error {
+	if !backup.IsWholeServer {
+		if artifactID != nil {
+			return errors.New("backup is not a whole-server backup, artifact cannot be chosen")
+		}
+
+		return nil
+	}
+
+	if artifactID == nil {
+		return errors.New("whole-server backup contains several databases, choose artifact to restore")
+	}
+
+	for _, artifact := range backup.Artifacts {
+		if artifact.ID == *artifactID {
+			return nil
+		}
+	}
+
+	return errors.New("artifact not found in the backup")
+}
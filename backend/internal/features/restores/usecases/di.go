@@ -6,6 +6,7 @@ import (
 
 var restoreBackupUsecase = &RestoreBackupUsecase{
 	usecases_postgresql.GetRestorePostgresqlBackupUsecase(),
+	usecases_postgresql.GetRestorePostgresqlPointInTimeUsecase(),
 }
 
 func GetRestoreBackupUsecase() *RestoreBackupUsecase {
@@ -0,0 +1,170 @@
+package usecases_postgresql
+
+import (
+	"encoding/base64"
+	"fmt"
+	"io"
+
+	"postgresus-backend/internal/features/backups/backups"
+	"postgresus-backend/internal/features/backups/backups/encryption"
+	backups_config "postgresus-backend/internal/features/backups/config"
+	encryption_secrets "postgresus-backend/internal/features/encryption/secrets"
+	"postgresus-backend/internal/features/storages"
+	"postgresus-backend/internal/util/checksum"
+	util_encryption "postgresus-backend/internal/util/encryption"
+
+	"github.com/google/uuid"
+)
+
+type decryptionReaderCloser struct {
+	*encryption.DecryptionReader
+	baseReader io.ReadCloser
+}
+
+func (r *decryptionReaderCloser) Close() error {
+	return r.baseReader.Close()
+}
+
+// getBackupReader returns the backup file reader, decrypting it
+// on-the-fly when the backup is encrypted. For whole-server backups
+// the reader of the artifact with given ID is returned
+func getBackupReader(
+	secretKeyService *encryption_secrets.SecretKeyService,
+	backup *backups.Backup,
+	storage *storages.Storage,
+	artifactID *uuid.UUID,
+) (io.ReadCloser, error) {
+	if !backup.IsWholeServer {
+		return getStorageFileReader(
+			secretKeyService,
+			storage,
+			backup.ID,
+			backup.Encryption,
+			backup.EncryptionSalt,
+			backup.EncryptionIV,
+			backup.Checksum,
+		)
+	}
+
+	if artifactID == nil {
+		return nil, fmt.Errorf("whole-server backup contains several databases, artifact ID is required")
+	}
+
+	for _, artifact := range backup.Artifacts {
+		if artifact.ID == *artifactID {
+			return getStorageFileReader(
+				secretKeyService,
+				storage,
+				artifact.ID,
+				backup.Encryption,
+				artifact.EncryptionSalt,
+				artifact.EncryptionIV,
+				artifact.Checksum,
+			)
+		}
+	}
+
+	return nil, fmt.Errorf("artifact not found in the backup")
+}
+
+func getGlobalsReader(
+	secretKeyService *encryption_secrets.SecretKeyService,
+	backup *backups.Backup,
+	storage *storages.Storage,
+) (io.ReadCloser, error) {
+	if backup.GlobalsFileID == nil {
+		return nil, fmt.Errorf("backup does not contain globals")
+	}
+
+	return getStorageFileReader(
+		secretKeyService,
+		storage,
+		*backup.GlobalsFileID,
+		backup.Encryption,
+		backup.GlobalsEncryptionSalt,
+		backup.GlobalsEncryptionIV,
+		backup.GlobalsChecksum,
+	)
+}
+
+func getStorageFileReader(
+	secretKeyService *encryption_secrets.SecretKeyService,
+	storage *storages.Storage,
+	fileID uuid.UUID,
+	fileEncryption backups_config.BackupEncryption,
+	encryptionSalt *string,
+	encryptionIV *string,
+	fileChecksum *string,
+) (io.ReadCloser, error) {
+	fieldEncryptor := util_encryption.GetFieldEncryptor()
+	rawReader, err := storage.GetFile(fieldEncryptor, fileID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get backup file from storage: %w", err)
+	}
+
+	// backups made before checksums were introduced have no checksum
+	if fileChecksum != nil {
+		rawReader = checksum.NewVerifyingReader(rawReader, *fileChecksum)
+	}
+
+	if fileEncryption != backups_config.BackupEncryptionEncrypted {
+		return rawReader, nil
+	}
+
+	decryptionReader, err := createDecryptionReader(
+		secretKeyService,
+		fileID,
+		encryptionSalt,
+		encryptionIV,
+		rawReader,
+	)
+	if err != nil {
+		_ = rawReader.Close()
+		return nil, err
+	}
+
+	return &decryptionReaderCloser{
+		decryptionReader,
+		rawReader,
+	}, nil
+}
+
+func createDecryptionReader(
+	secretKeyService *encryption_secrets.SecretKeyService,
+	fileID uuid.UUID,
+	encryptionSalt *string,
+	encryptionIV *string,
+	rawReader io.Reader,
+) (*encryption.DecryptionReader, error) {
+	if encryptionSalt == nil || encryptionIV == nil {
+		return nil, fmt.Errorf("backup is encrypted but missing encryption metadata")
+	}
+
+	masterKey, err := secretKeyService.GetSecretKey()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get master key for decryption: %w", err)
+	}
+
+	salt, err := base64.StdEncoding.DecodeString(*encryptionSalt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode encryption salt: %w", err)
+	}
+
+	iv, err := base64.StdEncoding.DecodeString(*encryptionIV)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode encryption IV: %w", err)
+	}
+
+	decryptionReader, err := encryption.NewDecryptionReader(
+		rawReader,
+		masterKey,
+		fileID,
+		salt,
+		iv,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create decryption reader: %w", err)
+	}
+
+	return decryptionReader, nil
+}
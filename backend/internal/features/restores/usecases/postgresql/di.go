@@ -1,7 +1,9 @@
 package usecases_postgresql
 
 import (
+	backups_wal "postgresus-backend/internal/features/backups/wal"
 	"postgresus-backend/internal/features/encryption/secrets"
+	"postgresus-backend/internal/features/storages"
 	"postgresus-backend/internal/util/logger"
 )
 
@@ -10,6 +12,17 @@ var restorePostgresqlBackupUsecase = &RestorePostgresqlBackupUsecase{
 	secrets.GetSecretKeyService(),
 }
 
+var restorePostgresqlPointInTimeUsecase = &RestorePostgresqlPointInTimeUsecase{
+	logger.GetLogger(),
+	secrets.GetSecretKeyService(),
+	backups_wal.GetWalArchiveService(),
+	storages.GetStorageService(),
+}
+
 func GetRestorePostgresqlBackupUsecase() *RestorePostgresqlBackupUsecase {
 	return restorePostgresqlBackupUsecase
 }
+
+func GetRestorePostgresqlPointInTimeUsecase() *RestorePostgresqlPointInTimeUsecase {
+	return restorePostgresqlPointInTimeUsecase
+}
@@ -0,0 +1,67 @@
+package usecases_postgresql
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	pgtypes "postgresus-backend/internal/features/databases/databases/postgresql"
+)
+
+var (
+	createRoleRegex       = regexp.MustCompile(`^CREATE ROLE (.+);$`)
+	createTablespaceRegex = regexp.MustCompile(`^CREATE TABLESPACE ("(?:[^"]|"")+"|\S+) `)
+)
+
+// prepareGlobalsScript makes pg_dumpall --globals-only output safe to apply
+// with ON_ERROR_STOP: roles and tablespaces existing on the target server
+// are skipped, any other error stops psql and fails the restore
+func prepareGlobalsScript(script string) string {
+	lines := strings.Split(script, "\n")
+	prepared := make([]string, 0, len(lines))
+
+	for _, line := range lines {
+		if match := createRoleRegex.FindStringSubmatch(line); match != nil {
+			prepared = append(prepared, fmt.Sprintf(
+				"DO $postgresus$ BEGIN CREATE ROLE %s; "+
+					"EXCEPTION WHEN duplicate_object THEN RAISE NOTICE '%%', SQLERRM; END $postgresus$;",
+				match[1],
+			))
+			continue
+		}
+
+		// CREATE TABLESPACE cannot run inside DO block,
+		// so existence is checked by psql itself
+		if match := createTablespaceRegex.FindStringSubmatch(line); match != nil {
+			prepared = append(prepared,
+				fmt.Sprintf(
+					"SELECT NOT EXISTS (SELECT 1 FROM pg_tablespace WHERE quote_ident(spcname) = '%s') "+
+						"AS postgresus_create_tablespace \\gset",
+					strings.ReplaceAll(match[1], "'", "''"),
+				),
+				`\if :postgresus_create_tablespace`,
+				line,
+				`\endif`,
+			)
+			continue
+		}
+
+		prepared = append(prepared, line)
+	}
+
+	return strings.Join(prepared, "\n")
+}
+
+func buildPsqlGlobalsArgs(pgConfig *pgtypes.PostgresqlDatabase, globalsFile string) []string {
+	return []string{
+		"--no-password",
+		"-h", pgConfig.Host,
+		"-p", strconv.Itoa(pgConfig.Port),
+		"-U", pgConfig.Username,
+		"-d", *pgConfig.Database,
+		// psql exits with 0 on failed statements without it
+		"-v", "ON_ERROR_STOP=1",
+		"-f", globalsFile,
+	}
+}
@@ -0,0 +1,61 @@
+package usecases_postgresql
+
+import (
+	"testing"
+
+	pgtypes "postgresus-backend/internal/features/databases/databases/postgresql"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_PrepareGlobalsScript_CreateRole_ExistingRoleSkipped(t *testing.T) {
+	script := "CREATE ROLE app;\nALTER ROLE app WITH LOGIN;\nCREATE ROLE \"read-only\";"
+
+	prepared := prepareGlobalsScript(script)
+
+	assert.Equal(
+		t,
+		"DO $postgresus$ BEGIN CREATE ROLE app; "+
+			"EXCEPTION WHEN duplicate_object THEN RAISE NOTICE '%', SQLERRM; END $postgresus$;\n"+
+			"ALTER ROLE app WITH LOGIN;\n"+
+			"DO $postgresus$ BEGIN CREATE ROLE \"read-only\"; "+
+			"EXCEPTION WHEN duplicate_object THEN RAISE NOTICE '%', SQLERRM; END $postgresus$;",
+		prepared,
+	)
+}
+
+func Test_PrepareGlobalsScript_CreateTablespace_CreatedOnlyIfMissing(t *testing.T) {
+	script := "CREATE TABLESPACE \"fast ssd\" OWNER postgres LOCATION '/mnt/ssd';"
+
+	prepared := prepareGlobalsScript(script)
+
+	assert.Equal(
+		t,
+		"SELECT NOT EXISTS (SELECT 1 FROM pg_tablespace WHERE quote_ident(spcname) = '\"fast ssd\"') "+
+			"AS postgresus_create_tablespace \\gset\n"+
+			"\\if :postgresus_create_tablespace\n"+
+			"CREATE TABLESPACE \"fast ssd\" OWNER postgres LOCATION '/mnt/ssd';\n"+
+			"\\endif",
+		prepared,
+	)
+}
+
+func Test_PrepareGlobalsScript_OtherStatements_KeptAsIs(t *testing.T) {
+	script := "SET default_transaction_read_only = off;\n\nGRANT app TO reporter GRANTED BY postgres;\n"
+
+	assert.Equal(t, script, prepareGlobalsScript(script))
+}
+
+func Test_BuildPsqlGlobalsArgs_StopsOnError(t *testing.T) {
+	database := "app"
+
+	args := buildPsqlGlobalsArgs(&pgtypes.PostgresqlDatabase{
+		Host:     "localhost",
+		Port:     5432,
+		Username: "postgres",
+		Database: &database,
+	}, "/tmp/globals.sql")
+
+	assert.Contains(t, args, "ON_ERROR_STOP=1")
+	assert.Equal(t, []string{"-f", "/tmp/globals.sql"}, args[len(args)-2:])
+}
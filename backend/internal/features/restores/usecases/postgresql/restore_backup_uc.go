@@ -2,7 +2,6 @@ package usecases_postgresql
 
 import (
 	"context"
-	"encoding/base64"
 	"errors"
 	"fmt"
 	"io"
@@ -16,14 +15,12 @@ import (
 
 	"postgresus-backend/internal/config"
 	"postgresus-backend/internal/features/backups/backups"
-	"postgresus-backend/internal/features/backups/backups/encryption"
 	backups_config "postgresus-backend/internal/features/backups/config"
 	"postgresus-backend/internal/features/databases"
 	pgtypes "postgresus-backend/internal/features/databases/databases/postgresql"
 	encryption_secrets "postgresus-backend/internal/features/encryption/secrets"
 	"postgresus-backend/internal/features/restores/models"
 	"postgresus-backend/internal/features/storages"
-	util_encryption "postgresus-backend/internal/util/encryption"
 	files_utils "postgresus-backend/internal/util/files"
 	"postgresus-backend/internal/util/tools"
 
@@ -42,17 +39,20 @@ func (uc *RestorePostgresqlBackupUsecase) Execute(
 	restore models.Restore,
 	backup *backups.Backup,
 	storage *storages.Storage,
+	isRestoreGlobals bool,
 ) error {
 	if originalDB.Type != databases.DatabaseTypePostgres {
 		return errors.New("database type not supported")
 	}
 
 	uc.logger.Info(
-		"Restoring PostgreSQL backup via pg_restore",
+		"Restoring PostgreSQL backup",
 		"restoreId",
 		restore.ID,
 		"backupId",
 		backup.ID,
+		"format",
+		backup.Format,
 	)
 
 	pg := restoringToDB.Postgresql
@@ -64,12 +64,21 @@ func (uc *RestorePostgresqlBackupUsecase) Execute(
 		return fmt.Errorf("target database name is required for pg_restore")
 	}
 
+	if backup.Format == backups_config.BackupFormatPlain {
+		return uc.restorePlainFromStorage(originalDB, backup, storage, pg, isRestoreGlobals, restore)
+	}
+
 	// Use parallel jobs based on CPU count (same as backup)
 	// Cap between 1 and 8 to avoid overwhelming the server
 	parallelJobs := max(1, min(backupConfig.CpuCount, 8))
 
+	formatArg := "-Fc"
+	if backup.Format == backups_config.BackupFormatDirectory {
+		formatArg = "-Fd"
+	}
+
 	args := []string{
-		"-Fc",                            // expect custom format (same as backup)
+		formatArg,                        // expect the same format as backup
 		"-j", strconv.Itoa(parallelJobs), // parallel jobs based on CPU count
 		"--no-password", // Use environment variable for password, prevent prompts
 		"-h", pg.Host,
@@ -96,6 +105,45 @@ func (uc *RestorePostgresqlBackupUsecase) Execute(
 		backup,
 		storage,
 		pg,
+		isRestoreGlobals,
+		restore.ArtifactID,
+	)
+}
+
+// restorePlainFromStorage restores plain SQL backup via psql. The dump is
+// made without owners and privileges, so it is applied as is
+func (uc *RestorePostgresqlBackupUsecase) restorePlainFromStorage(
+	originalDB *databases.Database,
+	backup *backups.Backup,
+	storage *storages.Storage,
+	pg *pgtypes.PostgresqlDatabase,
+	isRestoreGlobals bool,
+	restore models.Restore,
+) error {
+	args := []string{
+		"--no-password",
+		"-h", pg.Host,
+		"-p", strconv.Itoa(pg.Port),
+		"-U", pg.Username,
+		"-d", *pg.Database,
+		"-v", "ON_ERROR_STOP=1",
+	}
+
+	return uc.restoreFromStorage(
+		originalDB,
+		tools.GetPostgresqlExecutable(
+			pg.Version,
+			"psql",
+			config.GetEnv().EnvMode,
+			config.GetEnv().PostgresesInstallDir,
+		),
+		args,
+		pg.Password,
+		backup,
+		storage,
+		pg,
+		isRestoreGlobals,
+		restore.ArtifactID,
 	)
 }
 
@@ -108,6 +156,8 @@ func (uc *RestorePostgresqlBackupUsecase) restoreFromStorage(
 	backup *backups.Backup,
 	storage *storages.Storage,
 	pgConfig *pgtypes.PostgresqlDatabase,
+	isRestoreGlobals bool,
+	artifactID *uuid.UUID,
 ) error {
 	uc.logger.Info(
 		"Restoring PostgreSQL backup from storage via temporary file",
@@ -139,7 +189,7 @@ func (uc *RestorePostgresqlBackupUsecase) restoreFromStorage(
 	}()
 
 	// Create temporary .pgpass file for authentication
-	pgpassFile, err := uc.createTempPgpassFile(pgConfig, password)
+	pgpassFile, err := tools.CreateTempPgpassFile(pgConfig.Host, pgConfig.Port, pgConfig.Username, password)
 	if err != nil {
 		return fmt.Errorf("failed to create temporary .pgpass file: %w", err)
 	}
@@ -165,14 +215,45 @@ func (uc *RestorePostgresqlBackupUsecase) restoreFromStorage(
 	}
 
 	// Download backup to temporary file
-	tempBackupFile, cleanupFunc, err := uc.downloadBackupToTempFile(ctx, backup, storage)
+	tempBackupFile, cleanupFunc, err := uc.downloadBackupToTempFile(
+		ctx,
+		backup,
+		storage,
+		artifactID,
+	)
 	if err != nil {
 		return fmt.Errorf("failed to download backup to temporary file: %w", err)
 	}
 	defer cleanupFunc()
 
+	// roles should exist before pg_restore, otherwise grants to them fail
+	if isRestoreGlobals {
+		if err := uc.restoreGlobals(
+			ctx,
+			database,
+			backup,
+			storage,
+			filepath.Join(filepath.Dir(tempBackupFile), "globals.sql"),
+			pgpassFile,
+			pgConfig,
+		); err != nil {
+			return err
+		}
+	}
+
+	if backup.Format == backups_config.BackupFormatDirectory {
+		tempBackupFile, err = uc.extractDirectoryBackup(tempBackupFile)
+		if err != nil {
+			return err
+		}
+	}
+
 	// Add the temporary backup file as the last argument to pg_restore
-	args = append(args, tempBackupFile)
+	if backup.Format == backups_config.BackupFormatPlain {
+		args = append(args, "-f", tempBackupFile)
+	} else {
+		args = append(args, tempBackupFile)
+	}
 
 	return uc.executePgRestore(ctx, database, pgBin, args, pgpassFile, pgConfig)
 }
@@ -182,6 +263,7 @@ func (uc *RestorePostgresqlBackupUsecase) downloadBackupToTempFile(
 	ctx context.Context,
 	backup *backups.Backup,
 	storage *storages.Storage,
+	artifactID *uuid.UUID,
 ) (string, func(), error) {
 	err := files_utils.EnsureDirectories([]string{
 		config.GetEnv().TempFolder,
@@ -212,64 +294,17 @@ func (uc *RestorePostgresqlBackupUsecase) downloadBackupToTempFile(
 		"encrypted",
 		backup.Encryption == backups_config.BackupEncryptionEncrypted,
 	)
-	fieldEncryptor := util_encryption.GetFieldEncryptor()
-	rawReader, err := storage.GetFile(fieldEncryptor, backup.ID)
+	backupReader, err := getBackupReader(uc.secretKeyService, backup, storage, artifactID)
 	if err != nil {
 		cleanupFunc()
-		return "", nil, fmt.Errorf("failed to get backup file from storage: %w", err)
+		return "", nil, err
 	}
 	defer func() {
-		if err := rawReader.Close(); err != nil {
+		if err := backupReader.Close(); err != nil {
 			uc.logger.Error("Failed to close backup reader", "error", err)
 		}
 	}()
 
-	// Create a reader that handles decryption if needed
-	var backupReader io.Reader = rawReader
-	if backup.Encryption == backups_config.BackupEncryptionEncrypted {
-		// Validate encryption metadata
-		if backup.EncryptionSalt == nil || backup.EncryptionIV == nil {
-			cleanupFunc()
-			return "", nil, fmt.Errorf("backup is encrypted but missing encryption metadata")
-		}
-
-		// Get master key
-		masterKey, err := uc.secretKeyService.GetSecretKey()
-		if err != nil {
-			cleanupFunc()
-			return "", nil, fmt.Errorf("failed to get master key for decryption: %w", err)
-		}
-
-		// Decode salt and IV from base64
-		salt, err := base64.StdEncoding.DecodeString(*backup.EncryptionSalt)
-		if err != nil {
-			cleanupFunc()
-			return "", nil, fmt.Errorf("failed to decode encryption salt: %w", err)
-		}
-
-		iv, err := base64.StdEncoding.DecodeString(*backup.EncryptionIV)
-		if err != nil {
-			cleanupFunc()
-			return "", nil, fmt.Errorf("failed to decode encryption IV: %w", err)
-		}
-
-		// Create decryption reader
-		decryptReader, err := encryption.NewDecryptionReader(
-			rawReader,
-			masterKey,
-			backup.ID,
-			salt,
-			iv,
-		)
-		if err != nil {
-			cleanupFunc()
-			return "", nil, fmt.Errorf("failed to create decryption reader: %w", err)
-		}
-
-		backupReader = decryptReader
-		uc.logger.Info("Using decryption for encrypted backup", "backupId", backup.ID)
-	}
-
 	// Create temporary backup file
 	tempFile, err := os.Create(tempBackupFile)
 	if err != nil {
@@ -296,6 +331,91 @@ func (uc *RestorePostgresqlBackupUsecase) downloadBackupToTempFile(
 	return tempBackupFile, cleanupFunc, nil
 }
 
+// extractDirectoryBackup unpacks tar of directory format backup
+// next to it, so pg_restore can read the dump in parallel
+func (uc *RestorePostgresqlBackupUsecase) extractDirectoryBackup(
+	tempBackupFile string,
+) (string, error) {
+	dumpDir := filepath.Join(filepath.Dir(tempBackupFile), "backup_dir")
+
+	file, err := os.Open(tempBackupFile)
+	if err != nil {
+		return "", fmt.Errorf("failed to open temporary backup file: %w", err)
+	}
+	defer func() {
+		_ = file.Close()
+	}()
+
+	if err := files_utils.ExtractTarToDirectory(file, dumpDir); err != nil {
+		return "", fmt.Errorf("failed to extract directory backup: %w", err)
+	}
+
+	// tar is not needed anymore, large dumps should not be kept twice
+	_ = file.Close()
+	if err := os.Remove(tempBackupFile); err != nil {
+		uc.logger.Warn("Failed to remove temporary backup file", "error", err)
+	}
+
+	uc.logger.Info("Directory backup extracted", "directory", dumpDir)
+	return dumpDir, nil
+}
+
+// restoreGlobals applies pg_dumpall --globals-only output via psql. Already
+// existing roles and tablespaces are skipped, other errors fail the restore
+func (uc *RestorePostgresqlBackupUsecase) restoreGlobals(
+	ctx context.Context,
+	database *databases.Database,
+	backup *backups.Backup,
+	storage *storages.Storage,
+	globalsFile string,
+	pgpassFile string,
+	pgConfig *pgtypes.PostgresqlDatabase,
+) error {
+	if backup.GlobalsFileID == nil {
+		return errors.New("backup does not contain globals")
+	}
+
+	uc.logger.Info("Restoring globals before pg_restore", "backupId", backup.ID)
+
+	globalsReader, err := getGlobalsReader(uc.secretKeyService, backup, storage)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := globalsReader.Close(); err != nil {
+			uc.logger.Error("Failed to close globals reader", "error", err)
+		}
+	}()
+
+	var globals strings.Builder
+	if _, err := uc.copyWithShutdownCheck(ctx, &globals, globalsReader); err != nil {
+		return fmt.Errorf("failed to read globals: %w", err)
+	}
+
+	if err := os.WriteFile(globalsFile, []byte(prepareGlobalsScript(globals.String())), 0600); err != nil {
+		return fmt.Errorf("failed to write globals to temporary file: %w", err)
+	}
+
+	err = uc.executePgRestore(
+		ctx,
+		database,
+		tools.GetPostgresqlExecutable(
+			pgConfig.Version,
+			"psql",
+			config.GetEnv().EnvMode,
+			config.GetEnv().PostgresesInstallDir,
+		),
+		buildPsqlGlobalsArgs(pgConfig, globalsFile),
+		pgpassFile,
+		pgConfig,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to restore globals: %w", err)
+	}
+
+	return nil
+}
+
 // executePgRestore executes the pg_restore command with proper environment setup
 func (uc *RestorePostgresqlBackupUsecase) executePgRestore(
 	ctx context.Context,
@@ -553,37 +673,3 @@ func (uc *RestorePostgresqlBackupUsecase) copyWithShutdownCheck(
 func containsIgnoreCase(str, substr string) bool {
 	return strings.Contains(strings.ToLower(str), strings.ToLower(substr))
 }
-
-// createTempPgpassFile creates a temporary .pgpass file with the given password
-func (uc *RestorePostgresqlBackupUsecase) createTempPgpassFile(
-	pgConfig *pgtypes.PostgresqlDatabase,
-	password string,
-) (string, error) {
-	if pgConfig == nil || password == "" {
-		return "", nil
-	}
-
-	escapedHost := tools.EscapePgpassField(pgConfig.Host)
-	escapedUsername := tools.EscapePgpassField(pgConfig.Username)
-	escapedPassword := tools.EscapePgpassField(password)
-
-	pgpassContent := fmt.Sprintf("%s:%d:*:%s:%s",
-		escapedHost,
-		pgConfig.Port,
-		escapedUsername,
-		escapedPassword,
-	)
-
-	tempDir, err := os.MkdirTemp("", "pgpass")
-	if err != nil {
-		return "", fmt.Errorf("failed to create temporary directory: %w", err)
-	}
-
-	pgpassFile := filepath.Join(tempDir, ".pgpass")
-	err = os.WriteFile(pgpassFile, []byte(pgpassContent), 0600)
-	if err != nil {
-		return "", fmt.Errorf("failed to write temporary .pgpass file: %w", err)
-	}
-
-	return pgpassFile, nil
-}
@@ -0,0 +1,468 @@
+package usecases_postgresql
+
+import (
+	"archive/tar"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"postgresus-backend/internal/config"
+	"postgresus-backend/internal/features/backups/backups"
+	backups_config "postgresus-backend/internal/features/backups/config"
+	backups_wal "postgresus-backend/internal/features/backups/wal"
+	"postgresus-backend/internal/features/databases"
+	encryption_secrets "postgresus-backend/internal/features/encryption/secrets"
+	"postgresus-backend/internal/features/restores/models"
+	"postgresus-backend/internal/features/storages"
+)
+
+const recoveryTargetTimeFormat = "2006-01-02 15:04:05.999999Z07:00"
+
+// START WAL LOCATION: 0/2000028 (file 000000010000000000000002)
+var backupLabelStartWalRegex = regexp.MustCompile(
+	`(?m)^START WAL LOCATION: \S+ \(file ([0-9A-F]{24})\)$`,
+)
+
+// RestorePostgresqlPointInTimeUsecase prepares PGDATA directory from the
+// physical base backup and archived WAL. Recovery itself is performed
+// by PostgreSQL when the server is started on this directory
+type RestorePostgresqlPointInTimeUsecase struct {
+	logger            *slog.Logger
+	secretKeyService  *encryption_secrets.SecretKeyService
+	walArchiveService *backups_wal.WalArchiveService
+	storageService    *storages.StorageService
+}
+
+func (uc *RestorePostgresqlPointInTimeUsecase) Execute(
+	originalDB *databases.Database,
+	restore models.Restore,
+	backup *backups.Backup,
+	storage *storages.Storage,
+) (string, error) {
+	if originalDB.Type != databases.DatabaseTypePostgres {
+		return "", errors.New("database type not supported")
+	}
+
+	if backup.Method != backups_config.BackupMethodPgBasebackup {
+		return "", errors.New("point-in-time restore requires physical base backup made via pg_basebackup")
+	}
+
+	uc.logger.Info(
+		"Preparing PostgreSQL point-in-time restore",
+		"restoreId",
+		restore.ID,
+		"backupId",
+		backup.ID,
+	)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Minute)
+	defer cancel()
+
+	go func() {
+		ticker := time.NewTicker(1 * time.Second)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if config.IsShouldShutdown() {
+					cancel()
+					return
+				}
+			}
+		}
+	}()
+
+	restoreDir := filepath.Join(config.GetEnv().RestoresFolder, restore.ID.String())
+	dataDir := filepath.Join(restoreDir, "data")
+	walDir := filepath.Join(restoreDir, "wal_archive")
+
+	isSucceeded := false
+	defer func() {
+		if !isSucceeded {
+			_ = os.RemoveAll(restoreDir)
+		}
+	}()
+
+	// PostgreSQL refuses to start on data directory accessible by others
+	if err := os.MkdirAll(dataDir, 0700); err != nil {
+		return "", fmt.Errorf("failed to create data directory: %w", err)
+	}
+
+	if err := os.MkdirAll(walDir, 0700); err != nil {
+		return "", fmt.Errorf("failed to create WAL directory: %w", err)
+	}
+
+	if err := uc.extractBaseBackup(ctx, backup, storage, dataDir); err != nil {
+		return "", err
+	}
+
+	backupLabel, err := os.ReadFile(filepath.Join(dataDir, "backup_label"))
+	if err != nil {
+		return "", fmt.Errorf("failed to read backup_label of the base backup: %w", err)
+	}
+
+	startWalFileName, err := parseBackupLabelStartWalFile(string(backupLabel))
+	if err != nil {
+		return "", err
+	}
+
+	segments, err := uc.walArchiveService.GetSegmentsForRecovery(
+		originalDB.ID,
+		startWalFileName,
+		restore.RecoveryTargetTime,
+		restore.RecoveryTargetLsn,
+	)
+	if err != nil {
+		return "", err
+	}
+
+	if err := uc.downloadWalSegments(ctx, segments, walDir); err != nil {
+		return "", err
+	}
+
+	if err := uc.writeRecoveryConfig(restore, dataDir, walDir); err != nil {
+		return "", err
+	}
+
+	uc.logger.Info(
+		"Point-in-time restore prepared, start PostgreSQL on the data directory to replay WAL",
+		"restoreId",
+		restore.ID,
+		"dataDirectory",
+		dataDir,
+		"walSegments",
+		len(segments),
+	)
+
+	isSucceeded = true
+	return dataDir, nil
+}
+
+func (uc *RestorePostgresqlPointInTimeUsecase) extractBaseBackup(
+	ctx context.Context,
+	backup *backups.Backup,
+	storage *storages.Storage,
+	dataDir string,
+) error {
+	backupReader, err := getBackupReader(uc.secretKeyService, backup, storage, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := backupReader.Close(); err != nil {
+			uc.logger.Error("Failed to close backup reader", "error", err)
+		}
+	}()
+
+	if err := uc.extractTar(ctx, backupReader, dataDir); err != nil {
+		return err
+	}
+
+	// tar reader stops at the end-of-archive marker, the rest
+	// of the file is read to verify the checksum
+	if _, err := io.Copy(io.Discard, backupReader); err != nil {
+		return fmt.Errorf("failed to read base backup: %w", err)
+	}
+
+	if _, err := os.Stat(filepath.Join(dataDir, "PG_VERSION")); err != nil {
+		return errors.New(
+			"backup is not a physical base backup, point-in-time restore requires pg_basebackup backup",
+		)
+	}
+
+	// WAL is not included into base backup when archiving is
+	// enabled, but PostgreSQL still expects the directory
+	return os.MkdirAll(filepath.Join(dataDir, "pg_wal"), 0700)
+}
+
+// extractTar extracts entries of the tar archive into dataDir,
+// entries and symlinks pointing outside of the directory are rejected
+func (uc *RestorePostgresqlPointInTimeUsecase) extractTar(
+	ctx context.Context,
+	reader io.Reader,
+	dataDir string,
+) error {
+	realDataDir, err := filepath.EvalSymlinks(dataDir)
+	if err != nil {
+		return fmt.Errorf("failed to resolve data directory: %w", err)
+	}
+
+	tarReader := tar.NewReader(reader)
+	isAnyEntryExtracted := false
+
+	for {
+		if ctx.Err() != nil {
+			return fmt.Errorf("base backup extraction cancelled: %w", ctx.Err())
+		}
+
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			if !isAnyEntryExtracted {
+				return fmt.Errorf(
+					"backup is not a physical base backup, point-in-time restore requires pg_basebackup backup: %w",
+					err,
+				)
+			}
+
+			return fmt.Errorf("failed to read base backup: %w", err)
+		}
+
+		targetPath, err := getSafeExtractPath(dataDir, header.Name)
+		if err != nil {
+			return err
+		}
+
+		// previous entries may be symlinks, the path is checked by text only
+		if err := checkExtractPathNotEscaped(dataDir, realDataDir, targetPath); err != nil {
+			return fmt.Errorf("base backup contains illegal path: %s: %w", header.Name, err)
+		}
+
+		switch header.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(targetPath, 0700); err != nil {
+				return fmt.Errorf("failed to create directory %s: %w", header.Name, err)
+			}
+		case tar.TypeReg:
+			if err := uc.extractFile(tarReader, targetPath, header); err != nil {
+				return err
+			}
+		case tar.TypeSymlink:
+			if filepath.IsAbs(header.Linkname) ||
+				!isPathWithinDir(dataDir, filepath.Join(filepath.Dir(targetPath), header.Linkname)) {
+				return fmt.Errorf(
+					"base backup contains symlink %s pointing outside of the data directory: %s",
+					header.Name,
+					header.Linkname,
+				)
+			}
+
+			if err := os.Symlink(header.Linkname, targetPath); err != nil {
+				return fmt.Errorf("failed to create symlink %s: %w", header.Name, err)
+			}
+		default:
+			uc.logger.Warn(
+				"Skipping unsupported entry in base backup",
+				"name",
+				header.Name,
+				"type",
+				header.Typeflag,
+			)
+		}
+
+		isAnyEntryExtracted = true
+	}
+
+	return nil
+}
+
+func (uc *RestorePostgresqlPointInTimeUsecase) extractFile(
+	tarReader *tar.Reader,
+	targetPath string,
+	header *tar.Header,
+) error {
+	if err := os.MkdirAll(filepath.Dir(targetPath), 0700); err != nil {
+		return fmt.Errorf("failed to create directory for %s: %w", header.Name, err)
+	}
+
+	file, err := os.OpenFile(
+		targetPath,
+		os.O_CREATE|os.O_WRONLY|os.O_TRUNC,
+		0600,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create file %s: %w", header.Name, err)
+	}
+
+	if _, err := io.Copy(file, tarReader); err != nil {
+		_ = file.Close()
+		return fmt.Errorf("failed to extract file %s: %w", header.Name, err)
+	}
+
+	return file.Close()
+}
+
+func (uc *RestorePostgresqlPointInTimeUsecase) downloadWalSegments(
+	ctx context.Context,
+	segments []*backups_wal.WalSegment,
+	walDir string,
+) error {
+	for _, segment := range segments {
+		if ctx.Err() != nil {
+			return fmt.Errorf("WAL download cancelled: %w", ctx.Err())
+		}
+
+		if err := uc.downloadWalSegment(segment, walDir); err != nil {
+			return fmt.Errorf("failed to download WAL segment %s: %w", segment.FileName, err)
+		}
+	}
+
+	return nil
+}
+
+func (uc *RestorePostgresqlPointInTimeUsecase) downloadWalSegment(
+	segment *backups_wal.WalSegment,
+	walDir string,
+) error {
+	storage, err := uc.storageService.GetStorageByID(segment.StorageID)
+	if err != nil {
+		return err
+	}
+
+	segmentReader, err := getStorageFileReader(
+		uc.secretKeyService,
+		storage,
+		segment.ID,
+		segment.Encryption,
+		segment.EncryptionSalt,
+		segment.EncryptionIV,
+		segment.Checksum,
+	)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := segmentReader.Close(); err != nil {
+			uc.logger.Error("Failed to close WAL segment reader", "error", err)
+		}
+	}()
+
+	file, err := os.OpenFile(
+		filepath.Join(walDir, segment.FileName),
+		os.O_CREATE|os.O_WRONLY|os.O_TRUNC,
+		0600,
+	)
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.Copy(file, segmentReader); err != nil {
+		_ = file.Close()
+		return err
+	}
+
+	return file.Close()
+}
+
+func (uc *RestorePostgresqlPointInTimeUsecase) writeRecoveryConfig(
+	restore models.Restore,
+	dataDir string,
+	walDir string,
+) error {
+	recoveryConfig := []string{
+		"",
+		"# added by Postgresus for point-in-time restore",
+		fmt.Sprintf("restore_command = 'cp \"%s/%%f\" \"%%p\"'", escapeConfigValue(walDir)),
+	}
+
+	if restore.RecoveryTargetTime != nil {
+		recoveryConfig = append(
+			recoveryConfig,
+			fmt.Sprintf(
+				"recovery_target_time = '%s'",
+				restore.RecoveryTargetTime.UTC().Format(recoveryTargetTimeFormat),
+			),
+		)
+	}
+
+	if restore.RecoveryTargetLsn != nil {
+		recoveryConfig = append(
+			recoveryConfig,
+			fmt.Sprintf("recovery_target_lsn = '%s'", escapeConfigValue(*restore.RecoveryTargetLsn)),
+		)
+	}
+
+	recoveryConfig = append(recoveryConfig, "recovery_target_action = 'promote'", "")
+
+	autoConfFile, err := os.OpenFile(
+		filepath.Join(dataDir, "postgresql.auto.conf"),
+		os.O_CREATE|os.O_WRONLY|os.O_APPEND,
+		0600,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to open postgresql.auto.conf: %w", err)
+	}
+
+	if _, err := autoConfFile.WriteString(strings.Join(recoveryConfig, "\n")); err != nil {
+		_ = autoConfFile.Close()
+		return fmt.Errorf("failed to write recovery config: %w", err)
+	}
+
+	if err := autoConfFile.Close(); err != nil {
+		return err
+	}
+
+	return os.WriteFile(filepath.Join(dataDir, "recovery.signal"), []byte{}, 0600)
+}
+
+// parseBackupLabelStartWalFile returns the WAL file containing the start
+// of the base backup, it is the first file needed to replay the backup
+func parseBackupLabelStartWalFile(backupLabel string) (string, error) {
+	match := backupLabelStartWalRegex.FindStringSubmatch(backupLabel)
+	if match == nil {
+		return "", errors.New("backup_label of the base backup has no start WAL location")
+	}
+
+	return match[1], nil
+}
+
+func getSafeExtractPath(baseDir string, name string) (string, error) {
+	targetPath := filepath.Join(baseDir, name)
+
+	if !isPathWithinDir(baseDir, targetPath) {
+		return "", fmt.Errorf("base backup contains illegal path: %s", name)
+	}
+
+	return targetPath, nil
+}
+
+// checkExtractPathNotEscaped resolves symlinks of the nearest existing
+// parent of the path, it must stay in the data directory. Existing entry
+// must not be a symlink either, writing to it would follow the link
+func checkExtractPathNotEscaped(dataDir string, realDataDir string, targetPath string) error {
+	if info, err := os.Lstat(targetPath); err == nil && info.Mode()&os.ModeSymlink != 0 {
+		return errors.New("entry overwrites symlink")
+	}
+
+	existingParent := filepath.Dir(targetPath)
+	for existingParent != dataDir && isPathWithinDir(dataDir, existingParent) {
+		if _, err := os.Lstat(existingParent); err == nil {
+			break
+		}
+
+		existingParent = filepath.Dir(existingParent)
+	}
+
+	realParent, err := filepath.EvalSymlinks(existingParent)
+	if err != nil {
+		return err
+	}
+
+	if !isPathWithinDir(realDataDir, realParent) {
+		return errors.New("path resolves outside of the data directory")
+	}
+
+	return nil
+}
+
+func isPathWithinDir(baseDir string, path string) bool {
+	path = filepath.Clean(path)
+
+	return path == baseDir || strings.HasPrefix(path, baseDir+string(os.PathSeparator))
+}
+
+func escapeConfigValue(value string) string {
+	return strings.ReplaceAll(value, "'", "''")
+}
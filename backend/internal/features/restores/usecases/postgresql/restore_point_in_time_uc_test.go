@@ -0,0 +1,191 @@
+package usecases_postgresql
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"postgresus-backend/internal/features/restores/models"
+	"postgresus-backend/internal/util/logger"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_ParseBackupLabelStartWalFile_StartWalFileReturned(t *testing.T) {
+	backupLabel := "START WAL LOCATION: 0/2000028 (file 000000010000000000000002)\n" +
+		"CHECKPOINT LOCATION: 0/2000060\n" +
+		"BACKUP METHOD: streamed\n"
+
+	startWalFileName, err := parseBackupLabelStartWalFile(backupLabel)
+	assert.NoError(t, err)
+	assert.Equal(t, "000000010000000000000002", startWalFileName)
+
+	_, err = parseBackupLabelStartWalFile("BACKUP METHOD: streamed\n")
+	assert.Error(t, err)
+}
+
+func Test_WriteRecoveryConfig_TargetTimeAndRestoreCommandAppended(t *testing.T) {
+	dataDir := t.TempDir()
+	walDir := "/tmp/restore's wal"
+
+	err := os.WriteFile(
+		filepath.Join(dataDir, "postgresql.auto.conf"),
+		[]byte("max_connections = '50'\n"),
+		0600,
+	)
+	assert.NoError(t, err)
+
+	targetTime := time.Date(2026, 10, 17, 12, 30, 15, 0, time.UTC)
+	restore := models.Restore{RecoveryTargetTime: &targetTime}
+
+	uc := &RestorePostgresqlPointInTimeUsecase{logger: logger.GetLogger()}
+	assert.NoError(t, uc.writeRecoveryConfig(restore, dataDir, walDir))
+
+	autoConf, err := os.ReadFile(filepath.Join(dataDir, "postgresql.auto.conf"))
+	assert.NoError(t, err)
+
+	assert.Equal(
+		t,
+		"max_connections = '50'\n"+
+			"\n"+
+			"# added by Postgresus for point-in-time restore\n"+
+			"restore_command = 'cp \"/tmp/restore''s wal/%f\" \"%p\"'\n"+
+			"recovery_target_time = '2026-10-17 12:30:15Z'\n"+
+			"recovery_target_action = 'promote'\n",
+		string(autoConf),
+	)
+
+	assert.FileExists(t, filepath.Join(dataDir, "recovery.signal"))
+}
+
+func Test_WriteRecoveryConfig_TargetLsn_LsnTargetWritten(t *testing.T) {
+	dataDir := t.TempDir()
+
+	targetLsn := "0/3000060"
+	restore := models.Restore{RecoveryTargetLsn: &targetLsn}
+
+	uc := &RestorePostgresqlPointInTimeUsecase{logger: logger.GetLogger()}
+	assert.NoError(t, uc.writeRecoveryConfig(restore, dataDir, "/wal"))
+
+	autoConf, err := os.ReadFile(filepath.Join(dataDir, "postgresql.auto.conf"))
+	assert.NoError(t, err)
+
+	assert.Contains(t, string(autoConf), "recovery_target_lsn = '0/3000060'\n")
+	assert.NotContains(t, string(autoConf), "recovery_target_time")
+}
+
+func Test_ExtractTar_FilesAndDirectoriesExtracted(t *testing.T) {
+	dataDir := t.TempDir()
+
+	archive := createTestTar(t, []testTarEntry{
+		{name: "base/", typeflag: tar.TypeDir},
+		{name: "base/1/112", typeflag: tar.TypeReg, content: "relation"},
+		{name: "PG_VERSION", typeflag: tar.TypeReg, content: "17\n"},
+	})
+
+	uc := &RestorePostgresqlPointInTimeUsecase{logger: logger.GetLogger()}
+	assert.NoError(t, uc.extractTar(context.Background(), archive, dataDir))
+
+	content, err := os.ReadFile(filepath.Join(dataDir, "base", "1", "112"))
+	assert.NoError(t, err)
+	assert.Equal(t, "relation", string(content))
+
+	info, err := os.Stat(filepath.Join(dataDir, "PG_VERSION"))
+	assert.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+}
+
+func Test_ExtractTar_PathOutsideDataDir_ErrorReturned(t *testing.T) {
+	rootDir := t.TempDir()
+	dataDir := filepath.Join(rootDir, "data")
+	assert.NoError(t, os.MkdirAll(dataDir, 0700))
+
+	archive := createTestTar(t, []testTarEntry{
+		{name: "PG_VERSION", typeflag: tar.TypeReg, content: "17\n"},
+		{name: "../escaped", typeflag: tar.TypeReg, content: "data"},
+	})
+
+	uc := &RestorePostgresqlPointInTimeUsecase{logger: logger.GetLogger()}
+	err := uc.extractTar(context.Background(), archive, dataDir)
+	assert.ErrorContains(t, err, "illegal path")
+
+	assert.NoFileExists(t, filepath.Join(rootDir, "escaped"))
+}
+
+func Test_ExtractTar_SymlinkOutsideDataDir_ErrorReturned(t *testing.T) {
+	rootDir := t.TempDir()
+	dataDir := filepath.Join(rootDir, "data")
+	outsideDir := filepath.Join(rootDir, "outside")
+	assert.NoError(t, os.MkdirAll(dataDir, 0700))
+	assert.NoError(t, os.MkdirAll(outsideDir, 0700))
+
+	uc := &RestorePostgresqlPointInTimeUsecase{logger: logger.GetLogger()}
+
+	for _, linkname := range []string{outsideDir, "../outside"} {
+		archive := createTestTar(t, []testTarEntry{
+			{name: "pg_tblspc", typeflag: tar.TypeSymlink, linkname: linkname},
+			{name: "pg_tblspc/escaped", typeflag: tar.TypeReg, content: "data"},
+		})
+
+		err := uc.extractTar(context.Background(), archive, dataDir)
+		assert.ErrorContains(t, err, "pointing outside of the data directory")
+	}
+
+	// the link is created by something else than the archive
+	assert.NoError(t, os.Symlink(outsideDir, filepath.Join(dataDir, "linked")))
+
+	archive := createTestTar(t, []testTarEntry{
+		{name: "linked/escaped", typeflag: tar.TypeReg, content: "data"},
+	})
+
+	err := uc.extractTar(context.Background(), archive, dataDir)
+	assert.ErrorContains(t, err, "illegal path")
+
+	assert.NoFileExists(t, filepath.Join(outsideDir, "escaped"))
+}
+
+func Test_ExtractTar_NotTarArchive_ErrorReturned(t *testing.T) {
+	uc := &RestorePostgresqlPointInTimeUsecase{logger: logger.GetLogger()}
+
+	err := uc.extractTar(
+		context.Background(),
+		bytes.NewReader(bytes.Repeat([]byte("-- pg_dump output\n"), 64)),
+		t.TempDir(),
+	)
+	assert.ErrorContains(t, err, "not a physical base backup")
+}
+
+type testTarEntry struct {
+	name     string
+	typeflag byte
+	content  string
+	linkname string
+}
+
+func createTestTar(t *testing.T, entries []testTarEntry) *bytes.Buffer {
+	buffer := &bytes.Buffer{}
+	tarWriter := tar.NewWriter(buffer)
+
+	for _, entry := range entries {
+		err := tarWriter.WriteHeader(&tar.Header{
+			Name:     entry.name,
+			Typeflag: entry.typeflag,
+			Linkname: entry.linkname,
+			Mode:     0600,
+			Size:     int64(len(entry.content)),
+		})
+		assert.NoError(t, err)
+
+		if entry.content != "" {
+			_, err := tarWriter.Write([]byte(entry.content))
+			assert.NoError(t, err)
+		}
+	}
+
+	assert.NoError(t, tarWriter.Close())
+	return buffer
+}
@@ -11,7 +11,8 @@ import (
 )
 
 type RestoreBackupUsecase struct {
-	restorePostgresqlBackupUsecase *usecases_postgresql.RestorePostgresqlBackupUsecase
+	restorePostgresqlBackupUsecase      *usecases_postgresql.RestorePostgresqlBackupUsecase
+	restorePostgresqlPointInTimeUsecase *usecases_postgresql.RestorePostgresqlPointInTimeUsecase
 }
 
 func (uc *RestoreBackupUsecase) Execute(
@@ -21,6 +22,7 @@ func (uc *RestoreBackupUsecase) Execute(
 	restoringToDB *databases.Database,
 	backup *backups.Backup,
 	storage *storages.Storage,
+	isRestoreGlobals bool,
 ) error {
 	if originalDB.Type == databases.DatabaseTypePostgres {
 		return uc.restorePostgresqlBackupUsecase.Execute(
@@ -30,8 +32,29 @@ func (uc *RestoreBackupUsecase) Execute(
 			restore,
 			backup,
 			storage,
+			isRestoreGlobals,
 		)
 	}
 
 	return errors.New("database type not supported")
 }
+
+// ExecutePointInTime prepares data directory to recover the database
+// to restore target and returns path to it
+func (uc *RestoreBackupUsecase) ExecutePointInTime(
+	restore models.Restore,
+	originalDB *databases.Database,
+	backup *backups.Backup,
+	storage *storages.Storage,
+) (string, error) {
+	if originalDB.Type == databases.DatabaseTypePostgres {
+		return uc.restorePostgresqlPointInTimeUsecase.Execute(
+			originalDB,
+			restore,
+			backup,
+			storage,
+		)
+	}
+
+	return "", errors.New("database type not supported")
+}
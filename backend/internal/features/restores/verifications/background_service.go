@@ -0,0 +1,104 @@
+package restores_verifications
+
+import (
+	"log/slog"
+	"time"
+
+	"postgresus-backend/internal/config"
+)
+
+type VerificationBackgroundService struct {
+	verificationService    *VerificationService
+	verificationRepository *VerificationRepository
+	logger                 *slog.Logger
+}
+
+func (s *VerificationBackgroundService) Run() {
+	if err := s.failVerificationsInProgress(); err != nil {
+		s.logger.Error("Failed to fail restore verifications in progress", "error", err)
+		panic(err)
+	}
+
+	for {
+		if config.IsShouldShutdown() {
+			return
+		}
+
+		if err := s.runPendingVerifications(); err != nil {
+			s.logger.Error("Failed to run pending restore verifications", "error", err)
+		}
+
+		time.Sleep(1 * time.Minute)
+	}
+}
+
+func (s *VerificationBackgroundService) runPendingVerifications() error {
+	configs, err := s.verificationRepository.FindEnabledConfigs()
+	if err != nil {
+		return err
+	}
+
+	for _, verificationConfig := range configs {
+		if verificationConfig.Interval == nil {
+			continue
+		}
+
+		lastResult, err := s.verificationRepository.FindLastResultByDatabaseID(
+			verificationConfig.DatabaseID,
+		)
+		if err != nil {
+			s.logger.Error("Failed to get last restore verification", "error", err)
+			continue
+		}
+
+		var lastVerificationTime *time.Time
+		if lastResult != nil {
+			if lastResult.Status == VerificationStatusInProgress {
+				continue
+			}
+
+			lastVerificationTime = &lastResult.CreatedAt
+		}
+
+		if !verificationConfig.Interval.ShouldTriggerBackup(
+			time.Now().UTC(),
+			lastVerificationTime,
+		) {
+			continue
+		}
+
+		databaseID := verificationConfig.DatabaseID
+		go func() {
+			if err := s.verificationService.VerifyLastBackup(databaseID); err != nil {
+				s.logger.Error(
+					"Restore verification failed",
+					"databaseId",
+					databaseID,
+					"error",
+					err,
+				)
+			}
+		}()
+	}
+
+	return nil
+}
+
+func (s *VerificationBackgroundService) failVerificationsInProgress() error {
+	results, err := s.verificationRepository.FindResultsByStatus(VerificationStatusInProgress)
+	if err != nil {
+		return err
+	}
+
+	for _, result := range results {
+		failMessage := "Restore verification failed due to application restart"
+		result.Status = VerificationStatusFailed
+		result.FailMessage = &failMessage
+
+		if err := s.verificationRepository.SaveResult(result); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
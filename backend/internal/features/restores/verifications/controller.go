@@ -0,0 +1,120 @@
+package restores_verifications
+
+import (
+	"net/http"
+
+	users_middleware "postgresus-backend/internal/features/users/middleware"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type VerificationController struct {
+	verificationService *VerificationService
+}
+
+func (c *VerificationController) RegisterRoutes(router *gin.RouterGroup) {
+	router.POST("/restore-verifications/config", c.SaveConfig)
+	router.GET("/restore-verifications/config/:databaseId", c.GetConfig)
+	router.GET("/restore-verifications/results/:databaseId", c.GetResults)
+}
+
+// SaveConfig
+// @Summary Save restore verification configuration
+// @Description Create or update scheduled restore verification of the latest backup. Empty password keeps the existing one
+// @Tags restore-verifications
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "JWT token"
+// @Param config body VerificationConfig true "Restore verification configuration"
+// @Success 200 {object} VerificationConfig
+// @Failure 400
+// @Failure 401
+// @Router /restore-verifications/config [post]
+func (c *VerificationController) SaveConfig(ctx *gin.Context) {
+	user, ok := users_middleware.GetUserFromContext(ctx)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	var config VerificationConfig
+	if err := ctx.ShouldBindJSON(&config); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	savedConfig, err := c.verificationService.SaveConfigWithAuth(user, &config)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, savedConfig)
+}
+
+// GetConfig
+// @Summary Get restore verification configuration
+// @Description Get restore verification configuration of the database
+// @Tags restore-verifications
+// @Produce json
+// @Param Authorization header string true "JWT token"
+// @Param databaseId path string true "Database ID"
+// @Success 200 {object} VerificationConfig
+// @Failure 400
+// @Failure 401
+// @Router /restore-verifications/config/{databaseId} [get]
+func (c *VerificationController) GetConfig(ctx *gin.Context) {
+	user, ok := users_middleware.GetUserFromContext(ctx)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	databaseID, err := uuid.Parse(ctx.Param("databaseId"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid database ID"})
+		return
+	}
+
+	config, err := c.verificationService.GetConfigWithAuth(user, databaseID)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, config)
+}
+
+// GetResults
+// @Summary Get restore verification results
+// @Description Get latest restore verification results of the database
+// @Tags restore-verifications
+// @Produce json
+// @Param Authorization header string true "JWT token"
+// @Param databaseId path string true "Database ID"
+// @Success 200 {array} VerificationResult
+// @Failure 400
+// @Failure 401
+// @Router /restore-verifications/results/{databaseId} [get]
+func (c *VerificationController) GetResults(ctx *gin.Context) {
+	user, ok := users_middleware.GetUserFromContext(ctx)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	databaseID, err := uuid.Parse(ctx.Param("databaseId"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid database ID"})
+		return
+	}
+
+	results, err := c.verificationService.GetResultsWithAuth(user, databaseID)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, results)
+}
@@ -0,0 +1,208 @@
+package restores_verifications
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+
+	"postgresus-backend/internal/features/databases"
+	"postgresus-backend/internal/features/databases/databases/postgresql"
+	"postgresus-backend/internal/features/intervals"
+	users_enums "postgresus-backend/internal/features/users/enums"
+	users_testing "postgresus-backend/internal/features/users/testing"
+	workspaces_controllers "postgresus-backend/internal/features/workspaces/controllers"
+	workspaces_testing "postgresus-backend/internal/features/workspaces/testing"
+	test_utils "postgresus-backend/internal/util/testing"
+	"postgresus-backend/internal/util/tools"
+)
+
+func createTestRouter() *gin.Engine {
+	return workspaces_testing.CreateTestRouter(
+		workspaces_controllers.GetWorkspaceController(),
+		workspaces_controllers.GetMembershipController(),
+		databases.GetDatabaseController(),
+		GetVerificationController(),
+	)
+}
+
+func Test_GetVerificationConfig_WhenNotSaved_ReturnsDisabledDefault(t *testing.T) {
+	router := createTestRouter()
+	owner := users_testing.CreateTestUser(users_enums.UserRoleMember)
+	workspace := workspaces_testing.CreateTestWorkspace("Test Workspace", owner, router)
+	database := createTestDatabaseViaAPI("Test Database", workspace.ID, owner.Token, router)
+
+	var config VerificationConfig
+	test_utils.MakeGetRequestAndUnmarshal(
+		t,
+		router,
+		"/api/v1/restore-verifications/config/"+database.ID.String(),
+		"Bearer "+owner.Token,
+		http.StatusOK,
+		&config,
+	)
+
+	assert.Equal(t, database.ID, config.DatabaseID)
+	assert.False(t, config.IsEnabled)
+	assert.NotNil(t, config.Interval)
+	assert.Equal(t, intervals.IntervalDaily, config.Interval.Interval)
+}
+
+func Test_SaveVerificationConfig_PasswordIsHiddenAndKeptWhenEmpty(t *testing.T) {
+	router := createTestRouter()
+	owner := users_testing.CreateTestUser(users_enums.UserRoleMember)
+	workspace := workspaces_testing.CreateTestWorkspace("Test Workspace", owner, router)
+	database := createTestDatabaseViaAPI("Test Database", workspace.ID, owner.Token, router)
+
+	timeOfDay := "03:00"
+	request := VerificationConfig{
+		DatabaseID: database.ID,
+		IsEnabled:  true,
+		Interval: &intervals.Interval{
+			Interval:  intervals.IntervalDaily,
+			TimeOfDay: &timeOfDay,
+		},
+		Host:          "localhost",
+		Port:          5432,
+		Username:      "postgres",
+		Password:      "secret",
+		CheckedTables: []string{"public.users", "orders"},
+	}
+
+	var savedConfig VerificationConfig
+	test_utils.MakePostRequestAndUnmarshal(
+		t,
+		router,
+		"/api/v1/restore-verifications/config",
+		"Bearer "+owner.Token,
+		request,
+		http.StatusOK,
+		&savedConfig,
+	)
+	assert.Empty(t, savedConfig.Password)
+
+	request.Password = ""
+	request.Interval.ID = savedConfig.IntervalID
+	test_utils.MakePostRequest(
+		t,
+		router,
+		"/api/v1/restore-verifications/config",
+		"Bearer "+owner.Token,
+		request,
+		http.StatusOK,
+	)
+
+	storedConfig, err := verificationRepository.FindConfigByDatabaseID(database.ID)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, storedConfig.Password)
+	assert.NotEqual(t, "secret", storedConfig.Password)
+	assert.Equal(t, []string{"public.users", "orders"}, storedConfig.CheckedTables)
+	assert.Equal(t, "03:00", *storedConfig.Interval.TimeOfDay)
+}
+
+func Test_SaveVerificationConfig_WhenEnabledWithoutHost_ReturnsBadRequest(t *testing.T) {
+	router := createTestRouter()
+	owner := users_testing.CreateTestUser(users_enums.UserRoleMember)
+	workspace := workspaces_testing.CreateTestWorkspace("Test Workspace", owner, router)
+	database := createTestDatabaseViaAPI("Test Database", workspace.ID, owner.Token, router)
+
+	timeOfDay := "03:00"
+	request := VerificationConfig{
+		DatabaseID: database.ID,
+		IsEnabled:  true,
+		Interval: &intervals.Interval{
+			Interval:  intervals.IntervalDaily,
+			TimeOfDay: &timeOfDay,
+		},
+		Port:     5432,
+		Username: "postgres",
+	}
+
+	resp := test_utils.MakePostRequest(
+		t,
+		router,
+		"/api/v1/restore-verifications/config",
+		"Bearer "+owner.Token,
+		request,
+		http.StatusBadRequest,
+	)
+	assert.Contains(t, string(resp.Body), "host")
+}
+
+func Test_SaveVerificationConfig_WhenUserIsViewer_ReturnsBadRequest(t *testing.T) {
+	router := createTestRouter()
+	owner := users_testing.CreateTestUser(users_enums.UserRoleMember)
+	workspace := workspaces_testing.CreateTestWorkspace("Test Workspace", owner, router)
+	database := createTestDatabaseViaAPI("Test Database", workspace.ID, owner.Token, router)
+
+	viewer := users_testing.CreateTestUser(users_enums.UserRoleMember)
+	workspaces_testing.AddMemberToWorkspace(
+		workspace,
+		viewer,
+		users_enums.WorkspaceRoleViewer,
+		owner.Token,
+		router,
+	)
+
+	timeOfDay := "03:00"
+	request := VerificationConfig{
+		DatabaseID: database.ID,
+		Interval: &intervals.Interval{
+			Interval:  intervals.IntervalDaily,
+			TimeOfDay: &timeOfDay,
+		},
+	}
+
+	resp := test_utils.MakePostRequest(
+		t,
+		router,
+		"/api/v1/restore-verifications/config",
+		"Bearer "+viewer.Token,
+		request,
+		http.StatusBadRequest,
+	)
+	assert.Contains(t, string(resp.Body), "insufficient permissions")
+}
+
+func createTestDatabaseViaAPI(
+	name string,
+	workspaceID uuid.UUID,
+	token string,
+	router *gin.Engine,
+) *databases.Database {
+	testDbName := "test_db"
+	request := databases.Database{
+		WorkspaceID: &workspaceID,
+		Name:        name,
+		Type:        databases.DatabaseTypePostgres,
+		Postgresql: &postgresql.PostgresqlDatabase{
+			Version:  tools.PostgresqlVersion16,
+			Host:     "localhost",
+			Port:     5432,
+			Username: "postgres",
+			Password: "postgres",
+			Database: &testDbName,
+		},
+	}
+
+	w := workspaces_testing.MakeAPIRequest(
+		router,
+		"POST",
+		"/api/v1/databases/create",
+		"Bearer "+token,
+		request,
+	)
+
+	if w.Code != http.StatusCreated {
+		panic("Failed to create database")
+	}
+
+	var database databases.Database
+	if err := json.Unmarshal(w.Body.Bytes(), &database); err != nil {
+		panic(err)
+	}
+	return &database
+}
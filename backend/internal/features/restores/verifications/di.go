@@ -0,0 +1,50 @@
+package restores_verifications
+
+import (
+	audit_logs "postgresus-backend/internal/features/audit_logs"
+	"postgresus-backend/internal/features/backups/backups"
+	backups_config "postgresus-backend/internal/features/backups/config"
+	"postgresus-backend/internal/features/databases"
+	"postgresus-backend/internal/features/notifiers"
+	"postgresus-backend/internal/features/restores/usecases"
+	workspaces_services "postgresus-backend/internal/features/workspaces/services"
+	"postgresus-backend/internal/util/encryption"
+	"postgresus-backend/internal/util/logger"
+)
+
+var verificationRepository = &VerificationRepository{}
+var verifyBackupUsecase = &VerifyBackupUsecase{
+	usecases.GetRestoreBackupUsecase(),
+	backups_config.GetBackupConfigService(),
+	backups.GetBackupService(),
+	encryption.GetFieldEncryptor(),
+	logger.GetLogger(),
+}
+var verificationService = &VerificationService{
+	verificationRepository,
+	verifyBackupUsecase,
+	backups.GetBackupService(),
+	databases.GetDatabaseService(),
+	notifiers.GetNotifierService(),
+	workspaces_services.GetWorkspaceService(),
+	audit_logs.GetAuditLogService(),
+	encryption.GetFieldEncryptor(),
+	logger.GetLogger(),
+}
+var verificationController = &VerificationController{
+	verificationService,
+}
+
+var verificationBackgroundService = &VerificationBackgroundService{
+	verificationService,
+	verificationRepository,
+	logger.GetLogger(),
+}
+
+func GetVerificationController() *VerificationController {
+	return verificationController
+}
+
+func GetVerificationBackgroundService() *VerificationBackgroundService {
+	return verificationBackgroundService
+}
@@ -0,0 +1,9 @@
+package restores_verifications
+
+type VerificationStatus string
+
+const (
+	VerificationStatusInProgress VerificationStatus = "IN_PROGRESS"
+	VerificationStatusSuccess    VerificationStatus = "SUCCESS"
+	VerificationStatusFailed     VerificationStatus = "FAILED"
+)
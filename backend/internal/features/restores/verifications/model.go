@@ -0,0 +1,143 @@
+package restores_verifications
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"postgresus-backend/internal/features/intervals"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// VerificationConfig describes how the latest backup of the database
+// is periodically restored to prove that it is restorable
+type VerificationConfig struct {
+	DatabaseID uuid.UUID `json:"databaseId" gorm:"column:database_id;type:uuid;primaryKey"`
+
+	IsEnabled bool `json:"isEnabled" gorm:"column:is_enabled;type:boolean;not null"`
+
+	IntervalID uuid.UUID           `json:"intervalId"         gorm:"column:interval_id;type:uuid;not null"`
+	Interval   *intervals.Interval `json:"interval,omitempty" gorm:"foreignKey:IntervalID"`
+
+	// throwaway server: scratch database is created on it
+	// for each verification and dropped after it
+	Host     string `json:"host"     gorm:"column:host;type:text;not null"`
+	Port     int    `json:"port"     gorm:"column:port;type:int;not null"`
+	Username string `json:"username" gorm:"column:username;type:text;not null"`
+	Password string `json:"password" gorm:"column:password;type:text;not null"`
+	IsHttps  bool   `json:"isHttps"  gorm:"column:is_https;type:boolean;not null"`
+
+	// tables in "schema.table" format, which should have rows after restore
+	CheckedTables       []string `json:"checkedTables" gorm:"-"`
+	CheckedTablesString string   `json:"-"             gorm:"column:checked_tables;type:text;not null"`
+}
+
+func (c *VerificationConfig) TableName() string {
+	return "restore_verification_configs"
+}
+
+func (c *VerificationConfig) BeforeSave(tx *gorm.DB) error {
+	c.CheckedTablesString = strings.Join(c.CheckedTables, ",")
+	return nil
+}
+
+func (c *VerificationConfig) AfterFind(tx *gorm.DB) error {
+	if c.CheckedTablesString != "" {
+		c.CheckedTables = strings.Split(c.CheckedTablesString, ",")
+	} else {
+		c.CheckedTables = []string{}
+	}
+
+	return nil
+}
+
+func (c *VerificationConfig) Validate() error {
+	if c.IntervalID == uuid.Nil && c.Interval == nil {
+		return errors.New("verification interval is required")
+	}
+
+	if c.Interval != nil {
+		if err := c.Interval.Validate(); err != nil {
+			return err
+		}
+	}
+
+	for _, table := range c.CheckedTables {
+		if table == "" || strings.Contains(table, ",") {
+			return fmt.Errorf("invalid checked table: %s", table)
+		}
+	}
+
+	if !c.IsEnabled {
+		return nil
+	}
+
+	if c.Host == "" {
+		return errors.New("host of the scratch server is required")
+	}
+
+	if c.Port <= 0 {
+		return errors.New("port of the scratch server is required")
+	}
+
+	if c.Username == "" {
+		return errors.New("username of the scratch server is required")
+	}
+
+	return nil
+}
+
+func (c *VerificationConfig) HideSensitiveData() {
+	c.Password = ""
+}
+
+// VerificationResult is the outcome of restoring the backup
+// into a scratch database and running sanity queries against it
+type VerificationResult struct {
+	ID         uuid.UUID `json:"id"         gorm:"column:id;type:uuid;primaryKey"`
+	DatabaseID uuid.UUID `json:"databaseId" gorm:"column:database_id;type:uuid;not null"`
+	BackupID   uuid.UUID `json:"backupId"   gorm:"column:backup_id;type:uuid;not null"`
+
+	Status      VerificationStatus `json:"status"      gorm:"column:status;type:text;not null"`
+	FailMessage *string            `json:"failMessage" gorm:"column:fail_message"`
+
+	TablesCount          int              `json:"tablesCount"    gorm:"column:tables_count;type:int;not null"`
+	TableRowCounts       map[string]int64 `json:"tableRowCounts" gorm:"-"`
+	TableRowCountsString string           `json:"-"              gorm:"column:table_row_counts;type:text;not null"`
+
+	DurationMs int64     `json:"durationMs" gorm:"column:duration_ms;default:0"`
+	CreatedAt  time.Time `json:"createdAt"  gorm:"column:created_at"`
+}
+
+func (r *VerificationResult) TableName() string {
+	return "restore_verification_results"
+}
+
+func (r *VerificationResult) BeforeSave(tx *gorm.DB) error {
+	if r.TableRowCounts == nil {
+		r.TableRowCountsString = "{}"
+		return nil
+	}
+
+	tableRowCounts, err := json.Marshal(r.TableRowCounts)
+	if err != nil {
+		return err
+	}
+
+	r.TableRowCountsString = string(tableRowCounts)
+	return nil
+}
+
+func (r *VerificationResult) AfterFind(tx *gorm.DB) error {
+	r.TableRowCounts = map[string]int64{}
+
+	if r.TableRowCountsString == "" {
+		return nil
+	}
+
+	return json.Unmarshal([]byte(r.TableRowCountsString), &r.TableRowCounts)
+}
@@ -0,0 +1,132 @@
+package restores_verifications
+
+import (
+	"errors"
+
+	"postgresus-backend/internal/storage"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type VerificationRepository struct{}
+
+func (r *VerificationRepository) SaveConfig(
+	config *VerificationConfig,
+) (*VerificationConfig, error) {
+	db := storage.GetDb()
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if config.Interval != nil {
+			if config.Interval.ID == uuid.Nil {
+				if err := tx.Create(config.Interval).Error; err != nil {
+					return err
+				}
+			} else {
+				if err := tx.Save(config.Interval).Error; err != nil {
+					return err
+				}
+			}
+
+			config.IntervalID = config.Interval.ID
+		}
+
+		return tx.Omit("Interval").Save(config).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return config, nil
+}
+
+func (r *VerificationRepository) FindConfigByDatabaseID(
+	databaseID uuid.UUID,
+) (*VerificationConfig, error) {
+	var config VerificationConfig
+
+	if err := storage.
+		GetDb().
+		Preload("Interval").
+		Where("database_id = ?", databaseID).
+		First(&config).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	return &config, nil
+}
+
+func (r *VerificationRepository) FindEnabledConfigs() ([]*VerificationConfig, error) {
+	var configs []*VerificationConfig
+
+	if err := storage.
+		GetDb().
+		Preload("Interval").
+		Where("is_enabled = ?", true).
+		Find(&configs).Error; err != nil {
+		return nil, err
+	}
+
+	return configs, nil
+}
+
+func (r *VerificationRepository) SaveResult(result *VerificationResult) error {
+	return storage.GetDb().Save(result).Error
+}
+
+func (r *VerificationRepository) FindResultsByDatabaseID(
+	databaseID uuid.UUID,
+	limit int,
+) ([]*VerificationResult, error) {
+	var results []*VerificationResult
+
+	if err := storage.
+		GetDb().
+		Where("database_id = ?", databaseID).
+		Order("created_at DESC").
+		Limit(limit).
+		Find(&results).Error; err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
+
+func (r *VerificationRepository) FindLastResultByDatabaseID(
+	databaseID uuid.UUID,
+) (*VerificationResult, error) {
+	var result VerificationResult
+
+	if err := storage.
+		GetDb().
+		Where("database_id = ?", databaseID).
+		Order("created_at DESC").
+		First(&result).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+func (r *VerificationRepository) FindResultsByStatus(
+	status VerificationStatus,
+) ([]*VerificationResult, error) {
+	var results []*VerificationResult
+
+	if err := storage.
+		GetDb().
+		Where("status = ?", status).
+		Find(&results).Error; err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
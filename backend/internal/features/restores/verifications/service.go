@@ -0,0 +1,254 @@
+package restores_verifications
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	audit_logs "postgresus-backend/internal/features/audit_logs"
+	"postgresus-backend/internal/features/backups/backups"
+	backups_config "postgresus-backend/internal/features/backups/config"
+	"postgresus-backend/internal/features/databases"
+	"postgresus-backend/internal/features/intervals"
+	"postgresus-backend/internal/features/notifiers"
+	users_models "postgresus-backend/internal/features/users/models"
+	workspaces_services "postgresus-backend/internal/features/workspaces/services"
+	"postgresus-backend/internal/util/encryption"
+
+	"github.com/google/uuid"
+)
+
+const maxResultsReturned = 100
+
+type VerificationService struct {
+	verificationRepository *VerificationRepository
+	verifyBackupUsecase    *VerifyBackupUsecase
+	backupService          *backups.BackupService
+	databaseService        *databases.DatabaseService
+	notifierService        *notifiers.NotifierService
+	workspaceService       *workspaces_services.WorkspaceService
+	auditLogService        *audit_logs.AuditLogService
+	fieldEncryptor         encryption.FieldEncryptor
+	logger                 *slog.Logger
+}
+
+func (s *VerificationService) SaveConfigWithAuth(
+	user *users_models.User,
+	config *VerificationConfig,
+) (*VerificationConfig, error) {
+	database, err := s.databaseService.GetDatabaseByID(config.DatabaseID)
+	if err != nil {
+		return nil, err
+	}
+
+	if database.WorkspaceID == nil {
+		return nil, errors.New("cannot modify restore verification config for databases without workspace")
+	}
+
+	canManage, err := s.workspaceService.CanUserManageDBs(*database.WorkspaceID, user)
+	if err != nil {
+		return nil, err
+	}
+	if !canManage {
+		return nil, errors.New("insufficient permissions to modify restore verification config")
+	}
+
+	existingConfig, err := s.verificationRepository.FindConfigByDatabaseID(database.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	// empty password means the password is not changed
+	if config.Password == "" && existingConfig != nil {
+		config.Password = existingConfig.Password
+	}
+
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+
+	if config.Password != "" {
+		encryptedPassword, err := s.fieldEncryptor.Encrypt(database.ID, config.Password)
+		if err != nil {
+			return nil, err
+		}
+		config.Password = encryptedPassword
+	}
+
+	savedConfig, err := s.verificationRepository.SaveConfig(config)
+	if err != nil {
+		return nil, err
+	}
+
+	s.auditLogService.WriteAuditLog(
+		fmt.Sprintf("Restore verification config updated for database '%s'", database.Name),
+		&user.ID,
+		database.WorkspaceID,
+	)
+
+	savedConfig.HideSensitiveData()
+	return savedConfig, nil
+}
+
+func (s *VerificationService) GetConfigWithAuth(
+	user *users_models.User,
+	databaseID uuid.UUID,
+) (*VerificationConfig, error) {
+	database, err := s.getAccessibleDatabase(user, databaseID)
+	if err != nil {
+		return nil, err
+	}
+
+	config, err := s.verificationRepository.FindConfigByDatabaseID(database.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if config == nil {
+		config, err = s.initializeDefaultConfig(database.ID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	config.HideSensitiveData()
+	return config, nil
+}
+
+func (s *VerificationService) GetResultsWithAuth(
+	user *users_models.User,
+	databaseID uuid.UUID,
+) ([]*VerificationResult, error) {
+	database, err := s.getAccessibleDatabase(user, databaseID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.verificationRepository.FindResultsByDatabaseID(database.ID, maxResultsReturned)
+}
+
+// VerifyLastBackup restores the latest completed backup of the database
+// into scratch database and records the result
+func (s *VerificationService) VerifyLastBackup(databaseID uuid.UUID) error {
+	config, err := s.verificationRepository.FindConfigByDatabaseID(databaseID)
+	if err != nil {
+		return err
+	}
+
+	if config == nil || !config.IsEnabled {
+		return errors.New("restore verification is not enabled for the database")
+	}
+
+	database, err := s.databaseService.GetDatabaseByID(databaseID)
+	if err != nil {
+		return err
+	}
+
+	backup, err := s.backupService.GetLastCompletedBackup(databaseID)
+	if err != nil {
+		return err
+	}
+
+	if backup == nil {
+		s.logger.Info("No completed backups to verify", "databaseId", databaseID)
+		return nil
+	}
+
+	if backup.Method == backups_config.BackupMethodPgBasebackup {
+		s.logger.Info("Physical base backups are not verified", "databaseId", databaseID)
+		return nil
+	}
+
+	result := &VerificationResult{
+		ID:             uuid.New(),
+		DatabaseID:     databaseID,
+		BackupID:       backup.ID,
+		Status:         VerificationStatusInProgress,
+		TableRowCounts: map[string]int64{},
+		CreatedAt:      time.Now().UTC(),
+	}
+
+	if err := s.verificationRepository.SaveResult(result); err != nil {
+		return err
+	}
+
+	start := time.Now().UTC()
+
+	err = s.verifyBackupUsecase.Execute(config, database, backup, result)
+	result.DurationMs = time.Since(start).Milliseconds()
+
+	if err != nil {
+		errMsg := err.Error()
+		result.Status = VerificationStatusFailed
+		result.FailMessage = &errMsg
+
+		if saveErr := s.verificationRepository.SaveResult(result); saveErr != nil {
+			return saveErr
+		}
+
+		s.sendFailedNotifications(database, backup, errMsg)
+		return err
+	}
+
+	result.Status = VerificationStatusSuccess
+	return s.verificationRepository.SaveResult(result)
+}
+
+func (s *VerificationService) getAccessibleDatabase(
+	user *users_models.User,
+	databaseID uuid.UUID,
+) (*databases.Database, error) {
+	database, err := s.databaseService.GetDatabaseByID(databaseID)
+	if err != nil {
+		return nil, err
+	}
+
+	if database.WorkspaceID == nil {
+		return nil, errors.New("cannot access restore verifications for databases without workspace")
+	}
+
+	canAccess, _, err := s.workspaceService.CanUserAccessWorkspace(*database.WorkspaceID, user)
+	if err != nil {
+		return nil, err
+	}
+	if !canAccess {
+		return nil, errors.New("insufficient permissions to view restore verifications")
+	}
+
+	return database, nil
+}
+
+func (s *VerificationService) initializeDefaultConfig(
+	databaseID uuid.UUID,
+) (*VerificationConfig, error) {
+	timeOfDay := "05:00"
+
+	return s.verificationRepository.SaveConfig(&VerificationConfig{
+		DatabaseID: databaseID,
+		IsEnabled:  false,
+		Interval: &intervals.Interval{
+			Interval:  intervals.IntervalDaily,
+			TimeOfDay: &timeOfDay,
+		},
+		Port:          5432,
+		CheckedTables: []string{},
+	})
+}
+
+func (s *VerificationService) sendFailedNotifications(
+	database *databases.Database,
+	backup *backups.Backup,
+	errMsg string,
+) {
+	title := fmt.Sprintf("❌ [%s] Restore verification failed", database.Name)
+	message := fmt.Sprintf(
+		"Backup from %s could not be verified by restore: %s",
+		backup.CreatedAt.Format(time.RFC3339),
+		errMsg,
+	)
+
+	for _, notifier := range database.Notifiers {
+		s.notifierService.SendNotification(&notifier, title, message)
+	}
+}
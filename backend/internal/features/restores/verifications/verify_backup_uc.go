@@ -0,0 +1,285 @@
+package restores_verifications
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"postgresus-backend/internal/features/backups/backups"
+	backups_config "postgresus-backend/internal/features/backups/config"
+	"postgresus-backend/internal/features/databases"
+	"postgresus-backend/internal/features/databases/databases/postgresql"
+	"postgresus-backend/internal/features/restores/enums"
+	"postgresus-backend/internal/features/restores/models"
+	"postgresus-backend/internal/features/restores/usecases"
+	"postgresus-backend/internal/util/encryption"
+	"postgresus-backend/internal/util/tools"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+const (
+	scratchDatabasePrefix  = "postgresus_verification_"
+	scratchDatabaseTimeout = 30 * time.Second
+)
+
+type VerifyBackupUsecase struct {
+	restoreBackupUsecase *usecases.RestoreBackupUsecase
+	backupConfigService  *backups_config.BackupConfigService
+	backupService        *backups.BackupService
+	fieldEncryptor       encryption.FieldEncryptor
+	logger               *slog.Logger
+}
+
+// Execute restores the backup into a scratch database on the verification
+// server, runs sanity queries against it and drops the scratch database.
+// Result is filled with found tables and row counts
+func (uc *VerifyBackupUsecase) Execute(
+	config *VerificationConfig,
+	database *databases.Database,
+	backup *backups.Backup,
+	result *VerificationResult,
+) error {
+	if database.Type != databases.DatabaseTypePostgres || database.Postgresql == nil {
+		return errors.New("database type not supported")
+	}
+
+	password, err := uc.fieldEncryptor.Decrypt(config.DatabaseID, config.Password)
+	if err != nil {
+		return fmt.Errorf("failed to decrypt password of verification server: %w", err)
+	}
+
+	scratchDatabaseName := scratchDatabasePrefix + strings.ReplaceAll(result.ID.String(), "-", "")
+
+	if err := uc.createScratchDatabase(config, password, scratchDatabaseName); err != nil {
+		return err
+	}
+	defer func() {
+		if err := uc.dropScratchDatabase(config, password, scratchDatabaseName); err != nil {
+			uc.logger.Error(
+				"Failed to drop scratch database",
+				"database",
+				scratchDatabaseName,
+				"error",
+				err,
+			)
+		}
+	}()
+
+	restoringToDB := &databases.Database{
+		Type: databases.DatabaseTypePostgres,
+		Postgresql: &postgresql.PostgresqlDatabase{
+			Host:     config.Host,
+			Port:     config.Port,
+			Username: config.Username,
+			Password: password,
+			Database: &scratchDatabaseName,
+			IsHttps:  config.IsHttps,
+		},
+	}
+
+	if err := restoringToDB.PopulateVersionIfEmpty(uc.logger, uc.fieldEncryptor); err != nil {
+		return fmt.Errorf("failed to detect version of verification server: %w", err)
+	}
+
+	if tools.IsBackupDbVersionHigherThanRestoreDbVersion(
+		database.Postgresql.Version,
+		restoringToDB.Postgresql.Version,
+	) {
+		return fmt.Errorf(
+			"verification server version %s is lower than backup database version %s",
+			restoringToDB.Postgresql.Version,
+			database.Postgresql.Version,
+		)
+	}
+
+	backupConfig, err := uc.backupConfigService.GetBackupConfigByDbId(database.ID)
+	if err != nil {
+		return err
+	}
+
+	storage, err := uc.backupService.GetBackupStorage(backup, nil)
+	if err != nil {
+		return err
+	}
+
+	restore := models.Restore{
+		ID:         result.ID,
+		Status:     enums.RestoreStatusInProgress,
+		BackupID:   backup.ID,
+		Backup:     backup,
+		ArtifactID: getVerifiedArtifactID(database, backup),
+		CreatedAt:  time.Now().UTC(),
+	}
+
+	if err := uc.restoreBackupUsecase.Execute(
+		backupConfig,
+		restore,
+		database,
+		restoringToDB,
+		backup,
+		storage,
+		false,
+	); err != nil {
+		return fmt.Errorf("failed to restore backup: %w", err)
+	}
+
+	return uc.runSanityChecks(config, password, scratchDatabaseName, result)
+}
+
+func (uc *VerifyBackupUsecase) createScratchDatabase(
+	config *VerificationConfig,
+	password string,
+	scratchDatabaseName string,
+) error {
+	ctx, cancel := context.WithTimeout(context.Background(), scratchDatabaseTimeout)
+	defer cancel()
+
+	conn, err := pgx.Connect(ctx, buildConnectionString(config, "postgres", password))
+	if err != nil {
+		return fmt.Errorf("failed to connect to verification server: %w", err)
+	}
+	defer func() { _ = conn.Close(ctx) }()
+
+	_, err = conn.Exec(
+		ctx,
+		"CREATE DATABASE "+pgx.Identifier{scratchDatabaseName}.Sanitize(),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create scratch database: %w", err)
+	}
+
+	return nil
+}
+
+func (uc *VerifyBackupUsecase) dropScratchDatabase(
+	config *VerificationConfig,
+	password string,
+	scratchDatabaseName string,
+) error {
+	ctx, cancel := context.WithTimeout(context.Background(), scratchDatabaseTimeout)
+	defer cancel()
+
+	conn, err := pgx.Connect(ctx, buildConnectionString(config, "postgres", password))
+	if err != nil {
+		return fmt.Errorf("failed to connect to verification server: %w", err)
+	}
+	defer func() { _ = conn.Close(ctx) }()
+
+	// connections left by pg_restore or psql would block the drop
+	_, err = conn.Exec(
+		ctx,
+		"SELECT pg_terminate_backend(pid) FROM pg_stat_activity WHERE datname = $1 AND pid <> pg_backend_pid()",
+		scratchDatabaseName,
+	)
+	if err != nil {
+		return err
+	}
+
+	_, err = conn.Exec(
+		ctx,
+		"DROP DATABASE IF EXISTS "+pgx.Identifier{scratchDatabaseName}.Sanitize(),
+	)
+	return err
+}
+
+func (uc *VerifyBackupUsecase) runSanityChecks(
+	config *VerificationConfig,
+	password string,
+	scratchDatabaseName string,
+	result *VerificationResult,
+) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+	defer cancel()
+
+	conn, err := pgx.Connect(ctx, buildConnectionString(config, scratchDatabaseName, password))
+	if err != nil {
+		return fmt.Errorf("failed to connect to scratch database: %w", err)
+	}
+	defer func() { _ = conn.Close(ctx) }()
+
+	var tablesCount int
+	err = conn.QueryRow(
+		ctx,
+		`SELECT count(*) FROM information_schema.tables
+		 WHERE table_type = 'BASE TABLE'
+		   AND table_schema NOT IN ('pg_catalog', 'information_schema')`,
+	).Scan(&tablesCount)
+	if err != nil {
+		return fmt.Errorf("failed to count tables: %w", err)
+	}
+
+	result.TablesCount = tablesCount
+	if tablesCount == 0 {
+		return errors.New("restored database does not contain any tables")
+	}
+
+	result.TableRowCounts = map[string]int64{}
+
+	for _, table := range config.CheckedTables {
+		schemaName, tableName := splitTableName(table)
+
+		var rowsCount int64
+		err = conn.QueryRow(
+			ctx,
+			"SELECT count(*) FROM "+pgx.Identifier{schemaName, tableName}.Sanitize(),
+		).Scan(&rowsCount)
+		if err != nil {
+			return fmt.Errorf("failed to count rows of table %s: %w", table, err)
+		}
+
+		result.TableRowCounts[table] = rowsCount
+		if rowsCount == 0 {
+			return fmt.Errorf("table %s is empty after restore", table)
+		}
+	}
+
+	return nil
+}
+
+// getVerifiedArtifactID picks the database itself from whole-server backup,
+// or the first database if the configured one was not found on the server
+func getVerifiedArtifactID(database *databases.Database, backup *backups.Backup) *uuid.UUID {
+	if !backup.IsWholeServer || len(backup.Artifacts) == 0 {
+		return nil
+	}
+
+	for _, artifact := range backup.Artifacts {
+		if database.Postgresql.Database != nil &&
+			artifact.DatabaseName == *database.Postgresql.Database {
+			return &artifact.ID
+		}
+	}
+
+	return &backup.Artifacts[0].ID
+}
+
+func splitTableName(table string) (string, string) {
+	schemaName, tableName, found := strings.Cut(table, ".")
+	if !found {
+		return "public", table
+	}
+
+	return schemaName, tableName
+}
+
+func buildConnectionString(config *VerificationConfig, dbName string, password string) string {
+	sslMode := "disable"
+	if config.IsHttps {
+		sslMode = "require"
+	}
+
+	return fmt.Sprintf(
+		"host=%s port=%d user=%s password=%s dbname=%s sslmode=%s default_query_exec_mode=simple_protocol",
+		config.Host,
+		config.Port,
+		config.Username,
+		password,
+		dbName,
+		sslMode,
+	)
+}
@@ -9,6 +9,10 @@ import (
 	"github.com/google/uuid"
 )
 
+type StorageRemoveListener interface {
+	OnBeforeStorageRemove(storage *Storage) error
+}
+
 type StorageFileSaver interface {
 	SaveFile(
 		ctx context.Context,
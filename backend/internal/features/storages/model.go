@@ -5,6 +5,7 @@ import (
 	"errors"
 	"io"
 	"log/slog"
+	"postgresus-backend/internal/config"
 	azure_blob_storage "postgresus-backend/internal/features/storages/models/azure_blob"
 	ftp_storage "postgresus-backend/internal/features/storages/models/ftp"
 	google_drive_storage "postgresus-backend/internal/features/storages/models/google_drive"
@@ -13,10 +14,16 @@ import (
 	nas_storage "postgresus-backend/internal/features/storages/models/nas"
 	s3_storage "postgresus-backend/internal/features/storages/models/s3"
 	"postgresus-backend/internal/util/encryption"
+	"postgresus-backend/internal/util/throttle"
 
 	"github.com/google/uuid"
+	"golang.org/x/time/rate"
 )
 
+const globalUploadLimiterKey = "global"
+
+var uploadLimiters = throttle.NewRegistry()
+
 type Storage struct {
 	ID            uuid.UUID   `json:"id"            gorm:"column:id;primaryKey;type:uuid;default:gen_random_uuid()"`
 	WorkspaceID   uuid.UUID   `json:"workspaceId"   gorm:"column:workspace_id;not null;type:uuid;index"`
@@ -24,6 +31,9 @@ type Storage struct {
 	Name          string      `json:"name"          gorm:"column:name;not null;type:text"`
 	LastSaveError *string     `json:"lastSaveError" gorm:"column:last_save_error;type:text"`
 
+	// shared by all uploads to the storage, 0 means unlimited
+	MaxUploadBytesPerSecond int64 `json:"maxUploadBytesPerSecond" gorm:"column:max_upload_bytes_per_second;type:bigint;not null;default:0"`
+
 	// specific storage
 	LocalStorage       *local_storage.LocalStorage              `json:"localStorage"       gorm:"foreignKey:StorageID"`
 	S3Storage          *s3_storage.S3Storage                    `json:"s3Storage"          gorm:"foreignKey:StorageID"`
@@ -41,7 +51,11 @@ func (s *Storage) SaveFile(
 	fileID uuid.UUID,
 	file io.Reader,
 ) error {
-	err := s.getSpecificStorage().SaveFile(ctx, encryptor, logger, fileID, file)
+	// reading slower makes the writer of the backup wait, so the
+	// whole copy path is throttled
+	throttledFile := throttle.NewReader(ctx, file, s.getUploadLimiters()...)
+
+	err := s.getSpecificStorage().SaveFile(ctx, encryptor, logger, fileID, throttledFile)
 	if err != nil {
 		lastSaveError := err.Error()
 		s.LastSaveError = &lastSaveError
@@ -73,6 +87,10 @@ func (s *Storage) Validate(encryptor encryption.FieldEncryptor) error {
 		return errors.New("storage name is required")
 	}
 
+	if s.MaxUploadBytesPerSecond < 0 {
+		return errors.New("upload speed limit cannot be negative")
+	}
+
 	return s.getSpecificStorage().Validate(encryptor)
 }
 
@@ -91,6 +109,7 @@ func (s *Storage) EncryptSensitiveData(encryptor encryption.FieldEncryptor) erro
 func (s *Storage) Update(incoming *Storage) {
 	s.Name = incoming.Name
 	s.Type = incoming.Type
+	s.MaxUploadBytesPerSecond = incoming.MaxUploadBytesPerSecond
 
 	switch s.Type {
 	case StorageTypeLocal:
@@ -124,6 +143,14 @@ func (s *Storage) Update(incoming *Storage) {
 	}
 }
 
+// getUploadLimiters returns limiter of the storage and the global one
+func (s *Storage) getUploadLimiters() []*rate.Limiter {
+	return []*rate.Limiter{
+		uploadLimiters.GetLimiter(s.ID.String(), s.MaxUploadBytesPerSecond),
+		uploadLimiters.GetLimiter(globalUploadLimiterKey, config.GetEnv().MaxUploadBytesPerSecond),
+	}
+}
+
 func (s *Storage) getSpecificStorage() StorageFileSaver {
 	switch s.Type {
 	case StorageTypeLocal:
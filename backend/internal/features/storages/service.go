@@ -17,6 +17,12 @@ type StorageService struct {
 	workspaceService  *workspaces_services.WorkspaceService
 	auditLogService   *audit_logs.AuditLogService
 	fieldEncryptor    encryption.FieldEncryptor
+
+	storageRemoveListeners []StorageRemoveListener
+}
+
+func (s *StorageService) AddStorageRemoveListener(listener StorageRemoveListener) {
+	s.storageRemoveListeners = append(s.storageRemoveListeners, listener)
 }
 
 func (s *StorageService) SaveStorage(
@@ -115,6 +121,12 @@ func (s *StorageService) DeleteStorage(
 		return errors.New("insufficient permissions to manage storage in this workspace")
 	}
 
+	for _, listener := range s.storageRemoveListeners {
+		if err := listener.OnBeforeStorageRemove(storage); err != nil {
+			return err
+		}
+	}
+
 	err = s.storageRepository.Delete(storage)
 	if err != nil {
 		return err
@@ -84,7 +84,57 @@ func Test_BackupAndRestorePostgresql_RestoreIsSuccesful(t *testing.T) {
 	for _, tc := range cases {
 		t.Run(tc.name, func(t *testing.T) {
 			t.Parallel()
-			testBackupRestoreForVersion(t, tc.version, tc.port)
+			testBackupRestoreForVersion(t, tc.version, tc.port, backups_config.BackupFormatCustom)
+		})
+	}
+}
+
+func Test_BackupAndRestorePostgresqlDirectoryFormat_RestoreIsSuccessful(t *testing.T) {
+	env := config.GetEnv()
+	cases := []struct {
+		name    string
+		version string
+		port    string
+	}{
+		{"PostgreSQL 12", "12", env.TestPostgres12Port},
+		{"PostgreSQL 16", "16", env.TestPostgres16Port},
+		{"PostgreSQL 18", "18", env.TestPostgres18Port},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			testBackupRestoreForVersion(
+				t,
+				tc.version,
+				tc.port,
+				backups_config.BackupFormatDirectory,
+			)
+		})
+	}
+}
+
+func Test_BackupAndRestorePostgresqlPlainFormat_RestoreIsSuccessful(t *testing.T) {
+	env := config.GetEnv()
+	cases := []struct {
+		name    string
+		version string
+		port    string
+	}{
+		{"PostgreSQL 12", "12", env.TestPostgres12Port},
+		{"PostgreSQL 16", "16", env.TestPostgres16Port},
+		{"PostgreSQL 18", "18", env.TestPostgres18Port},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			testBackupRestoreForVersion(
+				t,
+				tc.version,
+				tc.port,
+				backups_config.BackupFormatPlain,
+			)
 		})
 	}
 }
@@ -489,7 +539,143 @@ func Test_BackupPostgresql_SchemaSelection_OnlySpecifiedSchemas(t *testing.T) {
 	workspaces_testing.RemoveTestWorkspace(workspace, router)
 }
 
-func testBackupRestoreForVersion(t *testing.T, pgVersion string, port string) {
+func Test_BackupPostgresql_ExcludePatterns_ExcludedObjectsNotRestored(t *testing.T) {
+	env := config.GetEnv()
+
+	container, err := connectToPostgresContainer("16", env.TestPostgres16Port)
+	assert.NoError(t, err)
+	defer container.DB.Close()
+
+	_, err = container.DB.Exec(`
+		DROP SCHEMA IF EXISTS excluded_schema CASCADE;
+		CREATE SCHEMA excluded_schema;
+
+		CREATE TABLE public.kept_table (id SERIAL PRIMARY KEY, data TEXT);
+		CREATE TABLE public.excluded_table (id SERIAL PRIMARY KEY, data TEXT);
+		CREATE TABLE public.excluded_data_table (id SERIAL PRIMARY KEY, data TEXT);
+		CREATE TABLE excluded_schema.schema_table (id SERIAL PRIMARY KEY, data TEXT);
+
+		INSERT INTO public.kept_table (data) VALUES ('kept_data');
+		INSERT INTO public.excluded_table (data) VALUES ('excluded_data');
+		INSERT INTO public.excluded_data_table (data) VALUES ('excluded_data');
+		INSERT INTO excluded_schema.schema_table (data) VALUES ('schema_data');
+	`)
+	assert.NoError(t, err)
+
+	defer func() {
+		_, _ = container.DB.Exec(`
+			DROP TABLE IF EXISTS public.kept_table;
+			DROP TABLE IF EXISTS public.excluded_table;
+			DROP TABLE IF EXISTS public.excluded_data_table;
+			DROP SCHEMA IF EXISTS excluded_schema CASCADE;
+		`)
+	}()
+
+	router := createTestRouter()
+	user := users_testing.CreateTestUser(users_enums.UserRoleMember)
+	workspace := workspaces_testing.CreateTestWorkspace("Exclude Test Workspace", user, router)
+
+	storage := storages.CreateTestStorage(workspace.ID)
+
+	database := createDatabaseWithExcludesViaAPI(
+		t, router, "Exclude Patterns Database", workspace.ID,
+		container.Host, container.Port,
+		container.Username, container.Password, container.Database,
+		[]string{"excluded_schema"},
+		[]string{"public.excluded_table"},
+		[]string{"public.excluded_data_table"},
+		user.Token,
+	)
+
+	enableBackupsViaAPI(
+		t, router, database.ID, storage.ID,
+		backups_config.BackupEncryptionNone, user.Token,
+	)
+
+	createBackupViaAPI(t, router, database.ID, user.Token)
+
+	backup := waitForBackupCompletion(t, router, database.ID, user.Token, 5*time.Minute)
+	assert.Equal(t, backups.BackupStatusCompleted, backup.Status)
+
+	newDBName := "restored_exclude_patterns"
+	_, err = container.DB.Exec(fmt.Sprintf("DROP DATABASE IF EXISTS %s;", newDBName))
+	assert.NoError(t, err)
+
+	_, err = container.DB.Exec(fmt.Sprintf("CREATE DATABASE %s;", newDBName))
+	assert.NoError(t, err)
+
+	newDSN := fmt.Sprintf("host=%s port=%d user=%s password=%s dbname=%s sslmode=disable",
+		container.Host, container.Port, container.Username, container.Password, newDBName)
+	newDB, err := sqlx.Connect("postgres", newDSN)
+	assert.NoError(t, err)
+	defer newDB.Close()
+
+	createRestoreViaAPI(
+		t, router, backup.ID,
+		container.Host, container.Port,
+		container.Username, container.Password, newDBName,
+		user.Token,
+	)
+
+	restore := waitForRestoreCompletion(t, router, backup.ID, user.Token, 5*time.Minute)
+	assert.Equal(t, restores_enums.RestoreStatusCompleted, restore.Status)
+
+	var keptTableRowsCount int
+	err = newDB.Get(&keptTableRowsCount, `SELECT COUNT(*) FROM public.kept_table`)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, keptTableRowsCount, "public.kept_table should be restored with data")
+
+	var excludedTableExists bool
+	err = newDB.Get(&excludedTableExists, `
+		SELECT EXISTS (
+			SELECT FROM information_schema.tables 
+			WHERE table_schema = 'public' AND table_name = 'excluded_table'
+		)
+	`)
+	assert.NoError(t, err)
+	assert.False(t, excludedTableExists, "public.excluded_table should NOT exist (was excluded)")
+
+	var excludedDataTableRowsCount int
+	err = newDB.Get(&excludedDataTableRowsCount, `SELECT COUNT(*) FROM public.excluded_data_table`)
+	assert.NoError(t, err)
+	assert.Equal(
+		t,
+		0,
+		excludedDataTableRowsCount,
+		"public.excluded_data_table should exist without data",
+	)
+
+	var excludedSchemaExists bool
+	err = newDB.Get(&excludedSchemaExists, `
+		SELECT EXISTS (
+			SELECT FROM information_schema.schemata WHERE schema_name = 'excluded_schema'
+		)
+	`)
+	assert.NoError(t, err)
+	assert.False(t, excludedSchemaExists, "excluded_schema should NOT exist (was excluded)")
+
+	err = os.Remove(filepath.Join(config.GetEnv().DataFolder, backup.ID.String()))
+	if err != nil {
+		t.Logf("Warning: Failed to delete backup file: %v", err)
+	}
+
+	test_utils.MakeDeleteRequest(
+		t,
+		router,
+		"/api/v1/databases/"+database.ID.String(),
+		"Bearer "+user.Token,
+		http.StatusNoContent,
+	)
+	storages.RemoveTestStorage(storage.ID)
+	workspaces_testing.RemoveTestWorkspace(workspace, router)
+}
+
+func testBackupRestoreForVersion(
+	t *testing.T,
+	pgVersion string,
+	port string,
+	format backups_config.BackupFormat,
+) {
 	container, err := connectToPostgresContainer(pgVersion, port)
 	assert.NoError(t, err)
 	defer func() {
@@ -514,15 +700,41 @@ func testBackupRestoreForVersion(t *testing.T, pgVersion string, port string) {
 		user.Token,
 	)
 
-	enableBackupsViaAPI(
+	enableBackupsWithFormatViaAPI(
 		t, router, database.ID, storage.ID,
-		backups_config.BackupEncryptionNone, user.Token,
+		backups_config.BackupEncryptionNone, format, user.Token,
 	)
 
 	createBackupViaAPI(t, router, database.ID, user.Token)
 
 	backup := waitForBackupCompletion(t, router, database.ID, user.Token, 5*time.Minute)
 	assert.Equal(t, backups.BackupStatusCompleted, backup.Status)
+	assert.Equal(t, format, backup.Format)
+	assert.NotNil(t, backup.Checksum)
+
+	if format != backups_config.BackupFormatPlain {
+		var contents backups.BackupContents
+		test_utils.MakeGetRequestAndUnmarshal(
+			t,
+			router,
+			fmt.Sprintf("/api/v1/backups/%s/contents", backup.ID.String()),
+			"Bearer "+user.Token,
+			http.StatusOK,
+			&contents,
+		)
+
+		var testDataTable *backups.BackupContentsTable
+		for _, table := range contents.Tables {
+			if table.Schema == "public" && table.Name == "test_data" {
+				testDataTable = table
+			}
+		}
+		assert.NotNil(t, testDataTable, "Table 'test_data' should be listed in backup contents")
+		if testDataTable != nil {
+			assert.True(t, testDataTable.HasData)
+		}
+		assert.Contains(t, contents.Schemas, "public")
+	}
 
 	newDBName := "restoreddb"
 	_, err = container.DB.Exec(fmt.Sprintf("DROP DATABASE IF EXISTS %s;", newDBName))
@@ -825,6 +1037,41 @@ func enableBackupsViaAPI(
 	)
 }
 
+func enableBackupsWithFormatViaAPI(
+	t *testing.T,
+	router *gin.Engine,
+	databaseID uuid.UUID,
+	storageID uuid.UUID,
+	encryption backups_config.BackupEncryption,
+	format backups_config.BackupFormat,
+	token string,
+) {
+	var backupConfig backups_config.BackupConfig
+	test_utils.MakeGetRequestAndUnmarshal(
+		t,
+		router,
+		fmt.Sprintf("/api/v1/backup-configs/database/%s", databaseID.String()),
+		"Bearer "+token,
+		http.StatusOK,
+		&backupConfig,
+	)
+
+	storage := &storages.Storage{ID: storageID}
+	backupConfig.IsBackupsEnabled = true
+	backupConfig.Storage = storage
+	backupConfig.Encryption = encryption
+	backupConfig.Format = format
+
+	test_utils.MakePostRequest(
+		t,
+		router,
+		"/api/v1/backup-configs/save",
+		"Bearer "+token,
+		backupConfig,
+		http.StatusOK,
+	)
+}
+
 func createBackupViaAPI(
 	t *testing.T,
 	router *gin.Engine,
@@ -924,6 +1171,61 @@ func createDatabaseWithSchemasViaAPI(
 	return &createdDatabase
 }
 
+func createDatabaseWithExcludesViaAPI(
+	t *testing.T,
+	router *gin.Engine,
+	name string,
+	workspaceID uuid.UUID,
+	host string,
+	port int,
+	username string,
+	password string,
+	database string,
+	excludeSchemas []string,
+	excludeTables []string,
+	excludeTableData []string,
+	token string,
+) *databases.Database {
+	request := databases.Database{
+		Name:        name,
+		WorkspaceID: &workspaceID,
+		Type:        databases.DatabaseTypePostgres,
+		Postgresql: &pgtypes.PostgresqlDatabase{
+			Host:             host,
+			Port:             port,
+			Username:         username,
+			Password:         password,
+			Database:         &database,
+			ExcludeSchemas:   excludeSchemas,
+			ExcludeTables:    excludeTables,
+			ExcludeTableData: excludeTableData,
+		},
+	}
+
+	w := workspaces_testing.MakeAPIRequest(
+		router,
+		"POST",
+		"/api/v1/databases/create",
+		"Bearer "+token,
+		request,
+	)
+
+	if w.Code != http.StatusCreated {
+		t.Fatalf(
+			"Failed to create database with excludes. Status: %d, Body: %s",
+			w.Code,
+			w.Body.String(),
+		)
+	}
+
+	var createdDatabase databases.Database
+	if err := json.Unmarshal(w.Body.Bytes(), &createdDatabase); err != nil {
+		t.Fatalf("Failed to unmarshal database response: %v", err)
+	}
+
+	return &createdDatabase
+}
+
 func createSupabaseDatabaseViaAPI(
 	t *testing.T,
 	router *gin.Engine,
@@ -0,0 +1,80 @@
+package checksum
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+)
+
+var ErrChecksumMismatch = errors.New("checksum mismatch, stored file is corrupted")
+
+// Writer computes SHA-256 of bytes passed to the underlying writer
+type Writer struct {
+	writer io.WriteCloser
+	hash   hash.Hash
+}
+
+func NewWriter(writer io.WriteCloser) *Writer {
+	return &Writer{
+		writer: writer,
+		hash:   sha256.New(),
+	}
+}
+
+func (w *Writer) Write(p []byte) (int, error) {
+	n, err := w.writer.Write(p)
+	w.hash.Write(p[:n])
+	return n, err
+}
+
+func (w *Writer) Close() error {
+	return w.writer.Close()
+}
+
+// GetChecksum returns hex encoded SHA-256 of all written bytes
+func (w *Writer) GetChecksum() string {
+	return hex.EncodeToString(w.hash.Sum(nil))
+}
+
+// VerifyingReader computes SHA-256 of read bytes and returns
+// ErrChecksumMismatch instead of io.EOF if it differs from expected one
+type VerifyingReader struct {
+	reader           io.ReadCloser
+	hash             hash.Hash
+	expectedChecksum string
+}
+
+func NewVerifyingReader(reader io.ReadCloser, expectedChecksum string) *VerifyingReader {
+	return &VerifyingReader{
+		reader:           reader,
+		hash:             sha256.New(),
+		expectedChecksum: expectedChecksum,
+	}
+}
+
+func (r *VerifyingReader) Read(p []byte) (int, error) {
+	n, err := r.reader.Read(p)
+	r.hash.Write(p[:n])
+
+	if err == io.EOF {
+		actualChecksum := hex.EncodeToString(r.hash.Sum(nil))
+
+		if actualChecksum != r.expectedChecksum {
+			return n, fmt.Errorf(
+				"%w: expected %s, got %s",
+				ErrChecksumMismatch,
+				r.expectedChecksum,
+				actualChecksum,
+			)
+		}
+	}
+
+	return n, err
+}
+
+func (r *VerifyingReader) Close() error {
+	return r.reader.Close()
+}
@@ -0,0 +1,54 @@
+package checksum
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type nopWriteCloser struct {
+	io.Writer
+}
+
+func (nopWriteCloser) Close() error {
+	return nil
+}
+
+func Test_VerifyingReader_WhenDataIsNotChanged_ReadsWithoutError(t *testing.T) {
+	data := []byte("backup data to be stored and read back")
+
+	var stored bytes.Buffer
+	writer := NewWriter(nopWriteCloser{&stored})
+
+	_, err := writer.Write(data)
+	require.NoError(t, err)
+	require.NoError(t, writer.Close())
+
+	reader := NewVerifyingReader(io.NopCloser(&stored), writer.GetChecksum())
+
+	readData, err := io.ReadAll(reader)
+	require.NoError(t, err)
+	assert.Equal(t, data, readData)
+}
+
+func Test_VerifyingReader_WhenDataIsCorrupted_ReturnsChecksumMismatch(t *testing.T) {
+	data := []byte("backup data to be stored and read back")
+
+	var stored bytes.Buffer
+	writer := NewWriter(nopWriteCloser{&stored})
+
+	_, err := writer.Write(data)
+	require.NoError(t, err)
+
+	corrupted := stored.Bytes()
+	corrupted[0] ^= 0xFF
+
+	reader := NewVerifyingReader(io.NopCloser(bytes.NewReader(corrupted)), writer.GetChecksum())
+
+	_, err = io.ReadAll(reader)
+	assert.True(t, errors.Is(err, ErrChecksumMismatch))
+}
@@ -0,0 +1,115 @@
+package files_utils
+
+import (
+	"archive/tar"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// WriteDirectoryToTar writes regular files of the directory to tar
+// with paths relative to the directory
+func WriteDirectoryToTar(writer io.Writer, directory string) error {
+	tarWriter := tar.NewWriter(writer)
+
+	err := filepath.Walk(directory, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if path == directory || !(info.IsDir() || info.Mode().IsRegular()) {
+			return nil
+		}
+
+		relativePath, err := filepath.Rel(directory, path)
+		if err != nil {
+			return err
+		}
+
+		header, err := tar.FileInfoHeader(info, "")
+		if err != nil {
+			return fmt.Errorf("failed to create tar header for %s: %w", relativePath, err)
+		}
+		header.Name = filepath.ToSlash(relativePath)
+
+		if err := tarWriter.WriteHeader(header); err != nil {
+			return fmt.Errorf("failed to write tar header for %s: %w", relativePath, err)
+		}
+
+		if info.IsDir() {
+			return nil
+		}
+
+		file, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer func() { _ = file.Close() }()
+
+		if _, err := io.Copy(tarWriter, file); err != nil {
+			return fmt.Errorf("failed to write %s to tar: %w", relativePath, err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	return tarWriter.Close()
+}
+
+// ExtractTarToDirectory extracts directories and regular files of tar into
+// the directory. Entries pointing outside of the directory are rejected
+func ExtractTarToDirectory(reader io.Reader, directory string) error {
+	tarReader := tar.NewReader(reader)
+
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read tar: %w", err)
+		}
+
+		targetPath := filepath.Join(directory, filepath.FromSlash(header.Name))
+		if targetPath != directory &&
+			!strings.HasPrefix(targetPath, directory+string(os.PathSeparator)) {
+			return fmt.Errorf("tar contains illegal path: %s", header.Name)
+		}
+
+		switch header.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(targetPath, 0700); err != nil {
+				return fmt.Errorf("failed to create directory %s: %w", header.Name, err)
+			}
+		case tar.TypeReg:
+			if err := extractTarFile(tarReader, targetPath); err != nil {
+				return fmt.Errorf("failed to extract file %s: %w", header.Name, err)
+			}
+		default:
+			return fmt.Errorf("tar contains unsupported entry: %s", header.Name)
+		}
+	}
+}
+
+func extractTarFile(tarReader *tar.Reader, targetPath string) error {
+	if err := os.MkdirAll(filepath.Dir(targetPath), 0700); err != nil {
+		return err
+	}
+
+	file, err := os.OpenFile(targetPath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.Copy(file, tarReader); err != nil {
+		_ = file.Close()
+		return err
+	}
+
+	return file.Close()
+}
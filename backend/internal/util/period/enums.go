@@ -18,6 +18,27 @@ const (
 	PeriodForever Period = "FOREVER"
 )
 
+// IsValid tells the period is one of the known ones,
+// ToDuration panics on unknown periods
+func (p Period) IsValid() bool {
+	switch p {
+	case PeriodDay,
+		PeriodWeek,
+		PeriodMonth,
+		Period3Month,
+		Period6Month,
+		PeriodYear,
+		Period2Years,
+		Period3Years,
+		Period4Years,
+		Period5Years,
+		PeriodForever:
+		return true
+	default:
+		return false
+	}
+}
+
 // ToDuration converts Period to time.Duration
 func (p Period) ToDuration() time.Duration {
 	switch p {
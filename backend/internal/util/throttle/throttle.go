@@ -0,0 +1,109 @@
+package throttle
+
+import (
+	"context"
+	"io"
+	"sync"
+
+	"golang.org/x/time/rate"
+)
+
+// Reader limits rate of bytes read from the underlying reader. All of the
+// limiters are applied, so the slowest of them defines the rate
+type Reader struct {
+	ctx      context.Context
+	reader   io.Reader
+	limiters []*rate.Limiter
+	maxChunk int
+}
+
+// NewReader wraps the reader with limiters. Nil limiters mean no limit
+func NewReader(ctx context.Context, reader io.Reader, limiters ...*rate.Limiter) io.Reader {
+	activeLimiters := make([]*rate.Limiter, 0, len(limiters))
+	maxChunk := 0
+
+	for _, limiter := range limiters {
+		if limiter == nil {
+			continue
+		}
+
+		activeLimiters = append(activeLimiters, limiter)
+
+		if maxChunk == 0 || limiter.Burst() < maxChunk {
+			maxChunk = limiter.Burst()
+		}
+	}
+
+	if len(activeLimiters) == 0 {
+		return reader
+	}
+
+	return &Reader{
+		ctx:      ctx,
+		reader:   reader,
+		limiters: activeLimiters,
+		maxChunk: maxChunk,
+	}
+}
+
+func (r *Reader) Read(p []byte) (int, error) {
+	// WaitN fails for more bytes than burst of the limiter
+	if len(p) > r.maxChunk {
+		p = p[:r.maxChunk]
+	}
+
+	n, err := r.reader.Read(p)
+	if n <= 0 {
+		return n, err
+	}
+
+	for _, limiter := range r.limiters {
+		if waitErr := limiter.WaitN(r.ctx, n); waitErr != nil {
+			return n, waitErr
+		}
+	}
+
+	return n, err
+}
+
+// Registry keeps one limiter per key, so concurrent transfers
+// with the same key share the bandwidth
+type Registry struct {
+	mu       sync.Mutex
+	limiters map[string]*rate.Limiter
+}
+
+func NewRegistry() *Registry {
+	return &Registry{
+		limiters: make(map[string]*rate.Limiter),
+	}
+}
+
+// GetLimiter returns limiter of the key, adjusted to the current rate.
+// Nil is returned when the rate is not positive, which means no limit
+func (r *Registry) GetLimiter(key string, bytesPerSecond int64) *rate.Limiter {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if bytesPerSecond <= 0 {
+		delete(r.limiters, key)
+		return nil
+	}
+
+	// one second of traffic can be sent at once
+	burst := int(bytesPerSecond)
+
+	limiter, ok := r.limiters[key]
+	if !ok {
+		limiter = rate.NewLimiter(rate.Limit(bytesPerSecond), burst)
+		r.limiters[key] = limiter
+		return limiter
+	}
+
+	if limiter.Limit() != rate.Limit(bytesPerSecond) {
+		limiter.SetLimit(rate.Limit(bytesPerSecond))
+		limiter.SetBurst(burst)
+	}
+
+	return limiter
+}
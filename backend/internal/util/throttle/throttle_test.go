@@ -0,0 +1,61 @@
+package throttle
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/time/rate"
+)
+
+func Test_Reader_WhenLimited_DataIsReadNotFasterThanLimit(t *testing.T) {
+	data := bytes.Repeat([]byte("a"), 3000)
+
+	// burst is spent first, the rest is read with 10000 bytes per second
+	limiter := rate.NewLimiter(rate.Limit(10000), 1000)
+	reader := NewReader(context.Background(), bytes.NewReader(data), limiter)
+
+	start := time.Now()
+	readData, err := io.ReadAll(reader)
+	elapsed := time.Since(start)
+
+	require.NoError(t, err)
+	assert.Equal(t, data, readData)
+	assert.GreaterOrEqual(t, elapsed, 150*time.Millisecond)
+}
+
+func Test_Reader_WhenNoLimiters_ReaderIsNotWrapped(t *testing.T) {
+	source := bytes.NewReader([]byte("data"))
+
+	assert.Same(t, source, NewReader(context.Background(), source, nil))
+}
+
+func Test_Reader_WhenContextCancelled_ReadFails(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	limiter := rate.NewLimiter(rate.Limit(1), 1)
+	limiter.AllowN(time.Now(), 1)
+
+	reader := NewReader(ctx, bytes.NewReader([]byte("data")), limiter)
+
+	_, err := io.ReadAll(reader)
+	assert.Error(t, err)
+}
+
+func Test_Registry_SameKey_LimiterSharedAndUpdated(t *testing.T) {
+	registry := NewRegistry()
+
+	first := registry.GetLimiter("storage", 1000)
+	second := registry.GetLimiter("storage", 2000)
+
+	assert.Same(t, first, second)
+	assert.Equal(t, rate.Limit(2000), second.Limit())
+	assert.Equal(t, 2000, second.Burst())
+
+	assert.Nil(t, registry.GetLimiter("storage", 0))
+}
@@ -152,6 +152,34 @@ func VerifyPostgresesInstallation(
 	logger.Info("All PostgreSQL version-specific client tools verification completed successfully!")
 }
 
+// CreateTempPgpassFile writes .pgpass with the single entry into a new
+// temporary directory and returns its path. Without password there is
+// nothing to write, so empty path is returned
+func CreateTempPgpassFile(host string, port int, username string, password string) (string, error) {
+	if password == "" {
+		return "", nil
+	}
+
+	pgpassContent := fmt.Sprintf("%s:%d:*:%s:%s",
+		EscapePgpassField(host),
+		port,
+		EscapePgpassField(username),
+		EscapePgpassField(password),
+	)
+
+	tempDir, err := os.MkdirTemp("", "pgpass")
+	if err != nil {
+		return "", fmt.Errorf("failed to create temporary directory: %w", err)
+	}
+
+	pgpassFile := filepath.Join(tempDir, ".pgpass")
+	if err := os.WriteFile(pgpassFile, []byte(pgpassContent), 0600); err != nil {
+		return "", fmt.Errorf("failed to write temporary .pgpass file: %w", err)
+	}
+
+	return pgpassFile, nil
+}
+
 // EscapePgpassField escapes special characters in a field value for .pgpass file format.
 // According to PostgreSQL documentation, the .pgpass file format requires:
 // - Backslash (\) must be escaped as \\
@@ -0,0 +1,54 @@
+-- +goose Up
+-- +goose StatementBegin
+
+ALTER TABLE backup_configs
+    ADD COLUMN is_wal_archiving_enabled BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE TABLE wal_segments (
+    id              UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    database_id     UUID NOT NULL,
+    storage_id      UUID NOT NULL,
+    file_name       TEXT NOT NULL,
+    size_bytes      BIGINT NOT NULL DEFAULT 0,
+    encryption_salt TEXT,
+    encryption_iv   TEXT,
+    encryption      TEXT NOT NULL DEFAULT 'NONE',
+    created_at      TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+ALTER TABLE wal_segments
+    ADD CONSTRAINT fk_wal_segments_database_id
+    FOREIGN KEY (database_id)
+    REFERENCES databases (id)
+    ON DELETE CASCADE;
+
+ALTER TABLE wal_segments
+    ADD CONSTRAINT fk_wal_segments_storage_id
+    FOREIGN KEY (storage_id)
+    REFERENCES storages (id)
+    ON DELETE RESTRICT;
+
+CREATE UNIQUE INDEX idx_wal_segments_database_id_file_name ON wal_segments (database_id, file_name);
+CREATE INDEX idx_wal_segments_database_id_created_at ON wal_segments (database_id, created_at);
+
+ALTER TABLE restores
+    ADD COLUMN recovery_target_time TIMESTAMPTZ,
+    ADD COLUMN recovery_target_lsn  TEXT,
+    ADD COLUMN data_directory       TEXT;
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+ALTER TABLE restores
+    DROP COLUMN IF EXISTS data_directory,
+    DROP COLUMN IF EXISTS recovery_target_lsn,
+    DROP COLUMN IF EXISTS recovery_target_time;
+
+DROP TABLE IF EXISTS wal_segments;
+
+ALTER TABLE backup_configs
+    DROP COLUMN IF EXISTS is_wal_archiving_enabled;
+
+-- +goose StatementEnd
@@ -0,0 +1,21 @@
+-- +goose Up
+-- +goose StatementBegin
+
+ALTER TABLE backup_configs
+    ADD COLUMN backup_method TEXT NOT NULL DEFAULT 'PG_DUMP';
+
+ALTER TABLE backups
+    ADD COLUMN method TEXT NOT NULL DEFAULT 'PG_DUMP';
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+ALTER TABLE backups
+    DROP COLUMN IF EXISTS method;
+
+ALTER TABLE backup_configs
+    DROP COLUMN IF EXISTS backup_method;
+
+-- +goose StatementEnd
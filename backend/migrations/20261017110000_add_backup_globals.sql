@@ -0,0 +1,25 @@
+-- +goose Up
+-- +goose StatementBegin
+
+ALTER TABLE backup_configs
+    ADD COLUMN is_backup_globals BOOLEAN NOT NULL DEFAULT FALSE;
+
+ALTER TABLE backups
+    ADD COLUMN globals_file_id         UUID,
+    ADD COLUMN globals_encryption_salt TEXT,
+    ADD COLUMN globals_encryption_iv   TEXT;
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+ALTER TABLE backups
+    DROP COLUMN IF EXISTS globals_encryption_iv,
+    DROP COLUMN IF EXISTS globals_encryption_salt,
+    DROP COLUMN IF EXISTS globals_file_id;
+
+ALTER TABLE backup_configs
+    DROP COLUMN IF EXISTS is_backup_globals;
+
+-- +goose StatementEnd
@@ -0,0 +1,51 @@
+-- +goose Up
+-- +goose StatementBegin
+
+ALTER TABLE backup_configs
+    ADD COLUMN is_whole_server_backup BOOLEAN NOT NULL DEFAULT FALSE,
+    ADD COLUMN include_databases      TEXT    NOT NULL DEFAULT '',
+    ADD COLUMN exclude_databases      TEXT    NOT NULL DEFAULT '';
+
+ALTER TABLE backups
+    ADD COLUMN is_whole_server BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE TABLE backup_artifacts (
+    id              UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    backup_id       UUID NOT NULL,
+    database_name   TEXT NOT NULL,
+    size_mb         DOUBLE PRECISION NOT NULL DEFAULT 0,
+    encryption_salt TEXT,
+    encryption_iv   TEXT,
+    created_at      TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+ALTER TABLE backup_artifacts
+    ADD CONSTRAINT fk_backup_artifacts_backup_id
+    FOREIGN KEY (backup_id)
+    REFERENCES backups (id)
+    ON DELETE CASCADE;
+
+CREATE INDEX idx_backup_artifacts_backup_id ON backup_artifacts (backup_id);
+
+ALTER TABLE restores
+    ADD COLUMN artifact_id UUID;
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+ALTER TABLE restores
+    DROP COLUMN IF EXISTS artifact_id;
+
+DROP TABLE IF EXISTS backup_artifacts;
+
+ALTER TABLE backups
+    DROP COLUMN IF EXISTS is_whole_server;
+
+ALTER TABLE backup_configs
+    DROP COLUMN IF EXISTS exclude_databases,
+    DROP COLUMN IF EXISTS include_databases,
+    DROP COLUMN IF EXISTS is_whole_server_backup;
+
+-- +goose StatementEnd
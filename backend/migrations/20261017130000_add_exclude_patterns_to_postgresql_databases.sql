@@ -0,0 +1,15 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE postgresql_databases
+    ADD COLUMN exclude_schemas    TEXT NOT NULL DEFAULT '',
+    ADD COLUMN exclude_tables     TEXT NOT NULL DEFAULT '',
+    ADD COLUMN exclude_table_data TEXT NOT NULL DEFAULT '';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE postgresql_databases
+    DROP COLUMN exclude_table_data,
+    DROP COLUMN exclude_tables,
+    DROP COLUMN exclude_schemas;
+-- +goose StatementEnd
@@ -0,0 +1,49 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE backup_configs
+    ADD COLUMN compression       TEXT NOT NULL DEFAULT 'ZSTD',
+    ADD COLUMN compression_level INT  NOT NULL DEFAULT 5;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+ALTER TABLE backups
+    ADD COLUMN compression TEXT NOT NULL DEFAULT 'ZSTD';
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+UPDATE backup_configs
+SET compression = 'GZIP'
+WHERE database_id IN (
+    SELECT database_id
+    FROM postgresql_databases
+    WHERE version IN ('12', '13', '14', '15')
+);
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+UPDATE backups
+SET compression = 'GZIP'
+WHERE database_id IN (
+    SELECT database_id
+    FROM postgresql_databases
+    WHERE version IN ('12', '13', '14', '15')
+);
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+UPDATE backups
+SET compression = 'NONE'
+WHERE method = 'PG_BASEBACKUP';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE backups
+    DROP COLUMN compression;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+ALTER TABLE backup_configs
+    DROP COLUMN compression_level,
+    DROP COLUMN compression;
+-- +goose StatementEnd
@@ -0,0 +1,21 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE backup_configs
+    ADD COLUMN format TEXT NOT NULL DEFAULT 'CUSTOM';
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+ALTER TABLE backups
+    ADD COLUMN format TEXT NOT NULL DEFAULT 'CUSTOM';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE backups
+    DROP COLUMN format;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+ALTER TABLE backup_configs
+    DROP COLUMN format;
+-- +goose StatementEnd
@@ -0,0 +1,23 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE backups
+    ADD COLUMN checksum         TEXT,
+    ADD COLUMN globals_checksum TEXT;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+ALTER TABLE backup_artifacts
+    ADD COLUMN checksum TEXT;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE backup_artifacts
+    DROP COLUMN checksum;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+ALTER TABLE backups
+    DROP COLUMN globals_checksum,
+    DROP COLUMN checksum;
+-- +goose StatementEnd
@@ -0,0 +1,66 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE restore_verification_configs (
+    database_id    UUID PRIMARY KEY,
+    is_enabled     BOOLEAN NOT NULL DEFAULT FALSE,
+    interval_id    UUID NOT NULL,
+    host           TEXT NOT NULL,
+    port           INT NOT NULL,
+    username       TEXT NOT NULL,
+    password       TEXT NOT NULL,
+    is_https       BOOLEAN NOT NULL DEFAULT FALSE,
+    checked_tables TEXT NOT NULL DEFAULT ''
+);
+
+ALTER TABLE restore_verification_configs
+    ADD CONSTRAINT fk_restore_verification_configs_database_id
+    FOREIGN KEY (database_id)
+    REFERENCES databases (id)
+    ON DELETE CASCADE DEFERRABLE INITIALLY DEFERRED;
+
+ALTER TABLE restore_verification_configs
+    ADD CONSTRAINT fk_restore_verification_configs_interval_id
+    FOREIGN KEY (interval_id)
+    REFERENCES intervals (id);
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TABLE restore_verification_results (
+    id               UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    database_id      UUID NOT NULL,
+    backup_id        UUID NOT NULL,
+    status           TEXT NOT NULL,
+    fail_message     TEXT,
+    tables_count     INT NOT NULL DEFAULT 0,
+    table_row_counts TEXT NOT NULL DEFAULT '{}',
+    duration_ms      BIGINT NOT NULL DEFAULT 0,
+    created_at       TIMESTAMPTZ NOT NULL
+);
+
+ALTER TABLE restore_verification_results
+    ADD CONSTRAINT fk_restore_verification_results_database_id
+    FOREIGN KEY (database_id)
+    REFERENCES databases (id)
+    ON DELETE CASCADE DEFERRABLE INITIALLY DEFERRED;
+
+ALTER TABLE restore_verification_results
+    ADD CONSTRAINT fk_restore_verification_results_backup_id
+    FOREIGN KEY (backup_id)
+    REFERENCES backups (id)
+    ON DELETE CASCADE;
+
+CREATE INDEX idx_restore_verification_results_database_id_created_at
+    ON restore_verification_results (database_id, created_at DESC);
+
+CREATE INDEX idx_restore_verification_results_status
+    ON restore_verification_results (status);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_restore_verification_results_status;
+DROP INDEX IF EXISTS idx_restore_verification_results_database_id_created_at;
+
+DROP TABLE IF EXISTS restore_verification_results;
+DROP TABLE IF EXISTS restore_verification_configs;
+-- +goose StatementEnd
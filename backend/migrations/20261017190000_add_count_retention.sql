@@ -0,0 +1,13 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE backup_configs
+    ADD COLUMN keep_last_count INT NOT NULL DEFAULT 0,
+    ADD COLUMN min_keep_count  INT NOT NULL DEFAULT 1;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE backup_configs
+    DROP COLUMN min_keep_count,
+    DROP COLUMN keep_last_count;
+-- +goose StatementEnd
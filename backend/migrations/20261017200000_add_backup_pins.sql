@@ -0,0 +1,30 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE backups
+    ADD COLUMN is_pinned         BOOLEAN NOT NULL DEFAULT FALSE,
+    ADD COLUMN pin_reason        TEXT,
+    ADD COLUMN pinned_by_user_id UUID,
+    ADD COLUMN pinned_at         TIMESTAMPTZ;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+ALTER TABLE backups
+    ADD CONSTRAINT fk_backups_pinned_by_user_id
+    FOREIGN KEY (pinned_by_user_id)
+    REFERENCES users (id)
+    ON DELETE SET NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE backups
+    DROP CONSTRAINT IF EXISTS fk_backups_pinned_by_user_id;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+ALTER TABLE backups
+    DROP COLUMN pinned_at,
+    DROP COLUMN pinned_by_user_id,
+    DROP COLUMN pin_reason,
+    DROP COLUMN is_pinned;
+-- +goose StatementEnd
@@ -0,0 +1,11 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE intervals
+    ADD COLUMN cron_expression TEXT;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE intervals
+    DROP COLUMN cron_expression;
+-- +goose StatementEnd
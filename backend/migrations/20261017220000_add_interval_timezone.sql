@@ -0,0 +1,11 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE intervals
+    ADD COLUMN timezone TEXT;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE intervals
+    DROP COLUMN timezone;
+-- +goose StatementEnd
@@ -0,0 +1,40 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE backup_blackout_windows (
+    id           UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    database_id  UUID,
+    workspace_id UUID,
+    start_time   TEXT NOT NULL,
+    end_time     TEXT NOT NULL,
+    weekdays     TEXT NOT NULL DEFAULT '',
+    timezone     TEXT NOT NULL DEFAULT '',
+    CONSTRAINT chk_backup_blackout_windows_owner
+        CHECK ((database_id IS NULL) <> (workspace_id IS NULL))
+);
+
+ALTER TABLE backup_blackout_windows
+    ADD CONSTRAINT fk_backup_blackout_windows_database_id
+    FOREIGN KEY (database_id)
+    REFERENCES databases (id)
+    ON DELETE CASCADE DEFERRABLE INITIALLY DEFERRED;
+
+ALTER TABLE backup_blackout_windows
+    ADD CONSTRAINT fk_backup_blackout_windows_workspace_id
+    FOREIGN KEY (workspace_id)
+    REFERENCES workspaces (id)
+    ON DELETE CASCADE;
+
+CREATE INDEX idx_backup_blackout_windows_database_id
+    ON backup_blackout_windows (database_id);
+
+CREATE INDEX idx_backup_blackout_windows_workspace_id
+    ON backup_blackout_windows (workspace_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_backup_blackout_windows_workspace_id;
+DROP INDEX IF EXISTS idx_backup_blackout_windows_database_id;
+
+DROP TABLE IF EXISTS backup_blackout_windows;
+-- +goose StatementEnd
@@ -0,0 +1,21 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE backup_configs
+    ADD COLUMN queue_priority INT NOT NULL DEFAULT 0;
+
+ALTER TABLE backups
+    ADD COLUMN queue_priority INT NOT NULL DEFAULT 0;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+UPDATE backups
+    SET status = 'CANCELED'
+    WHERE status = 'QUEUED';
+
+ALTER TABLE backups
+    DROP COLUMN queue_priority;
+
+ALTER TABLE backup_configs
+    DROP COLUMN queue_priority;
+-- +goose StatementEnd
@@ -0,0 +1,17 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE backup_configs
+    ADD COLUMN hooks TEXT NOT NULL DEFAULT '[]';
+
+ALTER TABLE backups
+    ADD COLUMN hooks_output TEXT;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE backups
+    DROP COLUMN hooks_output;
+
+ALTER TABLE backup_configs
+    DROP COLUMN hooks;
+-- +goose StatementEnd
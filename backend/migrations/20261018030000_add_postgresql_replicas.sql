@@ -0,0 +1,21 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE postgresql_databases
+    ADD COLUMN replicas                TEXT NOT NULL DEFAULT '[]',
+    ADD COLUMN max_replica_lag_seconds INT  NOT NULL DEFAULT 0,
+    ADD COLUMN replica_fallback_policy TEXT NOT NULL DEFAULT 'FALLBACK_TO_PRIMARY';
+
+ALTER TABLE backups
+    ADD COLUMN source_host TEXT;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE backups
+    DROP COLUMN source_host;
+
+ALTER TABLE postgresql_databases
+    DROP COLUMN replicas,
+    DROP COLUMN max_replica_lag_seconds,
+    DROP COLUMN replica_fallback_policy;
+-- +goose StatementEnd
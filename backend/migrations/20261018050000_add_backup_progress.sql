@@ -0,0 +1,13 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE backups
+    ADD COLUMN database_size_mb  DOUBLE PRECISION NOT NULL DEFAULT 0,
+    ADD COLUMN estimated_size_mb DOUBLE PRECISION NOT NULL DEFAULT 0;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE backups
+    DROP COLUMN database_size_mb,
+    DROP COLUMN estimated_size_mb;
+-- +goose StatementEnd
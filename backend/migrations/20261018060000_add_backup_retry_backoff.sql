@@ -0,0 +1,27 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE backup_configs
+    ADD COLUMN retry_delay_seconds     INT NOT NULL DEFAULT 0,
+    ADD COLUMN retry_max_delay_seconds INT NOT NULL DEFAULT 0,
+    ADD COLUMN retry_jitter_percent    INT NOT NULL DEFAULT 0;
+
+-- existing databases get the same defaults as the new ones
+UPDATE backup_configs
+SET retry_delay_seconds     = 60,
+    retry_max_delay_seconds = 3600,
+    retry_jitter_percent    = 20;
+
+ALTER TABLE backups
+    ADD COLUMN next_retry_at TIMESTAMPTZ;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE backups
+    DROP COLUMN next_retry_at;
+
+ALTER TABLE backup_configs
+    DROP COLUMN retry_delay_seconds,
+    DROP COLUMN retry_max_delay_seconds,
+    DROP COLUMN retry_jitter_percent;
+-- +goose StatementEnd
@@ -0,0 +1,48 @@
+-- +goose Up
+-- +goose StatementBegin
+
+ALTER TABLE backup_configs
+    ADD COLUMN copy_targets TEXT NOT NULL DEFAULT '[]';
+
+ALTER TABLE backups
+    ADD COLUMN is_removed_from_storage BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE TABLE backup_copies (
+    id           UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    backup_id    UUID NOT NULL,
+    storage_id   UUID NOT NULL,
+    status       TEXT NOT NULL,
+    fail_message TEXT,
+    created_at   TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    completed_at TIMESTAMPTZ
+);
+
+ALTER TABLE backup_copies
+    ADD CONSTRAINT fk_backup_copies_backup_id
+    FOREIGN KEY (backup_id)
+    REFERENCES backups (id)
+    ON DELETE CASCADE;
+
+ALTER TABLE backup_copies
+    ADD CONSTRAINT fk_backup_copies_storage_id
+    FOREIGN KEY (storage_id)
+    REFERENCES storages (id)
+    ON DELETE CASCADE;
+
+CREATE UNIQUE INDEX idx_backup_copies_backup_id_storage_id ON backup_copies (backup_id, storage_id);
+CREATE INDEX idx_backup_copies_status ON backup_copies (status);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+DROP TABLE IF EXISTS backup_copies;
+
+ALTER TABLE backups
+    DROP COLUMN IF EXISTS is_removed_from_storage;
+
+ALTER TABLE backup_configs
+    DROP COLUMN IF EXISTS copy_targets;
+
+-- +goose StatementEnd
@@ -0,0 +1,13 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE backup_copies
+    ADD COLUMN failed_tries_count INT NOT NULL DEFAULT 0,
+    ADD COLUMN next_retry_at      TIMESTAMPTZ;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE backup_copies
+    DROP COLUMN failed_tries_count,
+    DROP COLUMN next_retry_at;
+-- +goose StatementEnd
@@ -0,0 +1,23 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE backup_copies
+    DROP CONSTRAINT fk_backup_copies_storage_id;
+
+ALTER TABLE backup_copies
+    ADD CONSTRAINT fk_backup_copies_storage_id
+    FOREIGN KEY (storage_id)
+    REFERENCES storages (id)
+    ON DELETE RESTRICT;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE backup_copies
+    DROP CONSTRAINT fk_backup_copies_storage_id;
+
+ALTER TABLE backup_copies
+    ADD CONSTRAINT fk_backup_copies_storage_id
+    FOREIGN KEY (storage_id)
+    REFERENCES storages (id)
+    ON DELETE CASCADE;
+-- +goose StatementEnd
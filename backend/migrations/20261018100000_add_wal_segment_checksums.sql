@@ -0,0 +1,11 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE wal_segments
+    ADD COLUMN checksum TEXT;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE wal_segments
+    DROP COLUMN checksum;
+-- +goose StatementEnd
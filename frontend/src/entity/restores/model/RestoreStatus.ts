@@ -2,4 +2,5 @@ export enum RestoreStatus {
   IN_PROGRESS = 'IN_PROGRESS',
   COMPLETED = 'COMPLETED',
   FAILED = 'FAILED',
+  DATA_DIRECTORY_PREPARED = 'DATA_DIRECTORY_PREPARED',
 }
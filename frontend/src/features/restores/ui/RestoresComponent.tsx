@@ -283,6 +283,17 @@ export const RestoresComponent = ({ database, backup }: Props) => {
                       </div>
                     )}
 
+                    {restore.status === RestoreStatus.DATA_DIRECTORY_PREPARED && (
+                      <div className="flex items-center">
+                        <CheckCircleOutlined
+                          className="mr-2"
+                          style={{ fontSize: 16, color: '#008000' }}
+                        />
+
+                        <div>Data directory prepared</div>
+                      </div>
+                    )}
+
                     {restore.status === RestoreStatus.IN_PROGRESS && (
                       <div className="flex items-center font-bold text-blue-600">
                         <SyncOutlined spin />